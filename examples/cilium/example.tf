@@ -0,0 +1,31 @@
+###############################################################################
+# RKE doesn't ship cilium, so the cluster is deployed with "none" network
+# plugin and cilium manifests, rendered by helm, are deployed as user addons.
+###############################################################################
+data "helm_template" "cilium" {
+  name       = "cilium"
+  namespace  = "kube-system"
+  repository = "https://helm.cilium.io"
+  chart      = "cilium"
+  version    = "1.16.3"
+
+  set {
+    name  = "ipam.mode"
+    value = "kubernetes"
+  }
+}
+
+resource "rke_cluster" "cluster" {
+  nodes {
+    address = "1.2.3.4"
+    user    = "ubuntu"
+    role    = ["controlplane", "worker", "etcd"]
+    ssh_key = file("~/.ssh/id_rsa")
+  }
+
+  network {
+    plugin = "none"
+  }
+
+  addons = data.helm_template.cilium.manifest
+}
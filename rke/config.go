@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	rancher "github.com/rancher/rke/types"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -25,6 +26,20 @@ type Config struct {
 	LogBuffer *bytes.Buffer
 	LogFile   string
 	File      *os.File
+	SSH       *SSHConfig
+}
+
+// SSHConfig type of provider level SSH defaults
+type SSHConfig struct {
+	User           string
+	Port           string
+	SSHAgentAuth   bool
+	SSHCert        string
+	SSHCertPath    string
+	SSHKey         string
+	SSHKeyPath     string
+	KnownHostsPath string
+	BastionHost    *rancher.BastionHost
 }
 
 func (c *Config) initLogger() {
@@ -62,3 +77,45 @@ func (c *Config) saveRKEOutput(err error) diag.Diagnostics {
 
 	return nil
 }
+
+// applyDefaults sets provider SSH defaults on nodes and bastion host not defining their own.
+// Provider ssh_agent_auth is only applied if sshAgentAuthSet is false
+func (s *SSHConfig) applyDefaults(in *rancher.RancherKubernetesEngineConfig, sshAgentAuthSet bool) {
+	if s == nil || in == nil {
+		return
+	}
+
+	if s.SSHAgentAuth && !sshAgentAuthSet {
+		in.SSHAgentAuth = true
+	}
+
+	// Cluster level key and cert paths take precedence over provider defaults
+	defaultSSHKey := len(in.SSHKeyPath) == 0
+	if defaultSSHKey {
+		in.SSHKeyPath = s.SSHKeyPath
+	}
+
+	defaultSSHCert := len(in.SSHCertPath) == 0
+	if defaultSSHCert {
+		in.SSHCertPath = s.SSHCertPath
+	}
+
+	if len(in.BastionHost.Address) == 0 && s.BastionHost != nil {
+		in.BastionHost = *s.BastionHost
+	}
+
+	for i := range in.Nodes {
+		if len(in.Nodes[i].User) == 0 {
+			in.Nodes[i].User = s.User
+		}
+		if len(in.Nodes[i].Port) == 0 {
+			in.Nodes[i].Port = s.Port
+		}
+		if defaultSSHKey && len(in.Nodes[i].SSHKey) == 0 && len(in.Nodes[i].SSHKeyPath) == 0 {
+			in.Nodes[i].SSHKey = s.SSHKey
+		}
+		if defaultSSHCert && len(in.Nodes[i].SSHCert) == 0 && len(in.Nodes[i].SSHCertPath) == 0 {
+			in.Nodes[i].SSHCert = s.SSHCert
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package rke
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/docker"
+	"github.com/rancher/rke/hosts"
+	"github.com/rancher/rke/metadata"
+	rancher "github.com/rancher/rke/types"
+	"github.com/rancher/rke/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rkeHostPreflightContainerName = "rke-preflight-probe"
+	rkeHostPreflightHostRoot      = "/host"
+	// rkeHostPreflightProbeScript writes node state as key=value lines. $1 is the prefix path
+	rkeHostPreflightProbeScript = `
+[ -d /proc/sys/net/bridge ] && echo br_netfilter=1 || echo br_netfilter=0
+echo bridge_nf_call_iptables=$(cat /proc/sys/net/bridge/bridge-nf-call-iptables 2>/dev/null || echo 0)
+echo ip_forward=$(cat /proc/sys/net/ipv4/ip_forward 2>/dev/null || echo 0)
+echo swaps=$(tail -n +2 /proc/swaps | wc -l)
+p="` + rkeHostPreflightHostRoot + `$1"
+while [ ! -e "$p" ]; do p=$(dirname "$p"); done
+echo disk_free_kb=$(df -Pk "$p" | tail -n 1 | awk '{print $4}')
+for f in /proc/net/tcp /proc/net/tcp6; do
+  [ -f $f ] && awk 'NR>1 && $4=="0A" {split($2,a,":"); print "listen=" a[2]}' $f
+done
+for f in /proc/net/udp /proc/net/udp6; do
+  [ -f $f ] && awk 'NR>1 && $4=="07" {split($2,a,":"); print "listen_udp=" a[2]}' $f
+done
+exit 0
+`
+)
+
+func dataSourceRKEHostPreflight() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRKEHostPreflightRead,
+		Schema:      rkeHostPreflightFields(),
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func dataSourceRKEHostPreflightRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Info("Running RKE host preflight checks...")
+	config := meta.(*Config)
+
+	rkeConfig, err := expandRKEHostPreflight(d, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if dialers.DockerDialerFactory == nil {
+		dialers.DockerDialerFactory = hosts.SSHFactory
+	}
+
+	if err := metadata.InitMetadata(ctx); err != nil {
+		return diag.FromErr(fmt.Errorf("Failed initializing RKE metadata: %v", err))
+	}
+	k8sVersion := rkeConfig.Version
+	if len(k8sVersion) == 0 {
+		k8sVersion = metadata.DefaultK8sVersion
+	}
+	image := d.Get("preflight_image").(string)
+	if len(image) == 0 {
+		image = metadata.K8sVersionToRKESystemImages[k8sVersion].Alpine
+	}
+	if len(image) == 0 {
+		return diag.FromErr(fmt.Errorf("Failed getting preflight image for kubernetes version %s, set preflight_image", k8sVersion))
+	}
+
+	opts := &rkeHostPreflightOptions{
+		DisablePortCheck:  d.Get("disable_port_check").(bool),
+		EnableBrNetfilter: d.Get("enable_br_netfilter").(bool),
+		FailSwapOn:        d.Get("fail_swap_on").(bool),
+		Image:             image,
+		K8sVersion:        k8sVersion,
+		MaxClockSkew:      float64(d.Get("max_clock_skew").(int)),
+		MinDiskFreeMB:     d.Get("min_disk_free_mb").(int),
+	}
+
+	passed := true
+	addresses := ""
+	results := make([]*rkeHostPreflightResult, len(rkeConfig.Nodes))
+	for i := range rkeConfig.Nodes {
+		h := &hosts.Host{
+			RKEConfigNode:       rkeConfig.Nodes[i],
+			BastionHost:         rkeConfig.BastionHost,
+			IgnoreDockerVersion: rkeConfig.IgnoreDockerVersion != nil && *rkeConfig.IgnoreDockerVersion,
+		}
+		results[i] = runRKEHostPreflight(ctx, h, dialers.DockerDialerFactory, rkeConfig.PrefixPath, opts)
+		if len(results[i].Errors) > 0 {
+			passed = false
+			log.Infof("[rke_provider] Host preflight failed for host [%s]: %v", h.Address, results[i].Errors)
+		}
+		addresses += h.Address + ","
+	}
+
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256([]byte(addresses))))
+	if err := d.Set("hosts", flattenRKEHostPreflightResults(results)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("passed", passed); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+// rkeHostPreflightOptions type of preflight checks settings
+type rkeHostPreflightOptions struct {
+	DisablePortCheck  bool
+	EnableBrNetfilter bool
+	FailSwapOn        bool
+	Image             string
+	K8sVersion        string
+	MaxClockSkew      float64
+	MinDiskFreeMB     int
+}
+
+// expandRKEHostPreflight returns the RKE config of the nodes to check, with provider and RKE defaults set
+func expandRKEHostPreflight(d *schema.ResourceData, config *Config) (*rancher.RancherKubernetesEngineConfig, error) {
+	obj := &rancher.RancherKubernetesEngineConfig{}
+
+	if v, ok := d.Get("nodes").([]interface{}); ok && len(v) > 0 {
+		obj.Nodes = expandRKEClusterNodes(v)
+	}
+	if len(obj.Nodes) == 0 {
+		return nil, fmt.Errorf("nodes must be provided")
+	}
+
+	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) == 1 {
+		obj.BastionHost = expandRKEClusterBastionHost(v)
+	}
+
+	if v, ok := d.Get("ignore_docker_version").(bool); ok {
+		obj.IgnoreDockerVersion = &v
+	}
+
+	if v, ok := d.Get("kubernetes_version").(string); ok && len(v) > 0 {
+		obj.Version = v
+	}
+
+	if v, ok := d.Get("prefix_path").(string); ok && len(v) > 0 {
+		obj.PrefixPath = v
+	}
+
+	if v, ok := d.Get("ssh_agent_auth").(bool); ok {
+		obj.SSHAgentAuth = v
+	}
+
+	if v, ok := d.Get("ssh_cert_path").(string); ok && len(v) > 0 {
+		obj.SSHCertPath = v
+	}
+
+	if v, ok := d.Get("ssh_key_path").(string); ok && len(v) > 0 {
+		obj.SSHKeyPath = v
+	}
+
+	if config != nil {
+		config.SSH.applyDefaults(obj, rkeClusterConfigIsSet(d.GetRawConfig(), "ssh_agent_auth"))
+	}
+
+	// Bastion host chains are handled by provider dialers
+	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) > 1 {
+		obj.BastionHost = rancher.BastionHost{}
+	}
+
+	// Setting RKE defaults as cluster.InitClusterObject does
+	if len(obj.SSHKeyPath) == 0 {
+		obj.SSHKeyPath = cluster.DefaultClusterSSHKeyPath
+	}
+	if len(obj.PrefixPath) == 0 {
+		obj.PrefixPath = "/"
+	}
+	if len(obj.BastionHost.Address) > 0 {
+		if len(obj.BastionHost.Port) == 0 {
+			obj.BastionHost.Port = cluster.DefaultSSHPort
+		}
+		if len(obj.BastionHost.SSHKeyPath) == 0 {
+			obj.BastionHost.SSHKeyPath = obj.SSHKeyPath
+		}
+		obj.BastionHost.SSHAgentAuth = obj.SSHAgentAuth
+	}
+	for i := range obj.Nodes {
+		if len(obj.Nodes[i].SSHKeyPath) == 0 {
+			obj.Nodes[i].SSHKeyPath = obj.SSHKeyPath
+		}
+		if len(obj.Nodes[i].SSHCertPath) == 0 {
+			obj.Nodes[i].SSHCertPath = obj.SSHCertPath
+		}
+		if len(obj.Nodes[i].Port) == 0 {
+			obj.Nodes[i].Port = cluster.DefaultSSHPort
+		}
+		obj.Nodes[i].SSHAgentAuth = obj.SSHAgentAuth
+	}
+
+	return obj, nil
+}
+
+func runRKEHostPreflight(ctx context.Context, h *hosts.Host, dialerFactory hosts.DialerFactory, prefixPath string, opts *rkeHostPreflightOptions) *rkeHostPreflightResult {
+	result := &rkeHostPreflightResult{
+		Address: h.Address,
+	}
+
+	// Docker version is checked below, honoring ignore_docker_version
+	ignoreDockerVersion := h.IgnoreDockerVersion
+	h.IgnoreDockerVersion = true
+	if err := h.TunnelUp(ctx, dialerFactory, prefixPath, opts.K8sVersion); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed connecting to docker: %v", err))
+		return result
+	}
+	defer h.DClient.Close()
+	result.PrefixPath = h.PrefixPath
+
+	// Docker version
+	result.DockerVersion = h.DockerInfo.ServerVersion
+	if k8sVersion, err := util.StrToSemVer(opts.K8sVersion); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed parsing kubernetes version %s: %v", opts.K8sVersion, err))
+	} else {
+		supported, err := docker.IsSupportedDockerVersion(h.DockerInfo, fmt.Sprintf("%d.%d", k8sVersion.Major, k8sVersion.Minor))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed checking docker version %s: %v", result.DockerVersion, err))
+		}
+		result.DockerVersionSupported = supported
+		if !supported && !ignoreDockerVersion {
+			result.Errors = append(result.Errors, fmt.Sprintf("Unsupported docker version %s for kubernetes version %s", result.DockerVersion, opts.K8sVersion))
+		}
+	}
+
+	// Clock skew, measured against the middle of the docker info request
+	start := time.Now()
+	info, err := h.DClient.Info(ctx)
+	end := time.Now()
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed getting docker info: %v", err))
+	} else if systemTime, err := time.Parse(time.RFC3339Nano, info.SystemTime); err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed parsing docker system time %s: %v", info.SystemTime, err))
+	} else {
+		localTime := start.Add(end.Sub(start) / 2)
+		result.ClockSkew = math.Abs(systemTime.Sub(localTime).Seconds())
+		if result.ClockSkew > opts.MaxClockSkew {
+			result.Errors = append(result.Errors, fmt.Sprintf("Clock skew %.3fs is greater than %.0fs", result.ClockSkew, opts.MaxClockSkew))
+		}
+	}
+
+	if h.IsWindows() {
+		return result
+	}
+
+	// Node state from probe container
+	out, err := runRKEHostPreflightProbe(ctx, h, opts.Image)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	parseRKEHostPreflightProbe(out, result)
+
+	if !opts.DisablePortCheck {
+		result.OccupiedPorts = rkeHostPreflightOccupiedPorts(rkeHostPreflightRequiredPorts(h.Role), result.ListeningPorts)
+		if len(result.OccupiedPorts) > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("Required ports %v are already in use", result.OccupiedPorts))
+		}
+	}
+
+	// br_netfilter is loaded by kube-proxy if enable_br_netfilter is true
+	if !opts.EnableBrNetfilter && (!result.BrNetfilterLoaded || !result.BridgeNfCallIptables) {
+		result.Errors = append(result.Errors, "br_netfilter module must be loaded and net.bridge.bridge-nf-call-iptables set to 1 if enable_br_netfilter is false")
+	}
+
+	if opts.FailSwapOn && result.SwapEnabled {
+		result.Errors = append(result.Errors, "Swap is enabled")
+	}
+
+	if result.DiskFreeMB < opts.MinDiskFreeMB {
+		result.Errors = append(result.Errors, fmt.Sprintf("Free disk space %dMB under %s is lower than %dMB", result.DiskFreeMB, result.PrefixPath, opts.MinDiskFreeMB))
+	}
+
+	return result
+}
+
+func runRKEHostPreflightProbe(ctx context.Context, h *hosts.Host, image string) (string, error) {
+	imageCfg := &container.Config{
+		Image: image,
+		Cmd:   []string{"sh", "-c", rkeHostPreflightProbeScript, rkeHostPreflightContainerName, h.PrefixPath},
+	}
+	hostCfg := &container.HostConfig{
+		NetworkMode: "host",
+		Binds:       []string{"/:" + rkeHostPreflightHostRoot + ":ro"},
+	}
+
+	if err := docker.DoRemoveContainer(ctx, h.DClient, rkeHostPreflightContainerName, h.Address); err != nil {
+		return "", fmt.Errorf("Failed removing preflight container: %v", err)
+	}
+	defer docker.DoRemoveContainer(ctx, h.DClient, rkeHostPreflightContainerName, h.Address)
+
+	if err := docker.DoRunOnetimeContainer(ctx, h.DClient, imageCfg, hostCfg, rkeHostPreflightContainerName, h.Address, "preflight", nil); err != nil {
+		return "", fmt.Errorf("Failed running preflight container: %v", err)
+	}
+
+	_, stdout, err := docker.GetContainerLogsStdoutStderr(ctx, h.DClient, rkeHostPreflightContainerName, "all", false)
+	if err != nil {
+		return "", fmt.Errorf("Failed reading preflight container output: %v", err)
+	}
+	return stdout, nil
+}
@@ -0,0 +1,679 @@
+package rke
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/rke/hosts"
+	rancher "github.com/rancher/rke/types"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+	"k8s.io/client-go/transport"
+)
+
+const (
+	rkeDialerDockerSocketDefault = "/var/run/docker.sock"
+	rkeDialerSSHKeyPathDefault   = "~/.ssh/id_rsa"
+	rkeDialerSSHPortDefault      = "22"
+	rkeDialerTimeout             = 30 * time.Second
+)
+
+// rkeHostKey type of SSH host key verification settings
+type rkeHostKey struct {
+	Fingerprint string
+	KnownHosts  string
+}
+
+// rkeJumpHost type of SSH jump host settings
+type rkeJumpHost struct {
+	Address      string
+	Port         string
+	User         string
+	SSHAgentAuth bool
+	SSHCert      string
+	SSHCertPath  string
+	SSHKey       string
+	SSHKeyPath   string
+	HostKey      rkeHostKey
+}
+
+// rkeConnection type of node connection settings
+type rkeConnection struct {
+	Type             string
+	DockerHost       string
+	DockerCACert     string
+	DockerClientCert string
+	DockerClientKey  string
+	JumpHosts        []rkeJumpHost
+	ProxyCommand     string
+}
+
+// rkeDialersConfig type of dialers settings for a RKE cluster
+type rkeDialersConfig struct {
+	BastionHosts    []rancher.BastionHost
+	Connection      *rkeConnection
+	NodeConnections map[string]*rkeConnection
+	HostKeys        map[string]rkeHostKey
+	KnownHostsPath  string
+	SSHAgentAuth    bool
+	SSHKeyPath      string
+}
+
+func (c *rkeDialersConfig) isDefault() bool {
+	return c == nil || (len(c.BastionHosts) == 0 && c.Connection == nil && len(c.NodeConnections) == 0 && len(c.HostKeys) == 0 && len(c.KnownHostsPath) == 0)
+}
+
+// tunnelsK8s returns true if k8s api connections need to go through some node connection
+func (c *rkeDialersConfig) tunnelsK8s() bool {
+	if len(c.BastionHosts) > 0 || c.Connection.isTunnel() {
+		return true
+	}
+	for _, v := range c.NodeConnections {
+		if v.isTunnel() {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *rkeDialersConfig) connection(address string) *rkeConnection {
+	if v, ok := c.NodeConnections[address]; ok {
+		return v
+	}
+	if c.Connection != nil {
+		return c.Connection
+	}
+	return &rkeConnection{Type: rkeClusterConnectionTypeSSH}
+}
+
+// bastionHosts returns the bastion host chain for the host, RKE bastion host if no chain is configured
+func (c *rkeDialersConfig) bastionHosts(h *hosts.Host) []rancher.BastionHost {
+	if len(c.BastionHosts) > 0 {
+		return c.BastionHosts
+	}
+	if len(h.BastionHost.Address) > 0 {
+		return []rancher.BastionHost{h.BastionHost}
+	}
+	return nil
+}
+
+func (c *rkeConnection) isTunnel() bool {
+	return c != nil && (len(c.JumpHosts) > 0 || c.Type == rkeClusterConnectionTypeProxyCommand)
+}
+
+// rkeSSHHop type of a SSH server on the way to a node
+type rkeSSHHop struct {
+	address         string
+	username        string
+	sshKey          string
+	sshCert         string
+	useSSHAgentAuth bool
+	useProxyEnv     bool
+	hostKeyCallback ssh.HostKeyCallback
+}
+
+// rkeDialer connects to the node docker socket and local ports as the RKE dialer does, supporting
+// SSH host key verification, SSH jump host chains, proxy commands and direct docker connections
+type rkeDialer struct {
+	address       string
+	hops          []*rkeSSHHop
+	proxyCommand  string
+	dockerHost    *url.URL
+	dockerTLS     *tls.Config
+	localConnPort int
+	netConn       string
+	dockerSocket  string
+}
+
+// newRKEDialersOptions returns the RKE dialers for the config. Default RKE dialers are returned if nothing is configured
+func newRKEDialersOptions(in *rkeDialersConfig) (hosts.DialersOptions, error) {
+	if in.isDefault() {
+		return hosts.DialersOptions{}, nil
+	}
+
+	var knownHostsCallback ssh.HostKeyCallback
+	if len(in.KnownHostsPath) > 0 {
+		var err error
+		knownHostsCallback, err = knownhosts.New(expandHomePath(in.KnownHostsPath))
+		if err != nil {
+			return hosts.DialersOptions{}, fmt.Errorf("Failed reading known_hosts file %s: %v", in.KnownHostsPath, err)
+		}
+	}
+
+	// Network dialers are registered by address to route k8s api connections through node connections
+	networkDialers := &sync.Map{}
+	factory := func(kind string) hosts.DialerFactory {
+		return func(h *hosts.Host) (func(network, address string) (net.Conn, error), error) {
+			dialer, err := newRKEDialer(h, kind, in.bastionHosts(h), in.connection(h.Address), in.HostKeys[h.Address], knownHostsCallback)
+			if err != nil {
+				return nil, err
+			}
+			if kind != "network" && in.connection(h.Address).isTunnel() {
+				networkDialer, err := newRKEDialer(h, "network", in.bastionHosts(h), in.connection(h.Address), in.HostKeys[h.Address], knownHostsCallback)
+				if err != nil {
+					return nil, err
+				}
+				networkDialers.Store(h.Address, networkDialer)
+			}
+			return dialer.Dial, nil
+		}
+	}
+
+	var wrapTransport transport.WrapperFunc
+	if in.tunnelsK8s() {
+		// k8s api connections to other addresses go through the bastion host chain, if any
+		var bastionDialer *rkeDialer
+		if len(in.BastionHosts) > 0 {
+			var err error
+			bastionDialer, err = newRKEBastionDialer(in.BastionHosts, in.SSHKeyPath, in.SSHAgentAuth, knownHostsCallback)
+			if err != nil {
+				return hosts.DialersOptions{}, err
+			}
+		}
+		wrapTransport = newRKEK8sWrapTransport(networkDialers, bastionDialer)
+	}
+
+	return hosts.GetDialerOptions(factory("docker"), factory("network"), wrapTransport), nil
+}
+
+// newRKEK8sWrapTransport routes k8s api connections to a node address through the node connection
+func newRKEK8sWrapTransport(networkDialers *sync.Map, bastionDialer *rkeDialer) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if ht, ok := rt.(*http.Transport); ok {
+			ht.DialContext = nil
+			ht.DialTLS = nil
+			ht.Dial = func(network, address string) (net.Conn, error) {
+				host, _, err := net.SplitHostPort(address)
+				if err == nil {
+					if v, ok := networkDialers.Load(host); ok {
+						return v.(*rkeDialer).Dial(network, address)
+					}
+				}
+				if bastionDialer != nil {
+					return bastionDialer.Dial(network, address)
+				}
+				return net.DialTimeout(network, address, rkeDialerTimeout)
+			}
+		}
+		return rt
+	}
+}
+
+func newRKEDialer(h *hosts.Host, kind string, bastionHosts []rancher.BastionHost, conn *rkeConnection, hostKey rkeHostKey, knownHostsCallback ssh.HostKeyCallback) (*rkeDialer, error) {
+	dialer := &rkeDialer{
+		address:       h.Address,
+		localConnPort: h.LocalConnPort,
+		netConn:       "unix",
+		dockerSocket:  defaultString(h.DockerSocket, rkeDialerDockerSocketDefault),
+	}
+
+	if kind == "network" {
+		dialer.netConn = "tcp"
+	}
+
+	if conn.Type == rkeClusterConnectionTypeDocker {
+		var err error
+		dialer.dockerHost, err = url.Parse(conn.DockerHost)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing docker_host %s for host [%s]: %v", conn.DockerHost, h.Address, err)
+		}
+		dialer.dockerTLS, err = newDockerTLSConfig(dialer.dockerHost.Hostname(), conn.DockerCACert, conn.DockerClientCert, conn.DockerClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("Failed configuring docker TLS for host [%s]: %v", h.Address, err)
+		}
+		return dialer, nil
+	}
+
+	if conn.Type == rkeClusterConnectionTypeProxyCommand {
+		dialer.proxyCommand = conn.ProxyCommand
+	}
+
+	for _, bastionHost := range bastionHosts {
+		hop, err := newRKEBastionHop(bastionHost, h.SSHKeyPath, h.SSHAgentAuth, knownHostsCallback)
+		if err != nil {
+			return nil, err
+		}
+		dialer.hops = append(dialer.hops, hop)
+	}
+
+	for _, jumpHost := range conn.JumpHosts {
+		sshKeyPath := jumpHost.SSHKeyPath
+		if len(jumpHost.SSHKey) == 0 && len(sshKeyPath) == 0 {
+			sshKeyPath = h.SSHKeyPath
+		}
+		hop, err := newRKESSHHop(
+			jumpHost.Address,
+			jumpHost.Port,
+			jumpHost.User,
+			jumpHost.SSHKey,
+			sshKeyPath,
+			jumpHost.SSHCert,
+			jumpHost.SSHCertPath,
+			jumpHost.SSHAgentAuth || h.SSHAgentAuth,
+			jumpHost.HostKey,
+			knownHostsCallback,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Failed configuring jump host [%s]: %v", jumpHost.Address, err)
+		}
+		dialer.hops = append(dialer.hops, hop)
+	}
+
+	hop, err := newRKESSHHop(h.Address, h.Port, h.User, h.SSHKey, h.SSHKeyPath, h.SSHCert, h.SSHCertPath, h.SSHAgentAuth, hostKey, knownHostsCallback)
+	if err != nil {
+		return nil, fmt.Errorf("Failed configuring host [%s]: %v", h.Address, err)
+	}
+	dialer.hops = append(dialer.hops, hop)
+
+	return dialer, nil
+}
+
+// newRKEBastionDialer returns a dialer connecting to addresses from the last bastion host of the chain,
+// defaulting bastion hosts credentials to the cluster ones
+func newRKEBastionDialer(bastionHosts []rancher.BastionHost, sshKeyPath string, sshAgentAuth bool, knownHostsCallback ssh.HostKeyCallback) (*rkeDialer, error) {
+	dialer := &rkeDialer{
+		netConn: "tcp",
+	}
+	for _, bastionHost := range bastionHosts {
+		hop, err := newRKEBastionHop(bastionHost, sshKeyPath, sshAgentAuth, knownHostsCallback)
+		if err != nil {
+			return nil, err
+		}
+		dialer.hops = append(dialer.hops, hop)
+	}
+	return dialer, nil
+}
+
+// newRKEBastionHop returns the bastion host hop, defaulting credentials to the node ones.
+// Bastion hosts are dialed through ALL_PROXY env proxy unless they ignore proxy env vars
+func newRKEBastionHop(in rancher.BastionHost, sshKeyPath string, sshAgentAuth bool, knownHostsCallback ssh.HostKeyCallback) (*rkeSSHHop, error) {
+	if len(in.SSHKeyPath) > 0 || len(in.SSHKey) > 0 {
+		sshKeyPath = in.SSHKeyPath
+	}
+	hop, err := newRKESSHHop(
+		in.Address,
+		in.Port,
+		in.User,
+		in.SSHKey,
+		defaultString(sshKeyPath, rkeDialerSSHKeyPathDefault),
+		in.SSHCert,
+		in.SSHCertPath,
+		in.SSHAgentAuth || sshAgentAuth,
+		rkeHostKey{},
+		knownHostsCallback,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Failed configuring bastion host [%s]: %v", in.Address, err)
+	}
+	hop.useProxyEnv = !in.IgnoreProxyEnvVars
+	return hop, nil
+}
+
+func newRKESSHHop(address, port, user, sshKey, sshKeyPath, sshCert, sshCertPath string, sshAgentAuth bool, hostKey rkeHostKey, knownHostsCallback ssh.HostKeyCallback) (*rkeSSHHop, error) {
+	hostKeyCallback, err := newRKEHostKeyCallback(hostKey, knownHostsCallback)
+	if err != nil {
+		return nil, fmt.Errorf("configuring host key verification: %v", err)
+	}
+
+	hop := &rkeSSHHop{
+		address:         net.JoinHostPort(address, defaultString(port, rkeDialerSSHPortDefault)),
+		username:        user,
+		sshKey:          sshKey,
+		sshCert:         sshCert,
+		useSSHAgentAuth: sshAgentAuth,
+		hostKeyCallback: hostKeyCallback,
+	}
+
+	if len(hop.sshKey) > 0 || hop.useSSHAgentAuth {
+		return hop, nil
+	}
+
+	hop.sshKey, err = readSSHFile(sshKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error while reading SSH key file: %v", err)
+	}
+
+	if len(hop.sshCert) == 0 && len(sshCertPath) > 0 {
+		hop.sshCert, err = readSSHFile(sshCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("Error while reading SSH certificate file: %v", err)
+		}
+	}
+
+	return hop, nil
+}
+
+// Dial opens a connection to address through the node connection, or to the docker socket for docker dialers
+func (d *rkeDialer) Dial(network, address string) (net.Conn, error) {
+	if d.dockerHost != nil {
+		return d.dialDocker(network, address)
+	}
+
+	clients, err := d.sshClients()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dial ssh using address [%s]: %v", d.hops[len(d.hops)-1].address, err)
+	}
+
+	if d.netConn == "unix" {
+		network = d.netConn
+		address = d.dockerSocket
+	}
+
+	remote, err := clients[len(clients)-1].Dial(network, address)
+	if err != nil {
+		closeRKESSHClients(clients)
+		return nil, fmt.Errorf("Failed to dial to %s: %v", address, err)
+	}
+	return &rkeSSHConn{Conn: remote, clients: clients}, nil
+}
+
+func (d *rkeDialer) dialDocker(network, address string) (net.Conn, error) {
+	// Local connections are done directly to the node, as for DinD
+	if d.netConn == "tcp" {
+		if d.localConnPort > 0 {
+			address = net.JoinHostPort(d.address, strconv.Itoa(d.localConnPort))
+		}
+		return net.DialTimeout(network, address, rkeDialerTimeout)
+	}
+
+	if d.dockerHost.Scheme == "unix" {
+		return net.DialTimeout("unix", d.dockerHost.Path, rkeDialerTimeout)
+	}
+
+	conn, err := net.DialTimeout("tcp", d.dockerHost.Host, rkeDialerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dial docker host [%s]: %v", d.dockerHost.Host, err)
+	}
+	if d.dockerTLS != nil {
+		return tls.Client(conn, d.dockerTLS), nil
+	}
+	return conn, nil
+}
+
+// sshClients returns the SSH clients of every hop, from the first one to the node. Clients already opened are closed on error
+func (d *rkeDialer) sshClients() ([]*ssh.Client, error) {
+	first := d.hops[0]
+
+	var conn net.Conn
+	var err error
+	if len(d.proxyCommand) > 0 {
+		conn, err = newCommandConn(expandProxyCommand(d.proxyCommand, first))
+	} else if first.useProxyEnv {
+		conn, err = proxy.FromEnvironmentUsing(&net.Dialer{Timeout: rkeDialerTimeout}).Dial("tcp", first.address)
+	} else {
+		conn, err = net.DialTimeout("tcp", first.address, rkeDialerTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to [%s]: %v", first.address, err)
+	}
+
+	client, err := first.newClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	clients := []*ssh.Client{client}
+
+	for _, hop := range d.hops[1:] {
+		conn, err := client.Dial("tcp", hop.address)
+		if err != nil {
+			closeRKESSHClients(clients)
+			return nil, fmt.Errorf("Failed to connect to the host [%s] from [%s]: %v", hop.address, client.RemoteAddr(), err)
+		}
+		client, err = hop.newClient(conn)
+		if err != nil {
+			conn.Close()
+			closeRKESSHClients(clients)
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+// closeRKESSHClients closes SSH clients from the last hop to the first one
+func closeRKESSHClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
+	}
+}
+
+// rkeSSHConn is a connection through a SSH client chain, closing the whole chain on Close
+type rkeSSHConn struct {
+	net.Conn
+	clients []*ssh.Client
+}
+
+func (c *rkeSSHConn) Close() error {
+	err := c.Conn.Close()
+	closeRKESSHClients(c.clients)
+	return err
+}
+
+func (h *rkeSSHHop) newClient(conn net.Conn) (*ssh.Client, error) {
+	cfg, err := h.sshClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Error configuring SSH for host [%s]: %v", h.address, err)
+	}
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, h.address, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to establish new ssh client conn [%s]: %v", h.address, err)
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+func (h *rkeSSHHop) sshClientConfig() (*ssh.ClientConfig, error) {
+	config := &ssh.ClientConfig{
+		User:            h.username,
+		HostKeyCallback: h.hostKeyCallback,
+		Timeout:         rkeDialerTimeout,
+	}
+
+	if h.useSSHAgentAuth {
+		if sshAgentSock := os.Getenv("SSH_AUTH_SOCK"); sshAgentSock != "" {
+			sshAgent, err := net.Dial("unix", sshAgentSock)
+			if err != nil {
+				return config, fmt.Errorf("Cannot connect to SSH Auth socket %q: %s", sshAgentSock, err)
+			}
+			config.Auth = append(config.Auth, ssh.PublicKeysCallback(agent.NewClient(sshAgent).Signers))
+			log.Debugf("[rke_provider] using %q SSH_AUTH_SOCK", sshAgentSock)
+			return config, nil
+		}
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(h.sshKey))
+	if err != nil {
+		return config, err
+	}
+
+	if len(h.sshCert) > 0 {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(h.sshCert))
+		if err != nil {
+			return config, fmt.Errorf("Unable to parse SSH certificate: %v", err)
+		}
+		cert, ok := key.(*ssh.Certificate)
+		if !ok {
+			return config, fmt.Errorf("Unable to cast public key to SSH Certificate")
+		}
+		signer, err = ssh.NewCertSigner(cert, signer)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+
+	return config, nil
+}
+
+// newRKEHostKeyCallback returns the host key callback by precedence: fingerprint, known_hosts entries, known_hosts file
+func newRKEHostKeyCallback(in rkeHostKey, knownHostsCallback ssh.HostKeyCallback) (ssh.HostKeyCallback, error) {
+	if len(in.Fingerprint) > 0 {
+		return fingerprintHostKeyCallback(in.Fingerprint), nil
+	}
+	if len(in.KnownHosts) > 0 {
+		return knownHostsContentCallback(in.KnownHosts)
+	}
+	if knownHostsCallback != nil {
+		return knownHostsCallback, nil
+	}
+	return ssh.InsecureIgnoreHostKey(), nil
+}
+
+func fingerprintHostKeyCallback(fingerprint string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if got := ssh.FingerprintSHA256(key); got != strings.TrimSuffix(fingerprint, "=") {
+			return fmt.Errorf("host key fingerprint mismatch for %s: expected %s, got %s", hostname, fingerprint, got)
+		}
+		return nil
+	}
+}
+
+func knownHostsContentCallback(content string) (ssh.HostKeyCallback, error) {
+	f, err := os.CreateTemp("", "terraform-provider-rke-known-hosts-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.WriteString(content); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+
+	return knownhosts.New(f.Name())
+}
+
+func newDockerTLSConfig(serverName, caCert, clientCert, clientKey string) (*tls.Config, error) {
+	if len(caCert) == 0 && len(clientCert) == 0 {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if len(caCert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("docker_ca_cert is not a valid PEM certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	if len(clientCert) > 0 {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("loading docker client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// expandProxyCommand replaces %h, %p and %r tokens as ssh ProxyCommand does
+func expandProxyCommand(command string, hop *rkeSSHHop) string {
+	host, port, _ := net.SplitHostPort(hop.address)
+	return strings.NewReplacer("%h", host, "%p", port, "%r", hop.username, "%%", "%").Replace(command)
+}
+
+// commandConn is a net.Conn over the stdin and stdout of a local command
+type commandConn struct {
+	cmd    *exec.Cmd
+	reader io.ReadCloser
+	writer io.WriteCloser
+}
+
+func newCommandConn(command string) (net.Conn, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+	cmd.Stderr = log.StandardLogger().WriterLevel(log.DebugLevel)
+
+	writer, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting proxy command %q: %v", command, err)
+	}
+
+	return &commandConn{cmd: cmd, reader: reader, writer: writer}, nil
+}
+
+func (c *commandConn) Read(b []byte) (int, error)  { return c.reader.Read(b) }
+func (c *commandConn) Write(b []byte) (int, error) { return c.writer.Write(b) }
+
+func (c *commandConn) Close() error {
+	c.writer.Close()
+	c.reader.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr                { return commandAddr{} }
+func (c *commandConn) RemoteAddr() net.Addr               { return commandAddr{} }
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "command" }
+func (commandAddr) String() string  { return "command" }
+
+func readSSHFile(path string) (string, error) {
+	data, err := os.ReadFile(expandHomePath(path))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func expandHomePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+func defaultString(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
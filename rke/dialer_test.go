@@ -0,0 +1,98 @@
+package rke
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	rancher "github.com/rancher/rke/types"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewRKEBastionHop(t *testing.T) {
+
+	cases := []struct {
+		Input          rancher.BastionHost
+		ExpectedOutput bool
+	}{
+		{
+			rancher.BastionHost{Address: "bastion.terraform.test", User: "test", SSHAgentAuth: true},
+			true,
+		},
+		{
+			rancher.BastionHost{Address: "bastion.terraform.test", User: "test", SSHAgentAuth: true, IgnoreProxyEnvVars: true},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := newRKEBastionHop(tc.Input, "", false, nil)
+		if err != nil {
+			t.Fatalf("[ERROR] on bastion hop: %#v", err)
+		}
+		if output.useProxyEnv != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output from bastion hop on input %#v\nExpected proxy env: %t\nGiven:    %t",
+				tc.Input, tc.ExpectedOutput, output.useProxyEnv)
+		}
+	}
+}
+
+func TestRKEDialerProxyCommandClosed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("proxy command test requires a posix shell")
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("[ERROR] generating ssh key: %#v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatalf("[ERROR] marshaling ssh key: %#v", err)
+	}
+
+	// Proxy command answers a valid ssh version and an oversized packet, so the handshake fails while it keeps running
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	dialer := &rkeDialer{
+		address:      "node.terraform.test",
+		netConn:      "unix",
+		dockerSocket: rkeDialerDockerSocketDefault,
+		proxyCommand: "echo $$ > " + pidFile + "; printf 'SSH-2.0-test\\r\\n\\377\\377\\377\\377\\377\\377\\377\\377'; exec sleep 60",
+		hops: []*rkeSSHHop{
+			{
+				address:         "node.terraform.test:22",
+				username:        "test",
+				sshKey:          string(pem.EncodeToMemory(block)),
+				hostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			},
+		},
+	}
+
+	if _, err := dialer.Dial("tcp", "127.0.0.1:6443"); err == nil {
+		t.Fatalf("[ERROR] dial through proxy command should fail on ssh handshake")
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("[ERROR] reading proxy command pid: %#v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("[ERROR] parsing proxy command pid: %#v", err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatalf("[ERROR] finding proxy command process: %#v", err)
+	}
+	if err := process.Signal(syscall.Signal(0)); err == nil {
+		process.Kill()
+		t.Fatalf("Unexpected proxy command process %d running after failed ssh handshake", pid)
+	}
+}
@@ -21,21 +21,144 @@ func Provider() *schema.Provider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("RKE_LOG_FILE", ""),
 			},
+			"ssh": {
+				Type:        schema.TypeList,
+				MaxItems:    1,
+				Optional:    true,
+				Description: "Default SSH configuration for RKE cluster nodes and bastion host",
+				Elem: &schema.Resource{
+					Schema: providerSSHFields(),
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"rke_cluster": resourceRKECluster(),
+			"rke_cluster":       resourceRKECluster(),
+			"rke_cluster_files": resourceRKEClusterFiles(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"rke_host_preflight": dataSourceRKEHostPreflight(),
 		},
-		DataSourcesMap:       map[string]*schema.Resource{},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
 
+func providerSSHFields() map[string]*schema.Schema {
+	bastionHost := rkeClusterBastionHostFields()
+	for k := range bastionHost {
+		bastionHost[k].Computed = false
+	}
+
+	s := map[string]*schema.Schema{
+		"bastion_host": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Default bastion host for RKE clusters not defining one",
+			Elem: &schema.Resource{
+				Schema: bastionHost,
+			},
+		},
+		"known_hosts_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "known_hosts file used to verify SSH host keys",
+		},
+		"port": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Default SSH port",
+		},
+		"ssh_agent_auth": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Default SSH Agent Auth enable",
+		},
+		"ssh_cert": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Default SSH Certificate",
+		},
+		"ssh_cert_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Default SSH Certificate path",
+		},
+		"ssh_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Default SSH Private Key",
+		},
+		"ssh_key_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Default SSH Private Key path",
+		},
+		"user": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Default SSH user",
+		},
+	}
+	return s
+}
+
 func providerConfigure(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
 	config := &Config{
 		Debug:   d.Get("debug").(bool),
 		LogFile: d.Get("log_file").(string),
+		SSH:     expandProviderSSH(d.Get("ssh").([]interface{})),
 	}
 
 	config.initLogger()
 	return config, nil
 }
+
+func expandProviderSSH(p []interface{}) *SSHConfig {
+	obj := &SSHConfig{}
+	if len(p) == 0 || p[0] == nil {
+		return obj
+	}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["bastion_host"].([]interface{}); ok && len(v) > 0 {
+		bastionHost := expandRKEClusterBastionHost(v)
+		obj.BastionHost = &bastionHost
+	}
+
+	if v, ok := in["known_hosts_path"].(string); ok && len(v) > 0 {
+		obj.KnownHostsPath = v
+	}
+
+	if v, ok := in["port"].(string); ok && len(v) > 0 {
+		obj.Port = v
+	}
+
+	if v, ok := in["ssh_agent_auth"].(bool); ok {
+		obj.SSHAgentAuth = v
+	}
+
+	if v, ok := in["ssh_cert"].(string); ok && len(v) > 0 {
+		obj.SSHCert = v
+	}
+
+	if v, ok := in["ssh_cert_path"].(string); ok && len(v) > 0 {
+		obj.SSHCertPath = v
+	}
+
+	if v, ok := in["ssh_key"].(string); ok && len(v) > 0 {
+		obj.SSHKey = v
+	}
+
+	if v, ok := in["ssh_key_path"].(string); ok && len(v) > 0 {
+		obj.SSHKeyPath = v
+	}
+
+	if v, ok := in["user"].(string); ok && len(v) > 0 {
+		obj.User = v
+	}
+
+	return obj
+}
@@ -4,22 +4,32 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rancher/rke/cluster"
 	"github.com/rancher/rke/cmd"
 	"github.com/rancher/rke/dind"
+	"github.com/rancher/rke/docker"
 	"github.com/rancher/rke/hosts"
 	"github.com/rancher/rke/pki"
 	v3 "github.com/rancher/rke/types"
+	"github.com/rancher/rke/util"
 	log "github.com/sirupsen/logrus"
 )
 
-const rkeClusterDINDWaitTime = 3
+const (
+	rkeClusterDINDWaitTime             = 3
+	rkeClusterKubeAPIFilesDeployerName = "rke-provider-file-deployer"
+	rkeClusterKubeAPIFilesDeployerEnv  = "RKE_PROVIDER_FILE"
+)
 
 func resourceRKECluster() *schema.Resource {
 	return &schema.Resource{
@@ -32,6 +42,96 @@ func resourceRKECluster() *schema.Resource {
 		},
 		Schema: rkeClusterFields(),
 		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, i interface{}) error {
+			if v, ok := d.Get("ingress").([]interface{}); ok && len(v) > 0 {
+				ingress, err := expandRKEClusterIngress(v)
+				if err != nil {
+					return err
+				}
+				if err := validateRKEClusterIngress(ingress); err != nil {
+					return err
+				}
+			}
+			if v, ok := d.Get("services").([]interface{}); ok && len(v) > 0 && v[0] != nil {
+				if kubelet, ok := v[0].(map[string]interface{})["kubelet"].([]interface{}); ok && len(kubelet) > 0 {
+					rawKubelet := rkeClusterRawConfigBlock(d.GetRawConfig(), "services", "kubelet")
+					configured := func(field string) bool {
+						return rkeClusterConfigIsSet(rawKubelet, field)
+					}
+					if err := validateRKEClusterServicesKubelet(expandRKEClusterServicesKubelet(kubelet), configured); err != nil {
+						return err
+					}
+				}
+			}
+			if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) > 1 {
+				if err := validateRKEClusterBastionHosts(expandRKEClusterBastionHosts(v)); err != nil {
+					return err
+				}
+			}
+			for _, key := range []string{"dns", "ingress", "monitoring", "network"} {
+				if v, ok := d.Get(key + ".0.tolerations").([]interface{}); ok && len(v) > 0 {
+					if err := validateRKEClusterTolerations(expandRKEClusterTolerations(v)); err != nil {
+						return fmt.Errorf("%s %v", key, err)
+					}
+				}
+			}
+			rawNetwork := rkeClusterRawConfigBlock(d.GetRawConfig(), "network")
+			configured := func(provider, field string) bool {
+				return rkeClusterConfigIsSet(rkeClusterRawConfigBlock(rawNetwork, provider), field)
+			}
+			if err := validateRKEClusterNetworkOptions(rkeClusterRawConfigMapKeys(rawNetwork, "options"), configured); err != nil {
+				return err
+			}
+			if v, ok := d.Get("dns").([]interface{}); ok && len(v) > 0 {
+				services, err := expandRKEClusterServices(d.Get("services").([]interface{}))
+				if err != nil {
+					return err
+				}
+				serviceClusterIPRange := services.KubeAPI.ServiceClusterIPRange
+				if len(serviceClusterIPRange) == 0 {
+					serviceClusterIPRange = services.KubeController.ServiceClusterIPRange
+				}
+				if err := validateRKEClusterDNS(expandRKEClusterDNS(v), serviceClusterIPRange); err != nil {
+					return err
+				}
+			}
+			if d.HasChanges("cloud_provider", "cluster_yaml") {
+				if rkeClusterConfigWhollyKnown(d, "cloud_provider") && rkeClusterConfigWhollyKnown(d, "cluster_yaml") {
+					if _, err := expandRKEClusterCloudProviderExternalAddons(d.Get("cloud_provider").([]interface{})); err != nil {
+						return err
+					}
+					renderedCloudConfig, err := flattenRKEClusterRenderedCloudConfig(d.Get("cluster_yaml").(string), d.Get("cloud_provider").([]interface{}))
+					if err != nil {
+						return err
+					}
+					if err := d.SetNew("rendered_cloud_config", renderedCloudConfig); err != nil {
+						return err
+					}
+				} else if err := d.SetNewComputed("rendered_cloud_config"); err != nil {
+					return err
+				}
+			}
+			rkeConfig, err := expandRKEClusterCIDRsConfig(d)
+			if err != nil {
+				return err
+			}
+			if err := validateRKEClusterCIDRs(rkeConfig); err != nil {
+				return err
+			}
+			// Network is validated on typed network block overlaid on cluster_yaml, like it's passed to RKE
+			if v, ok := d.Get("nodes").([]interface{}); ok && len(v) > 0 && rkeConfig != nil && rkeClusterConfigWhollyKnown(d, "network") {
+				if err := validateRKEClusterNodesWindows(v, rkeConfig.Network); err != nil {
+					return err
+				}
+			}
+			if v, ok := d.Get("rotate_encryption_key").(string); ok && len(v) > 0 && d.HasChange("rotate_encryption_key") {
+				services, err := expandRKEClusterServices(d.Get("services").([]interface{}))
+				if err != nil {
+					return err
+				}
+				if err := validateRKEClusterRotateEncryptionKey(services.KubeAPI); err != nil {
+					return err
+				}
+			}
 			if changedKeys := getChangedKeys(d); len(changedKeys) > 0 {
 				log.Infof("[rke_provider] rke cluster changed arguments: %v", changedKeys)
 				if log.IsLevelEnabled(log.DebugLevel) {
@@ -44,6 +144,7 @@ func resourceRKECluster() *schema.Resource {
 					"rke_state",
 					"kube_config_yaml",
 					"rke_cluster_yaml",
+					"rke_cluster_yaml_redacted",
 				}
 
 				if changedKeys["rotate_certificates"] || changedKeys["cluster_yaml"] {
@@ -53,17 +154,25 @@ func resourceRKECluster() *schema.Resource {
 				}
 
 				if changedKeys["dns"] || changedKeys["services"] || changedKeys["cluster_yaml"] {
-					for _, key := range []string{"cluster_domain", "cluster_cidr", "cluster_dns_server"} {
+					for _, key := range []string{"cluster_domain", "cluster_cidr", "cluster_cidr_ipv4", "cluster_cidr_ipv6", "cluster_dns_server", "dual_stack", "service_cluster_ip_range_ipv4", "service_cluster_ip_range_ipv6"} {
 						computedFields = append(computedFields, key)
 					}
 				}
 
-				if changedKeys["nodes"] || changedKeys["cluster_yaml"] {
-					for _, key := range []string{"api_server_url", "etcd_hosts", "control_plane_hosts", "inactive_hosts", "worker_hosts"} {
+				if changedKeys["nodes"] || changedKeys["cluster_yaml"] || changedKeys["api_server_endpoint"] {
+					for _, key := range []string{"api_server_url", "api_server_urls", "etcd_hosts", "control_plane_hosts", "inactive_hosts", "worker_hosts"} {
 						computedFields = append(computedFields, key)
 					}
 				}
 
+				if changedKeys["rotate_encryption_key"] || changedKeys["services"] || changedKeys["cluster_yaml"] {
+					computedFields = append(computedFields, "encryption_key_name")
+				}
+
+				if changedKeys["rotate_encryption_key"] {
+					computedFields = append(computedFields, "encryption_key_rotated_at")
+				}
+
 				if changedKeys["kubernetes_version"] || changedKeys["system_images"] || changedKeys["cluster_yaml"] {
 					computedFields = append(computedFields, "running_system_images")
 				}
@@ -89,30 +198,65 @@ func resourceRKEClusterCreate(ctx context.Context, d *schema.ResourceData, meta
 	if delay, ok := d.Get("delay_on_creation").(int); ok && delay > 0 {
 		time.Sleep(time.Duration(delay) * time.Second)
 	}
-	if err := clusterUp(d); err != nil {
+	if err := clusterUp(d, meta.(*Config)); err != nil {
 		return meta.(*Config).saveRKEOutput(err)
 	}
-	return resourceRKEClusterRead(ctx, d, meta)
+	// Encryption key is generated on cluster creation, so the rotation trigger is recorded as done
+	if v, ok := d.Get("rotate_encryption_key").(string); ok && len(v) > 0 {
+		d.Set("encryption_key_rotated_at", time.Now().UTC().Format(time.RFC3339)) // nolint
+	}
+	return resourceRKEClusterReadAndWait(ctx, d, meta)
 }
 
 func resourceRKEClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Info("Updating RKE cluster...")
 
-	restored, err := clusterRestore(d)
-	if err != nil {
+	// Rotation trigger is set back to its old value on error, so a failed rotation is retried on next apply
+	rotateEncryptionKey := false
+	if v, ok := d.Get("rotate_encryption_key").(string); ok && len(v) > 0 && d.HasChange("rotate_encryption_key") {
+		rotateEncryptionKey = true
+	}
+	updateErr := func(err error) diag.Diagnostics {
+		if rotateEncryptionKey {
+			old, _ := d.GetChange("rotate_encryption_key")
+			d.Set("rotate_encryption_key", old) // nolint
+		}
 		return meta.(*Config).saveRKEOutput(err)
 	}
+
+	restored, err := clusterRestore(d, meta.(*Config))
+	if err != nil {
+		return updateErr(err)
+	}
 	if !restored {
-		if err := clusterUp(d); err != nil {
-			return meta.(*Config).saveRKEOutput(err)
+		if err := clusterUp(d, meta.(*Config)); err != nil {
+			return updateErr(err)
 		}
 	}
-	return resourceRKEClusterRead(ctx, d, meta)
+	if rotateEncryptionKey {
+		if err := clusterRotateEncryptionKey(d, meta.(*Config)); err != nil {
+			return updateErr(err)
+		}
+		d.Set("encryption_key_rotated_at", time.Now().UTC().Format(time.RFC3339)) // nolint
+	}
+	return resourceRKEClusterReadAndWait(ctx, d, meta)
+}
+
+// resourceRKEClusterReadAndWait reads the cluster and waits for wait_for gates, once state is set
+func resourceRKEClusterReadAndWait(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	diags := resourceRKEClusterRead(ctx, d, meta)
+	if diags.HasError() || len(d.Id()) == 0 {
+		return diags
+	}
+	if err := waitForRKECluster(ctx, d, meta.(*Config)); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
 }
 
 func resourceRKEClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Infof("Reading RKE cluster %s ...", d.Id())
-	currentCluster, err := readClusterState(d)
+	currentCluster, err := readClusterState(d, meta.(*Config))
 	if err != nil {
 		return meta.(*Config).saveRKEOutput(err)
 	}
@@ -122,7 +266,7 @@ func resourceRKEClusterRead(ctx context.Context, d *schema.ResourceData, meta in
 
 func resourceRKEClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	log.Info("Deleting RKE cluster...")
-	err := clusterDelete(d)
+	err := clusterDelete(d, meta.(*Config))
 	if err != nil {
 		return meta.(*Config).saveRKEOutput(err)
 	}
@@ -130,16 +274,28 @@ func resourceRKEClusterDelete(ctx context.Context, d *schema.ResourceData, meta
 	return nil
 }
 
-func clusterUp(d *schema.ResourceData) error {
-	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d)
+func clusterUp(d *schema.ResourceData, config *Config) error {
+	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d, config)
 	defer removeTempDir(tempDir)
 	if err != nil {
 		return err
 	}
 
+	secrets, err := expandRKEClusterCloudProviderSecrets(d.Get("cloud_provider").([]interface{}), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("Failed resolving cloud_provider secrets: %v", err)
+	}
+	dockerConfigRegistries, err := expandRKEClusterPrivateRegistriesDockerConfig(d.Get("private_registries").([]interface{}), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("Failed reading private_registries docker config: %v", err)
+	}
+
 	// setting up the flags, dialers and context
 	flags := expandRKEClusterFlag(d, clusterFilePath)
-	dialers := hosts.DialersOptions{}
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return err
+	}
 
 	// setting dind if needed
 	if d.Get("dind").(bool) {
@@ -151,12 +307,16 @@ func clusterUp(d *schema.ResourceData) error {
 		dialers = hosts.GetDialerOptions(hosts.DindConnFactory, hosts.DindHealthcheckConnFactory, nil)
 	}
 
+	if err := deployRKEClusterKubeAPIFiles(context.Background(), rkeConfig, expandRKEClusterServicesKubeAPIInputFiles(d.Get("services").([]interface{})), dialers, flags); err != nil {
+		return fmt.Errorf("Failed deploying kube-api files err:%v", err)
+	}
+
 	if err := cmd.ClusterInit(context.Background(), rkeConfig, dialers, flags); err != nil {
 		return fmt.Errorf("Failed initializing cluster err:%v", err)
 	}
 	// set init cluster state to resourceData
 	flattenRKEClusterFlag(d, &flags)
-	err = setRKEClusterState(d, tempDir)
+	err = setRKEClusterState(d, tempDir, secrets, dockerConfigRegistries)
 	if err != nil {
 		return fmt.Errorf("Failed setting initial cluster state err:%v", err)
 	}
@@ -164,7 +324,7 @@ func clusterUp(d *schema.ResourceData) error {
 	_, _, _, _, _, clusterUpErr := cmd.ClusterUp(context.Background(), dialers, flags, map[string]interface{}{})
 
 	// set cluster state to resourceData
-	err = setRKEClusterState(d, tempDir)
+	err = setRKEClusterState(d, tempDir, secrets, dockerConfigRegistries)
 	if clusterUpErr != nil {
 		return fmt.Errorf("Failed running cluster err:%v", clusterUpErr)
 	}
@@ -175,8 +335,8 @@ func clusterUp(d *schema.ResourceData) error {
 	return nil
 }
 
-func clusterRestore(d *schema.ResourceData) (bool, error) {
-	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d)
+func clusterRestore(d *schema.ResourceData, config *Config) (bool, error) {
+	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d, config)
 	defer removeTempDir(tempDir)
 	if err != nil {
 		return false, err
@@ -189,9 +349,25 @@ func clusterRestore(d *schema.ResourceData) (bool, error) {
 		return false, fmt.Errorf("Failed restoring cluster: snapshop_name must be provided")
 	}
 
+	secrets, err := expandRKEClusterCloudProviderSecrets(d.Get("cloud_provider").([]interface{}), rkeConfig)
+	if err != nil {
+		return false, fmt.Errorf("Failed resolving cloud_provider secrets: %v", err)
+	}
+	dockerConfigRegistries, err := expandRKEClusterPrivateRegistriesDockerConfig(d.Get("private_registries").([]interface{}), rkeConfig)
+	if err != nil {
+		return false, fmt.Errorf("Failed reading private_registries docker config: %v", err)
+	}
+
 	// setting up the flags, dialers and context
 	flags := expandRKEClusterFlag(d, clusterFilePath)
-	dialers := hosts.DialersOptions{}
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return false, err
+	}
+
+	if err := deployRKEClusterKubeAPIFiles(context.Background(), rkeConfig, expandRKEClusterServicesKubeAPIInputFiles(d.Get("services").([]interface{})), dialers, flags); err != nil {
+		return false, fmt.Errorf("Failed deploying kube-api files err:%v", err)
+	}
 
 	// set restore to false to force diff on next apply
 	rkeConfig.Restore.Restore = false
@@ -199,7 +375,7 @@ func clusterRestore(d *schema.ResourceData) (bool, error) {
 
 	// set cluster state to resourceData
 	flattenRKEClusterFlag(d, &flags)
-	err = setRKEClusterState(d, tempDir)
+	err = setRKEClusterState(d, tempDir, secrets, dockerConfigRegistries)
 	if clusterRestoreErr != nil {
 		return false, fmt.Errorf("Failed restoring cluster err:%v", clusterRestoreErr)
 	}
@@ -210,6 +386,48 @@ func clusterRestore(d *schema.ResourceData) (bool, error) {
 	return true, nil
 }
 
+func clusterRotateEncryptionKey(d *schema.ResourceData, config *Config) error {
+	_, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d, config)
+	defer removeTempDir(tempDir)
+	if err != nil {
+		return err
+	}
+
+	// setting up the flags and dialers
+	flags := expandRKEClusterFlag(d, clusterFilePath)
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return err
+	}
+
+	// rotating the key of the applied config, as rke up does
+	fullState, err := cluster.ReadStateFile(context.Background(), cluster.GetStateFilePath(clusterFilePath, ""))
+	if err != nil || fullState.CurrentState.RancherKubernetesEngineConfig == nil {
+		return fmt.Errorf("Failed rotating encryption key: cluster state not found")
+	}
+	rkeConfig := fullState.CurrentState.RancherKubernetesEngineConfig.DeepCopy()
+	secrets, err := expandRKEClusterCloudProviderSecrets(d.Get("cloud_provider").([]interface{}), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("Failed resolving cloud_provider secrets: %v", err)
+	}
+	dockerConfigRegistries, err := expandRKEClusterPrivateRegistriesDockerConfig(d.Get("private_registries").([]interface{}), rkeConfig)
+	if err != nil {
+		return fmt.Errorf("Failed reading private_registries docker config: %v", err)
+	}
+	_, _, _, _, _, rotateErr := cmd.RotateEncryptionKey(context.Background(), rkeConfig, dialers, flags)
+
+	// set cluster state to resourceData
+	err = setRKEClusterState(d, tempDir, secrets, dockerConfigRegistries)
+	if rotateErr != nil {
+		return fmt.Errorf("Failed rotating encryption key err:%v", rotateErr)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed setting cluster state err:%v", err)
+	}
+
+	return nil
+}
+
 func prepareDINDEnv(ctx context.Context, rkeConfig *v3.RancherKubernetesEngineConfig, dindStorageDriver, dindDNS string) error {
 	for i := range rkeConfig.Nodes {
 		address, err := dind.StartUpDindContainer(ctx, rkeConfig.Nodes[i].Address, dind.DINDNetwork, dindStorageDriver, dindDNS)
@@ -225,8 +443,86 @@ func prepareDINDEnv(ctx context.Context, rkeConfig *v3.RancherKubernetesEngineCo
 	return nil
 }
 
-func clusterDelete(d *schema.ResourceData) error {
-	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d)
+// deployRKEClusterKubeAPIFiles writes provider managed kube-api files to control plane nodes, and sets kube-api on rkeConfig to use them.
+// RKE only writes EventRateLimit and PodSecurity plugins to its admission configuration, so the consolidated one is written here
+func deployRKEClusterKubeAPIFiles(ctx context.Context, rkeConfig *v3.RancherKubernetesEngineConfig, files map[string]string, dialers hosts.DialersOptions, flags cluster.ExternalFlags) error {
+	if len(files) == 0 && rkeConfig.Services.KubeAPI.AdmissionConfiguration == nil {
+		return nil
+	}
+
+	// RKE defaults are needed to build the files and to reach the nodes
+	kubeCluster, err := cluster.InitClusterObject(ctx, rkeConfig.DeepCopy(), flags, "")
+	if err != nil {
+		return err
+	}
+	if rkeConfig.Services.KubeAPI.AdmissionConfiguration != nil {
+		files[clusterServicesKubeAPIAdmissionConfigurationFile], err = expandRKEClusterServicesKubeAPIAdmissionConfigurationFile(kubeCluster.Services.KubeAPI, kubeCluster.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := kubeCluster.SetupDialers(ctx, dialers); err != nil {
+		return err
+	}
+	if err := kubeCluster.TunnelHosts(ctx, flags); err != nil {
+		return err
+	}
+	for _, h := range kubeCluster.ControlPlaneHosts {
+		log.Infof("[rke_provider] Deploying kube-api files to node [%s]", h.Address)
+		if err := deployRKEClusterKubeAPIFilesToHost(ctx, h, kubeCluster, files); err != nil {
+			return fmt.Errorf("Failed deploying kube-api files to node [%s]: %v", h.Address, err)
+		}
+	}
+
+	expandRKEClusterServicesKubeAPIFiles(&rkeConfig.Services.KubeAPI, kubeCluster.PrefixPath, files)
+	return nil
+}
+
+// deployRKEClusterKubeAPIFilesToHost writes files atomically with 0600 permissions, as RKE file deployer does
+func deployRKEClusterKubeAPIFilesToHost(ctx context.Context, h *hosts.Host, kubeCluster *cluster.Cluster, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	script := "set -e\n"
+	env := make([]string, 0, len(names))
+	for i, name := range names {
+		env = append(env, fmt.Sprintf("%s_%d=%s", rkeClusterKubeAPIFilesDeployerEnv, i, files[name]))
+		script += fmt.Sprintf("t=$(mktemp %[1]s/.%[2]s.XXXXXX)\nprintf '%%s' \"$%[3]s_%[4]d\" > $t\nchmod 600 $t\nmv $t %[1]s/%[2]s\n", clusterServicesKubeAPIFilesDir, name, rkeClusterKubeAPIFilesDeployerEnv, i)
+	}
+	imageCfg := &container.Config{
+		Image: kubeCluster.SystemImages.Alpine,
+		Cmd:   []string{"sh", "-c", script},
+		Env:   env,
+	}
+
+	hostCfg := &container.HostConfig{}
+	bind := path.Join(kubeCluster.PrefixPath, clusterServicesKubeAPIFilesDir) + ":" + clusterServicesKubeAPIFilesDir
+	// SELinux labels are rewritten for k8s versions lower than 1.22, as RKE does
+	matchedRange, err := util.SemVerMatchRange(kubeCluster.Version, util.SemVerK8sVersion122OrHigher)
+	if err != nil {
+		return err
+	}
+	if !matchedRange {
+		bind += ":z"
+	} else if hosts.IsDockerSELinuxEnabled(h) {
+		hostCfg.SecurityOpt = append(hostCfg.SecurityOpt, cluster.SELinuxLabel)
+	}
+	hostCfg.Binds = []string{bind}
+
+	if err := docker.DoRemoveContainer(ctx, h.DClient, rkeClusterKubeAPIFilesDeployerName, h.Address); err != nil {
+		return err
+	}
+	if err := docker.DoRunOnetimeContainer(ctx, h.DClient, imageCfg, hostCfg, rkeClusterKubeAPIFilesDeployerName, h.Address, "controlPlane", kubeCluster.PrivateRegistriesMap); err != nil {
+		return err
+	}
+	return docker.DoRemoveContainer(ctx, h.DClient, rkeClusterKubeAPIFilesDeployerName, h.Address)
+}
+
+func clusterDelete(d *schema.ResourceData, config *Config) error {
+	rkeConfig, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d, config)
 	defer removeTempDir(tempDir)
 	if err != nil {
 		return err
@@ -241,16 +537,25 @@ func clusterDelete(d *schema.ResourceData) error {
 		return nil
 	}
 
-	// setting up the flags
+	// setting up the flags and dialers
 	flags := cluster.GetExternalFlags(false, false, false, false, "", clusterFilePath)
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return err
+	}
+
+	// Cleanup images may be pulled from private registries
+	if _, err := expandRKEClusterPrivateRegistriesDockerConfig(d.Get("private_registries").([]interface{}), rkeConfig); err != nil {
+		log.Warnf("[rke_provider] Unable to read private_registries docker config: %v", err)
+	}
 
 	// Omitting ClusterRemove  errors
-	_ = cmd.ClusterRemove(context.Background(), rkeConfig, hosts.DialersOptions{}, flags)
+	_ = cmd.ClusterRemove(context.Background(), rkeConfig, dialers, flags)
 
 	return nil
 }
 
-func getRKEClusterConfig(d *schema.ResourceData) (*v3.RancherKubernetesEngineConfig, string, string, string, error) {
+func getRKEClusterConfig(d *schema.ResourceData, config *Config) (*v3.RancherKubernetesEngineConfig, string, string, string, error) {
 	rkeClusterYaml, _, err := expandRKECluster(d)
 	if err != nil {
 		return nil, "", "", "", err
@@ -261,6 +566,15 @@ func getRKEClusterConfig(d *schema.ResourceData) (*v3.RancherKubernetesEngineCon
 		return nil, "", "", "", fmt.Errorf("Failed to parse cluster config: %v\n%s", err, rkeClusterYaml)
 	}
 
+	if config != nil {
+		config.SSH.applyDefaults(rkeConfig, rkeClusterSSHAgentAuthIsSet(d))
+	}
+
+	// Bastion host chains are handled by provider dialers, RKE supports just one bastion host
+	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) > 1 {
+		rkeConfig.BastionHost = v3.BastionHost{}
+	}
+
 	if rkeConfig.Services.KubeAPI.EventRateLimit != nil && rkeConfig.Services.KubeAPI.EventRateLimit.Configuration != nil {
 		if len(rkeConfig.Services.KubeAPI.EventRateLimit.Configuration.TypeMeta.Kind) == 0 {
 			rkeConfig.Services.KubeAPI.EventRateLimit.Configuration.TypeMeta.Kind = clusterServicesKubeAPIEventRateLimitConfigKindDefault
@@ -272,6 +586,12 @@ func getRKEClusterConfig(d *schema.ResourceData) (*v3.RancherKubernetesEngineCon
 
 	d.Set("rke_cluster_yaml", rkeClusterYaml)
 
+	rkeClusterYamlRedacted, err := expandRKEClusterYamlRedacted(rkeClusterYaml, d.Get("cloud_provider").([]interface{}), expandRKEClusterYamlRedactedKey(d.Get("rke_state").(string)))
+	if err != nil {
+		log.Warnf("[rke_provider] Unable to redact RKE cluster yaml: %v", err)
+	}
+	d.Set("rke_cluster_yaml_redacted", rkeClusterYamlRedacted)
+
 	clusterFilePath, tempDir, err := writeRKEConfigFiles(d)
 	if err != nil {
 		return nil, "", "", "", err
@@ -279,11 +599,20 @@ func getRKEClusterConfig(d *schema.ResourceData) (*v3.RancherKubernetesEngineCon
 	return rkeConfig, rkeClusterYaml, clusterFilePath, tempDir, err
 }
 
-func setRKEClusterState(d *schema.ResourceData, configDir string) error {
+func setRKEClusterState(d *schema.ResourceData, configDir string, secrets *rkeClusterCloudProviderSecrets, dockerConfigRegistries []string) error {
 	rkeState, err := readRKEStateFile(configDir)
 	if err != nil {
 		return err
 	}
+	// resolved cloud provider secrets and docker config credentials aren't saved to tf state
+	rkeState, err = flattenRKEClusterCloudProviderSecrets(secrets, rkeState)
+	if err != nil {
+		return err
+	}
+	rkeState, err = flattenRKEClusterPrivateRegistriesDockerConfig(dockerConfigRegistries, rkeState)
+	if err != nil {
+		return err
+	}
 	if rkeState != "" {
 		d.Set("rke_state", rkeState) // nolint
 	}
@@ -293,8 +622,15 @@ func setRKEClusterState(d *schema.ResourceData, configDir string) error {
 		return err
 	}
 	if kubeConfig != "" {
-		d.Set("kube_config_yaml", kubeConfig)          // nolint
+		// internal_kube_config_yaml keeps the RKE generated kube config, used by RKE
 		d.Set("internal_kube_config_yaml", kubeConfig) // nolint
+		if v, ok := d.Get("api_server_endpoint").(string); ok && len(v) > 0 {
+			kubeConfig, err = rkeClusterKubeConfigWithServer(kubeConfig, rkeClusterAPIServerEndpointURL(v))
+			if err != nil {
+				return err
+			}
+		}
+		d.Set("kube_config_yaml", kubeConfig) // nolint
 	}
 
 	if len(d.Id()) == 0 {
@@ -303,16 +639,20 @@ func setRKEClusterState(d *schema.ResourceData, configDir string) error {
 	return nil
 }
 
-func readClusterState(d *schema.ResourceData) (*cluster.Cluster, error) {
-	_, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d)
+func readClusterState(d *schema.ResourceData, config *Config) (*cluster.Cluster, error) {
+	_, _, clusterFilePath, tempDir, err := getRKEClusterConfig(d, config)
 	defer removeTempDir(tempDir)
 	if err != nil {
 		return nil, err
 	}
 
-	// setting up the flags
+	// setting up the flags and dialers
 	flags := expandRKEClusterFlag(d, clusterFilePath)
-	_, readedCluster, err := getClusterState(context.Background(), hosts.DialersOptions{}, flags)
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return nil, err
+	}
+	_, readedCluster, err := getClusterState(context.Background(), dialers, flags)
 	if err != nil {
 		switch err.(type) {
 		case *stateNotFoundError:
@@ -342,7 +682,7 @@ func getClusterState(ctx context.Context, dialersOptions hosts.DialersOptions, f
 		return nil, nil, err
 	}
 
-	err = kubeCluster.SetupDialers(ctx, hosts.DialersOptions{})
+	err = kubeCluster.SetupDialers(ctx, dialersOptions)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -359,6 +699,48 @@ func getClusterState(ctx context.Context, dialersOptions hosts.DialersOptions, f
 	return fullState, clusterState, nil
 }
 
+func expandRKEClusterDialers(d *schema.ResourceData, config *Config) (hosts.DialersOptions, error) {
+	in := &rkeDialersConfig{}
+	if v, ok := d.Get("ssh_agent_auth").(bool); ok {
+		in.SSHAgentAuth = v
+	}
+	if v, ok := d.Get("ssh_key_path").(string); ok {
+		in.SSHKeyPath = v
+	}
+	if config != nil && config.SSH != nil {
+		in.KnownHostsPath = config.SSH.KnownHostsPath
+		// Cluster SSH settings are defaulted as SSHConfig.applyDefaults does for nodes
+		if config.SSH.SSHAgentAuth && !rkeClusterSSHAgentAuthIsSet(d) {
+			in.SSHAgentAuth = true
+		}
+		if len(in.SSHKeyPath) == 0 {
+			in.SSHKeyPath = config.SSH.SSHKeyPath
+		}
+	}
+
+	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) > 1 {
+		in.BastionHosts = expandRKEClusterBastionHosts(v)
+	}
+
+	var err error
+	if v, ok := d.Get("default_connection").([]interface{}); ok && len(v) > 0 {
+		in.Connection, err = expandRKEClusterConnection(v)
+		if err != nil {
+			return hosts.DialersOptions{}, fmt.Errorf("Failed expanding default_connection: %v", err)
+		}
+	}
+
+	if v, ok := d.Get("nodes").([]interface{}); ok && len(v) > 0 {
+		in.HostKeys = expandRKEClusterNodesHostKeys(v)
+		in.NodeConnections, err = expandRKEClusterNodesConnections(v)
+		if err != nil {
+			return hosts.DialersOptions{}, err
+		}
+	}
+
+	return newRKEDialersOptions(in)
+}
+
 func readKubeConfig(dir string) (string, error) {
 	configPath := filepath.Join(dir, pki.ClusterConfig)
 	localKubeConfigPath := pki.GetLocalKubeConfig(configPath, "")
@@ -414,7 +796,11 @@ func writeRKEState(dir string, d *schema.ResourceData) error {
 }
 
 func writeKubeConfig(dir string, d *schema.ResourceData) error {
-	if strConf, ok := d.Get("kube_config_yaml").(string); ok && len(strConf) > 0 {
+	strConf, ok := d.Get("internal_kube_config_yaml").(string)
+	if !ok || len(strConf) == 0 {
+		strConf, ok = d.Get("kube_config_yaml").(string)
+	}
+	if ok && len(strConf) > 0 {
 		localKubeConfigPath := pki.GetLocalKubeConfig(dir, "")
 		return os.WriteFile(localKubeConfigPath, []byte(strConf), 0640)
 	}
@@ -453,6 +839,7 @@ func getChangedKeys(d *schema.ResourceDiff) map[string]bool {
 		"addon_job_timeout",
 		"addons",
 		"addons_include",
+		"api_server_endpoint",
 		"authentication",
 		"authorization",
 		"bastion_host",
@@ -469,6 +856,7 @@ func getChangedKeys(d *schema.ResourceDiff) map[string]bool {
 		"private_registries",
 		"restore",
 		"rotate_certificates",
+		"rotate_encryption_key",
 		"services",
 		"ssh_agent_auth",
 		"ssh_cert_path",
@@ -533,6 +921,122 @@ func getChangedKeys(d *schema.ResourceDiff) map[string]bool {
 	return changedKeys
 }
 
+// rkeClusterConfigIsSet returns true if key is set on raw config, instead of holding a default or a state value
+func rkeClusterConfigIsSet(raw cty.Value, key string) bool {
+	if raw.IsNull() || !raw.IsKnown() || !raw.Type().IsObjectType() || !raw.Type().HasAttribute(key) {
+		return false
+	}
+	return !raw.GetAttr(key).IsNull()
+}
+
+// rkeClusterRawConfigBlock returns the first element of nested blocks at path on raw config, or a null value if any of them isn't set
+func rkeClusterRawConfigBlock(raw cty.Value, path ...string) cty.Value {
+	for _, key := range path {
+		if !rkeClusterConfigIsSet(raw, key) {
+			return cty.NullVal(cty.DynamicPseudoType)
+		}
+		raw = raw.GetAttr(key)
+		if !raw.IsKnown() || !raw.CanIterateElements() || raw.LengthInt() == 0 {
+			return cty.NullVal(cty.DynamicPseudoType)
+		}
+		raw = raw.Index(cty.NumberIntVal(0))
+	}
+	return raw
+}
+
+// rkeClusterRawConfigMapKeys returns the known keys of the key map on raw config
+func rkeClusterRawConfigMapKeys(raw cty.Value, key string) []string {
+	if !rkeClusterConfigIsSet(raw, key) {
+		return nil
+	}
+	raw = raw.GetAttr(key)
+	if !raw.IsKnown() || !raw.CanIterateElements() {
+		return nil
+	}
+	keys := []string{}
+	for it := raw.ElementIterator(); it.Next(); {
+		k, _ := it.Element()
+		if k.IsKnown() && k.Type() == cty.String {
+			keys = append(keys, k.AsString())
+		}
+	}
+	return keys
+}
+
+// rkeClusterSSHAgentAuthIsSet returns true if ssh_agent_auth is set on config or on cluster_yaml. Without config, e.g. on destroy,
+// ssh_agent_auth state value is the one used by RKE
+func rkeClusterSSHAgentAuthIsSet(d *schema.ResourceData) bool {
+	if d.GetRawConfig().IsNull() || rkeClusterConfigIsSet(d.GetRawConfig(), "ssh_agent_auth") {
+		return true
+	}
+	if v, ok := d.Get("cluster_yaml").(string); ok && len(v) > 0 {
+		clusterYaml, err := ghodssyamlToMapInterface(v)
+		if err != nil {
+			return false
+		}
+		_, ok := clusterYaml["ssh_agent_auth"]
+		return ok
+	}
+	return false
+}
+
+// rkeClusterConfigWhollyKnown returns true if the key config value doesn't contain unknown values at plan time
+func rkeClusterConfigWhollyKnown(d *schema.ResourceDiff, key string) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return false
+	}
+	return raw.GetAttr(key).IsWhollyKnown()
+}
+
+// expandRKEClusterCIDRsConfig returns the RKE config arguments needed to validate cluster CIDRs,
+// or nil if any of them is unknown at plan time
+func expandRKEClusterCIDRsConfig(d *schema.ResourceDiff) (*v3.RancherKubernetesEngineConfig, error) {
+	for _, key := range []string{
+		"cluster_yaml",
+		"network.0.plugin",
+		"services.0.kube_api.0.service_cluster_ip_range",
+		"services.0.kube_controller.0.service_cluster_ip_range",
+		"services.0.kube_controller.0.cluster_cidr",
+		"services.0.kubelet.0.cluster_dns_server",
+	} {
+		if !d.NewValueKnown(key) {
+			return nil, nil
+		}
+	}
+
+	obj := &v3.RancherKubernetesEngineConfig{}
+	if v, ok := d.Get("cluster_yaml").(string); ok && len(v) > 0 {
+		var err error
+		obj, err = cluster.ParseConfig(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := d.Get("nodes").([]interface{}); ok && len(v) > 0 {
+		obj.Nodes = expandRKEClusterNodes(v)
+	}
+
+	if v, ok := d.Get("network").([]interface{}); ok && len(v) > 0 {
+		network, err := expandRKEClusterNetwork(v)
+		if err != nil {
+			return nil, err
+		}
+		obj.Network = network
+	}
+
+	if v, ok := d.Get("services").([]interface{}); ok && len(v) > 0 {
+		services, err := expandRKEClusterServices(v)
+		if err != nil {
+			return nil, err
+		}
+		obj.Services = services
+	}
+
+	return obj, nil
+}
+
 type stateNotFoundError struct {
 	actual error
 }
@@ -0,0 +1,270 @@
+package rke
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/pki"
+	rancher "github.com/rancher/rke/types"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	rkeClusterFilesChangedDetail = "It's kept, so rke cli changes aren't lost. Refresh rke_cluster to read them back, or remove the file to write it again"
+)
+
+func resourceRKEClusterFiles() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRKEClusterFilesCreate,
+		ReadContext:   resourceRKEClusterFilesRead,
+		UpdateContext: resourceRKEClusterFilesUpdate,
+		DeleteContext: resourceRKEClusterFilesDelete,
+		Schema:        rkeClusterFilesFields(),
+	}
+}
+
+func resourceRKEClusterFilesCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	dir, err := filepath.Abs(d.Get("directory").(string))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("Failed getting directory path: %v", err))
+	}
+	log.Infof("Writing RKE cluster files to %s", dir)
+
+	if err := os.MkdirAll(dir, rkeClusterFilesDirPermission); err != nil {
+		return diag.FromErr(fmt.Errorf("Failed creating directory %s: %v", dir, err))
+	}
+
+	d.SetId(filepath.Join(dir, pki.ClusterConfig))
+	if err := writeRKEClusterFiles(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceRKEClusterFilesRead(ctx, d, meta)
+}
+
+func resourceRKEClusterFilesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	checksums := d.Get("file_checksums").(map[string]interface{})
+	for key, path := range getRKEClusterFilesPaths(d.Id()) {
+		content := d.Get(key).(string)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			if len(content) > 0 {
+				log.Infof("RKE cluster file %s not found, recreating", path)
+				d.SetId("")
+				return nil
+			}
+			continue
+		}
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Failed reading RKE cluster file %s: %v", path, err))
+		}
+		// Files changed outside terraform, like cluster.rkestate after rke up, are kept and never overwritten
+		if rkeClusterFileChanged(data, checksums[key], content) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("RKE cluster file %s changed outside terraform", path),
+				Detail:   rkeClusterFilesChangedDetail,
+			})
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Failed reading RKE cluster file %s: %v", path, err))
+		}
+		if info.Mode().Perm() != rkeClusterFilesFilePermission {
+			log.Infof("RKE cluster file %s permissions changed to %s, recreating", path, info.Mode().Perm())
+			d.SetId("")
+			return nil
+		}
+	}
+
+	clusterFilePath := d.Id()
+	d.Set("cluster_file_path", clusterFilePath)                             // nolint
+	d.Set("state_file_path", cluster.GetStateFilePath(clusterFilePath, "")) // nolint
+	d.Set("kube_config_path", pki.GetLocalKubeConfig(clusterFilePath, ""))  // nolint
+
+	return diags
+}
+
+func resourceRKEClusterFilesUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Infof("Updating RKE cluster files at %s", filepath.Dir(d.Id()))
+
+	if err := writeRKEClusterFiles(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceRKEClusterFilesRead(ctx, d, meta)
+}
+
+func resourceRKEClusterFilesDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Infof("Removing RKE cluster files from %s", filepath.Dir(d.Id()))
+
+	var diags diag.Diagnostics
+	checksums := d.Get("file_checksums").(map[string]interface{})
+	for key, path := range getRKEClusterFilesPaths(d.Id()) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("Failed reading RKE cluster file %s: %v", path, err))
+		}
+		if rkeClusterFileChanged(data, checksums[key], d.Get(key).(string)) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("RKE cluster file %s changed outside terraform, keeping it", path),
+			})
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return diag.FromErr(fmt.Errorf("Failed removing RKE cluster file %s: %v", path, err))
+		}
+	}
+
+	d.SetId("")
+	return diags
+}
+
+// getRKEClusterFilesPaths returns the RKE cli file path of each argument, relative to the cluster config file path
+func getRKEClusterFilesPaths(clusterFilePath string) map[string]string {
+	return map[string]string{
+		"rke_cluster_yaml": clusterFilePath,
+		"rke_state":        cluster.GetStateFilePath(clusterFilePath, ""),
+		"kube_config_yaml": pki.GetLocalKubeConfig(clusterFilePath, ""),
+	}
+}
+
+// rkeClusterFileChanged returns true if data isn't the last file written by the provider. Content is compared if no checksum was saved
+func rkeClusterFileChanged(data []byte, checksum interface{}, content string) bool {
+	if v, ok := checksum.(string); ok && len(v) > 0 {
+		return getRKEClusterFileChecksum(string(data)) != v
+	}
+	return string(data) != content
+}
+
+func getRKEClusterFileChecksum(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+func writeRKEClusterFiles(d *schema.ResourceData) error {
+	paths := getRKEClusterFilesPaths(d.Id())
+	oldChecksums := d.Get("file_checksums").(map[string]interface{})
+
+	// All files are checked before writing any, so an update never leaves cli files half updated
+	contents := map[string]string{}
+	for key, path := range paths {
+		content, err := expandRKEClusterFilesContent(key, d.Get(key).(string))
+		if err != nil {
+			return fmt.Errorf("Failed writing RKE cluster file %s: %v", path, err)
+		}
+		contents[key] = content
+
+		// Files changed outside terraform are overwritten only if they already match
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Failed reading RKE cluster file %s: %v", path, err)
+		}
+		if err == nil && string(data) != content && rkeClusterFileChanged(data, oldChecksums[key], "") {
+			return fmt.Errorf("Failed writing RKE cluster file %s: changed outside terraform. %s", path, rkeClusterFilesChangedDetail)
+		}
+	}
+
+	checksums := map[string]interface{}{}
+	for key, path := range paths {
+		content := contents[key]
+		if len(content) == 0 {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("Failed removing RKE cluster file %s: %v", path, err)
+			}
+			continue
+		}
+		if err := writeRKEClusterFile(path, content); err != nil {
+			return fmt.Errorf("Failed writing RKE cluster file %s: %v", path, err)
+		}
+		checksums[key] = getRKEClusterFileChecksum(content)
+	}
+	return d.Set("file_checksums", checksums)
+}
+
+// writeRKEClusterFile writes content to a temp file renamed to path, so path is never partially written or readable by others
+func writeRKEClusterFile(path, content string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(rkeClusterFilesFilePermission); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// expandRKEClusterFilesContent resolves cloud provider env:// and file:// secret references on RKE cluster yaml and state,
+// so the rke cli gets the same secrets as the provider. References that can't be resolved, like external cloud provider addons, are an error
+func expandRKEClusterFilesContent(key, content string) (string, error) {
+	if key == "kube_config_yaml" || !rkeClusterFilesHasSecretReference(content) {
+		return content, nil
+	}
+
+	var err error
+	switch key {
+	case "rke_cluster_yaml":
+		config, parseErr := cluster.ParseConfig(content)
+		if parseErr != nil {
+			return "", fmt.Errorf("parsing RKE cluster yaml: %v", parseErr)
+		}
+		if err = mapRKEClusterCloudProviderSecrets(&config.CloudProvider, resolveRKEClusterCloudProviderSecret); err != nil {
+			return "", err
+		}
+		content, err = patchRKEClusterYaml(config)
+	case "rke_state":
+		state := &cluster.FullState{}
+		if err = json.Unmarshal([]byte(content), state); err != nil {
+			return "", fmt.Errorf("parsing RKE state: %v", err)
+		}
+		for _, config := range []*rancher.RancherKubernetesEngineConfig{state.DesiredState.RancherKubernetesEngineConfig, state.CurrentState.RancherKubernetesEngineConfig} {
+			if config == nil {
+				continue
+			}
+			if err = mapRKEClusterCloudProviderSecrets(&config.CloudProvider, resolveRKEClusterCloudProviderSecret); err != nil {
+				return "", err
+			}
+		}
+		var out []byte
+		out, err = json.MarshalIndent(state, "", "  ")
+		content = string(out)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if rkeClusterFilesHasSecretReference(content) {
+		return "", fmt.Errorf("secret references can't be resolved outside cloud provider arguments, like on external_cloud_provider addons")
+	}
+	return content, nil
+}
+
+func rkeClusterFilesHasSecretReference(content string) bool {
+	return strings.Contains(content, rkeClusterCloudProviderSecretEnvPrefix) || strings.Contains(content, rkeClusterCloudProviderSecretFilePrefix)
+}
@@ -0,0 +1,148 @@
+package rke
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rancher/rke/cluster"
+	rancher "github.com/rancher/rke/types"
+)
+
+func TestResourceRKEClusterFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cluster")
+	d := schema.TestResourceDataRaw(t, rkeClusterFilesFields(), map[string]interface{}{
+		"directory":        dir,
+		"rke_cluster_yaml": "nodes: []\n",
+		"rke_state":        "{}",
+		"kube_config_yaml": "apiVersion: v1\n",
+	})
+
+	if diags := resourceRKEClusterFilesCreate(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("[ERROR] on create: %#v", diags)
+	}
+
+	expectedFiles := map[string]string{
+		"cluster.yml":             "nodes: []\n",
+		"cluster.rkestate":        "{}",
+		"kube_config_cluster.yml": "apiVersion: v1\n",
+	}
+	for name, expectedContent := range expectedFiles {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("[ERROR] on create, file %s: %#v", name, err)
+		}
+		if info.Mode().Perm() != rkeClusterFilesFilePermission {
+			t.Fatalf("Unexpected file %s permissions.\nExpected: %s\nGiven:    %s", name, rkeClusterFilesFilePermission, info.Mode().Perm())
+		}
+		content, _ := os.ReadFile(path)
+		if string(content) != expectedContent {
+			t.Fatalf("Unexpected file %s content.\nExpected: %#v\nGiven:    %#v", name, expectedContent, string(content))
+		}
+	}
+	if info, _ := os.Stat(dir); info.Mode().Perm() != rkeClusterFilesDirPermission {
+		t.Fatalf("Unexpected directory permissions.\nExpected: %s\nGiven:    %s", rkeClusterFilesDirPermission, info.Mode().Perm())
+	}
+	if d.Get("kube_config_path").(string) != filepath.Join(dir, "kube_config_cluster.yml") {
+		t.Fatalf("Unexpected kube_config_path: %s", d.Get("kube_config_path").(string))
+	}
+
+	// Files changed outside terraform, like by rke up, are kept and not overwritten
+	statePath := filepath.Join(dir, "cluster.rkestate")
+	if err := os.WriteFile(statePath, []byte("changed"), rkeClusterFilesFilePermission); err != nil {
+		t.Fatalf("[ERROR] changing state file: %#v", err)
+	}
+	diags := resourceRKEClusterFilesRead(context.Background(), d, nil)
+	if diags.HasError() || len(diags) != 1 {
+		t.Fatalf("Unexpected diagnostics on read, changed file should be warned: %#v", diags)
+	}
+	if d.Get("rke_state").(string) != "{}" {
+		t.Fatalf("Unexpected rke_state after read: %#v", d.Get("rke_state").(string))
+	}
+	d.Set("rke_cluster_yaml", "nodes: [{}]\n")
+	if err := writeRKEClusterFiles(d); err == nil {
+		t.Fatalf("Expected error writing over changed state file")
+	}
+	if content, _ := os.ReadFile(statePath); string(content) != "changed" {
+		t.Fatalf("Unexpected state file content after failed write: %#v", string(content))
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "cluster.yml")); string(content) != "nodes: []\n" {
+		t.Fatalf("Unexpected config file content after failed write: %#v", string(content))
+	}
+	// Once state is read back, files are written again
+	d.Set("rke_cluster_yaml", "nodes: []\n")
+	d.Set("rke_state", "changed")
+	if err := writeRKEClusterFiles(d); err != nil {
+		t.Fatalf("[ERROR] writing files matching changed state file: %#v", err)
+	}
+	if diags := resourceRKEClusterFilesRead(context.Background(), d, nil); len(diags) > 0 {
+		t.Fatalf("Unexpected diagnostics on read: %#v", diags)
+	}
+
+	// Files with loose permissions are recreated
+	if err := os.Chmod(filepath.Join(dir, "cluster.yml"), 0644); err != nil {
+		t.Fatalf("[ERROR] changing config file permissions: %#v", err)
+	}
+	if diags := resourceRKEClusterFilesRead(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("[ERROR] on read: %#v", diags)
+	}
+	if len(d.Id()) > 0 {
+		t.Fatalf("Unexpected id after read, file with loose permissions should be recreated: %s", d.Id())
+	}
+
+	d.SetId(filepath.Join(dir, "cluster.yml"))
+	if diags := resourceRKEClusterFilesDelete(context.Background(), d, nil); diags.HasError() {
+		t.Fatalf("[ERROR] on delete: %#v", diags)
+	}
+	for name := range expectedFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Fatalf("Unexpected file %s after delete: %v", name, err)
+		}
+	}
+}
+
+func TestExpandRKEClusterFilesContent(t *testing.T) {
+	t.Setenv("TEST_RKE_AZURE_CLIENT_SECRET", "client_secret")
+	config := &rancher.RancherKubernetesEngineConfig{
+		CloudProvider: rancher.CloudProvider{
+			Name: "azure",
+			AzureCloudProvider: &rancher.AzureCloudProvider{
+				AADClientID:     "client_id",
+				AADClientSecret: "env://TEST_RKE_AZURE_CLIENT_SECRET",
+			},
+		},
+	}
+	clusterYaml, err := patchRKEClusterYaml(config)
+	if err != nil {
+		t.Fatalf("[ERROR] on patchRKEClusterYaml: %#v", err)
+	}
+	state, err := json.MarshalIndent(&cluster.FullState{CurrentState: cluster.State{RancherKubernetesEngineConfig: config}}, "", "  ")
+	if err != nil {
+		t.Fatalf("[ERROR] on state: %#v", err)
+	}
+
+	for key, content := range map[string]string{"rke_cluster_yaml": clusterYaml, "rke_state": string(state)} {
+		output, err := expandRKEClusterFilesContent(key, content)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander %s: %#v", key, err)
+		}
+		if strings.Contains(output, "env://") || !strings.Contains(output, "client_secret") || !strings.Contains(output, "client_id") {
+			t.Fatalf("Unexpected output from expander %s, secret reference isn't resolved:\n%s", key, output)
+		}
+	}
+
+	if output, err := expandRKEClusterFilesContent("rke_cluster_yaml", "nodes: []\n"); err != nil || output != "nodes: []\n" {
+		t.Fatalf("Unexpected output from expander without secret references: %#v %v", output, err)
+	}
+	if _, err := expandRKEClusterFilesContent("rke_cluster_yaml", strings.ReplaceAll(clusterYaml, "TEST_RKE_AZURE_CLIENT_SECRET", "TEST_RKE_UNSET")); err == nil {
+		t.Fatalf("Expected error on unresolved secret reference")
+	}
+	if _, err := expandRKEClusterFilesContent("rke_cluster_yaml", "addons: |-\n  password: env://TEST_RKE_AZURE_CLIENT_SECRET\n"); err == nil {
+		t.Fatalf("Expected error on secret reference on addons")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/rancher/rke/cluster"
@@ -351,3 +352,62 @@ resource rke_cluster "cluster" {
 }
 `, testAccRKEClusterNodes[0], testAccRKEClusterNodes[1])
 }
+
+func TestRKEClusterRawConfigBlock(t *testing.T) {
+	kubelet := cty.ObjectVal(map[string]cty.Value{
+		"cluster_dns_server": cty.NullVal(cty.String),
+		"fail_swap_on":       cty.False,
+	})
+	raw := cty.ObjectVal(map[string]cty.Value{
+		"services": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"kubelet": cty.ListVal([]cty.Value{kubelet}),
+			}),
+		}),
+	})
+
+	cases := []struct {
+		Path           []string
+		Field          string
+		ExpectedOutput bool
+	}{
+		{[]string{"services", "kubelet"}, "fail_swap_on", true},
+		{[]string{"services", "kubelet"}, "cluster_dns_server", false},
+		{[]string{"services", "kube_api"}, "fail_swap_on", false},
+		{[]string{"network"}, "plugin", false},
+	}
+
+	for _, tc := range cases {
+		output := rkeClusterConfigIsSet(rkeClusterRawConfigBlock(raw, tc.Path...), tc.Field)
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output on input %#v\nExpected: %#v\nGiven:    %#v", tc.Path, tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestResourceRKEClusterUpdateRotateEncryptionKeyFailed(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"id":                    "test",
+			"rotate_encryption_key": "v1",
+		},
+	}
+	diff := &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"cluster_yaml":          {Old: "", New: "nodes: ["},
+			"rotate_encryption_key": {Old: "v1", New: "v2"},
+		},
+	}
+
+	newState, diags := resourceRKECluster().Apply(context.Background(), state, diff, &Config{})
+	if !diags.HasError() {
+		t.Fatalf("[ERROR] update with invalid cluster_yaml should fail")
+	}
+	if v := newState.Attributes["rotate_encryption_key"]; v != "v1" {
+		t.Fatalf("Unexpected rotate_encryption_key after failed update\nExpected: %q\nGiven:    %q", "v1", v)
+	}
+	if v := newState.Attributes["encryption_key_rotated_at"]; len(v) > 0 {
+		t.Fatalf("Unexpected encryption_key_rotated_at after failed update: %q", v)
+	}
+}
@@ -11,6 +11,11 @@ import (
 	"github.com/rancher/rke/metadata"
 )
 
+const (
+	rkeClusterAddonsSeparator    = "\n---\n"
+	rkeClusterYamlRedactedPrefix = "REDACTED:hmac-sha256:"
+)
+
 //Schemas
 
 func rkeClusterFields() map[string]*schema.Schema {
@@ -37,6 +42,7 @@ func rkeClusterFields() map[string]*schema.Schema {
 				}
 				return
 			},
+			DiffSuppressFunc: suppressYamlDiff,
 		},
 		"custom_certs": {
 			Type:        schema.TypeBool,
@@ -84,6 +90,17 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Type:        schema.TypeString,
 			Optional:    true,
 			Description: "RKE k8s cluster user addons YAML manifest to be deployed",
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v, ok := val.(string)
+				if !ok || len(v) == 0 {
+					return
+				}
+				if _, err := yamlDocumentsToInterface(v); err != nil {
+					errs = append(errs, fmt.Errorf("%q must be in yaml format, error: %v", key, err))
+				}
+				return
+			},
+			DiffSuppressFunc: suppressYamlDiff,
 		},
 		"addons_include": {
 			Type:        schema.TypeList,
@@ -93,6 +110,12 @@ func rkeClusterFields() map[string]*schema.Schema {
 				Type: schema.TypeString,
 			},
 		},
+		"api_server_endpoint": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "RKE k8s cluster api server endpoint, like a load balancer DNS name or VIP, as host or host:port. Added to authentication sans and used for api_server_url and kube_config_yaml",
+			ValidateFunc: validateRKEClusterAPIServerEndpoint,
+		},
 		"authentication": {
 			Type:        schema.TypeList,
 			MaxItems:    1,
@@ -114,8 +137,7 @@ func rkeClusterFields() map[string]*schema.Schema {
 		"bastion_host": {
 			Type:        schema.TypeList,
 			Optional:    true,
-			MaxItems:    1,
-			Description: "RKE k8s cluster bastion Host configuration",
+			Description: "RKE k8s cluster bastion Host configuration. Multiple bastion hosts are used as an ordered SSH chain",
 			Elem: &schema.Resource{
 				Schema: rkeClusterBastionHostFields(),
 			},
@@ -134,6 +156,15 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "RKE k8s cluster name used in the kube config",
 		},
+		"default_connection": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "RKE k8s cluster default connection to nodes",
+			Elem: &schema.Resource{
+				Schema: rkeClusterConnectionFields(),
+			},
+		},
 		"dns": {
 			Type:        schema.TypeList,
 			MaxItems:    1,
@@ -230,6 +261,11 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "RKE k8s directory path",
 		},
+		"win_prefix_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "RKE k8s directory path for windows nodes",
+		},
 		"private_registries": {
 			Type:        schema.TypeList,
 			Optional:    true,
@@ -256,6 +292,11 @@ func rkeClusterFields() map[string]*schema.Schema {
 				Schema: rkeClusterRotateCertificatesFields(),
 			},
 		},
+		"rotate_encryption_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "RKE k8s cluster secrets encryption key rotation trigger. Changing its value, e.g. to a version or timestamp, rotates the key",
+		},
 		"services": {
 			Type:        schema.TypeList,
 			MaxItems:    1,
@@ -359,6 +400,15 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Default:     false,
 			Description: "Skip idempotent deployment of control and etcd plane",
 		},
+		"wait_for": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "RKE k8s cluster readiness gates to wait for after apply",
+			Elem: &schema.Resource{
+				Schema: rkeClusterWaitForFields(),
+			},
+		},
 		// Computed fields
 		"ca_crt": {
 			Type:        schema.TypeString,
@@ -402,6 +452,11 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Sensitive:   true,
 			Description: "RKE k8s cluster config yaml",
 		},
+		"rke_cluster_yaml_redacted": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster config yaml with sensitive values replaced by an HMAC-SHA256 placeholder keyed by the cluster CA",
+		},
 		"certificates": {
 			Type:        schema.TypeList,
 			Computed:    true,
@@ -421,6 +476,19 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "RKE k8s cluster api server url",
 		},
+		"api_server_urls": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Description: "RKE k8s cluster control plane nodes api server urls",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"rendered_cloud_config": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster cloud provider config file, with secrets redacted",
+		},
 		"cluster_domain": {
 			Type:        schema.TypeString,
 			Computed:    true,
@@ -431,11 +499,46 @@ func rkeClusterFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "RKE k8s cluster cidr",
 		},
+		"cluster_cidr_ipv4": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster IPv4 cidr",
+		},
+		"cluster_cidr_ipv6": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster IPv6 cidr",
+		},
 		"cluster_dns_server": {
 			Type:        schema.TypeString,
 			Computed:    true,
 			Description: "RKE k8s cluster dns server",
 		},
+		"dual_stack": {
+			Type:        schema.TypeBool,
+			Computed:    true,
+			Description: "RKE k8s cluster is IPv4/IPv6 dual-stack",
+		},
+		"service_cluster_ip_range_ipv4": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster IPv4 service cluster ip range",
+		},
+		"service_cluster_ip_range_ipv6": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster IPv6 service cluster ip range",
+		},
+		"encryption_key_name": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster active secrets encryption key name",
+		},
+		"encryption_key_rotated_at": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster last secrets encryption key rotation time, in RFC3339 format",
+		},
 		"control_plane_hosts": {
 			Type:        schema.TypeList,
 			Computed:    true,
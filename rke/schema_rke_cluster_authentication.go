@@ -18,9 +18,11 @@ var (
 func rkeClusterAuthenticationWebhookFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"config_file": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Description: "Multiline string that represent a custom webhook config file",
+			Type:             schema.TypeString,
+			Optional:         true,
+			Sensitive:        true,
+			Description:      "Multiline string that represent a custom webhook config file",
+			DiffSuppressFunc: suppressYamlDiff,
 		},
 		"cache_timeout": {
 			Type:        schema.TypeString,
@@ -76,6 +76,15 @@ func rkeClusterCloudProviderFields() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Custom Cloud Provider config",
 		},
+		"external_cloud_provider": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "External Cloud Provider cloud controller manager deployment, requires name external",
+			Elem: &schema.Resource{
+				Schema: rkeClusterCloudProviderExternalFields(),
+			},
+		},
 		"openstack_cloud_config": {
 			Type:       schema.TypeList,
 			MaxItems:   1,
@@ -24,15 +24,16 @@ func rkeClusterCloudProviderAzureFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"aad_client_id": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
 			Sensitive:   true,
-			Description: "The ClientID for an AAD application with RBAC access to talk to Azure RM APIs",
+			Description: "The ClientID for an AAD application with RBAC access to talk to Azure RM APIs. Required if use_managed_identity_extension is false",
 		},
 		"aad_client_secret": {
-			Type:        schema.TypeString,
-			Required:    true,
-			Sensitive:   true,
-			Description: "The ClientSecret for an AAD application with RBAC access to talk to Azure RM APIs",
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "The ClientSecret for an AAD application with RBAC access to talk to Azure RM APIs. Required if use_managed_identity_extension is false and aad_client_cert_path isn't set. Accepts env://<VAR> and file://<path> references, resolved at apply time",
+			ValidateFunc: validateRKEClusterCloudProviderSecret,
 		},
 		"subscription_id": {
 			Type:        schema.TypeString,
@@ -47,10 +48,11 @@ func rkeClusterCloudProviderAzureFields() map[string]*schema.Schema {
 			Description: "The AAD Tenant ID for the Subscription that the cluster is deployed in",
 		},
 		"aad_client_cert_password": {
-			Type:        schema.TypeString,
-			Optional:    true,
-			Sensitive:   true,
-			Description: "The password of the client certificate for an AAD application with RBAC access to talk to Azure RM APIs",
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "The password of the client certificate for an AAD application with RBAC access to talk to Azure RM APIs. Accepts env://<VAR> and file://<path> references, resolved at apply time",
+			ValidateFunc: validateRKEClusterCloudProviderSecret,
 		},
 		"aad_client_cert_path": {
 			Type:        schema.TypeString,
@@ -0,0 +1,51 @@
+package rke
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	rkeClusterCloudProviderExternalNamespace      = "kube-system"
+	rkeClusterCloudProviderExternalResourceName   = "cloud-controller-manager"
+	rkeClusterCloudProviderExternalSecretName     = "cloud-controller-manager-config"
+	rkeClusterCloudProviderExternalSecretKey      = "cloud-config"
+	rkeClusterCloudProviderExternalConfigPath     = "/etc/kubernetes/cloud-controller-manager"
+	rkeClusterCloudProviderExternalAwsImage       = "registry.k8s.io/provider-aws/cloud-controller-manager:v1.31.1"
+	rkeClusterCloudProviderExternalAzureImage     = "mcr.microsoft.com/oss/kubernetes/azure-cloud-controller-manager:v1.31.1"
+	rkeClusterCloudProviderExternalOpenstackImage = "registry.k8s.io/provider-os/openstack-cloud-controller-manager:v1.31.1"
+	rkeClusterCloudProviderExternalVsphereImage   = "registry.k8s.io/cloud-pv-vsphere/cloud-provider-vsphere:v1.31.0"
+)
+
+var (
+	rkeClusterCloudProviderExternalList = []string{
+		rkeClusterCloudProviderAwsName,
+		rkeClusterCloudProviderAzureName,
+		rkeClusterCloudProviderOpenstackName,
+		rkeClusterCloudProviderVsphereName,
+	}
+)
+
+//Schemas
+
+func rkeClusterCloudProviderExternalFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "Out-of-tree cloud controller manager to deploy, credentials are taken from the matching <name>_cloud_provider block",
+			ValidateFunc: validation.StringInSlice(rkeClusterCloudProviderExternalList, false),
+		},
+		"extra_args": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "Cloud controller manager extra arguments",
+		},
+		"image": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Cloud controller manager image. Default depends on name",
+		},
+	}
+	return s
+}
@@ -36,9 +36,11 @@ func rkeClusterCloudProviderOpenstackGlobalFields() map[string]*schema.Schema {
 			Required: true,
 		},
 		"password": {
-			Type:      schema.TypeString,
-			Required:  true,
-			Sensitive: true,
+			Type:         schema.TypeString,
+			Required:     true,
+			Sensitive:    true,
+			Description:  "Accepts env://<VAR> and file://<path> references, resolved at apply time",
+			ValidateFunc: validateRKEClusterCloudProviderSecret,
 		},
 		"ca_file": {
 			Type:     schema.TypeString,
@@ -40,9 +40,11 @@ func rkeClusterCloudProviderVsphereGlobalFields() map[string]*schema.Schema {
 			Optional: true,
 		},
 		"password": {
-			Type:      schema.TypeString,
-			Optional:  true,
-			Sensitive: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "Accepts env://<VAR> and file://<path> references, resolved at apply time",
+			ValidateFunc: validateRKEClusterCloudProviderSecret,
 		},
 		"user": {
 			Type:      schema.TypeString,
@@ -87,20 +89,22 @@ func rkeClusterCloudProviderVsphereVirtualCenterFields() map[string]*schema.Sche
 	s := map[string]*schema.Schema{
 		"datacenters": {
 			Type:     schema.TypeString,
-			Required: true,
+			Optional: true,
 		},
 		"name": { // called server on original
 			Type:     schema.TypeString,
 			Required: true,
 		},
 		"password": {
-			Type:      schema.TypeString,
-			Required:  true,
-			Sensitive: true,
+			Type:         schema.TypeString,
+			Optional:     true,
+			Sensitive:    true,
+			Description:  "Accepts env://<VAR> and file://<path> references, resolved at apply time",
+			ValidateFunc: validateRKEClusterCloudProviderSecret,
 		},
 		"user": {
 			Type:      schema.TypeString,
-			Required:  true,
+			Optional:  true,
 			Sensitive: true,
 		},
 		"port": {
@@ -0,0 +1,130 @@
+package rke
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	rkeClusterConnectionTypeDocker       = "docker"
+	rkeClusterConnectionTypeProxyCommand = "proxy_command"
+	rkeClusterConnectionTypeSSH          = "ssh"
+)
+
+var (
+	rkeClusterConnectionTypes = []string{
+		rkeClusterConnectionTypeDocker,
+		rkeClusterConnectionTypeProxyCommand,
+		rkeClusterConnectionTypeSSH,
+	}
+)
+
+//Schemas
+
+func rkeClusterConnectionJumpHostFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"address": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Address of the jump host",
+		},
+		"user": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "SSH user to the jump host",
+		},
+		"host_key_fingerprint": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "SHA256 fingerprint of the jump host SSH host key, e.g. SHA256:...",
+			ValidateFunc: validation.StringMatch(rkeClusterNodeHostKeyFingerprintRegexp, "must be a SHA256 fingerprint like SHA256:<base64>"),
+		},
+		"known_hosts": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "known_hosts formatted entries used to verify the jump host SSH host key",
+		},
+		"port": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Default:     "22",
+			Description: "SSH port of the jump host",
+		},
+		"ssh_agent_auth": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "SSH Agent Auth enable",
+		},
+		"ssh_cert": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "SSH Certificate",
+		},
+		"ssh_cert_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "SSH Certificate path",
+		},
+		"ssh_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "SSH Private Key",
+		},
+		"ssh_key_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "SSH Private Key path. Default node ssh_key_path",
+		},
+	}
+	return s
+}
+
+func rkeClusterConnectionFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      rkeClusterConnectionTypeSSH,
+			Description:  "Connection type to the node [docker/proxy_command/ssh]",
+			ValidateFunc: validation.StringInSlice(rkeClusterConnectionTypes, false),
+		},
+		"docker_ca_cert": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "CA certificate to verify the docker_host TLS connection",
+		},
+		"docker_client_cert": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Client certificate for the docker_host TLS connection",
+		},
+		"docker_client_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Client key for the docker_host TLS connection",
+		},
+		"docker_host": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Docker daemon address, tcp://<host>:<port> or unix://<path>. Required for docker type",
+		},
+		"jump_host": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Ordered SSH jump hosts to reach the node, after the cluster bastion_host",
+			Elem: &schema.Resource{
+				Schema: rkeClusterConnectionJumpHostFields(),
+			},
+		},
+		"proxy_command": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Local command whose stdin/stdout is used as SSH transport, supporting %h, %p and %r tokens. Required for proxy_command type",
+		},
+	}
+	return s
+}
@@ -1,14 +1,23 @@
 package rke
 
 import (
+	"fmt"
+	"net"
+	"slices"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rancher/rke/cluster"
 )
 
 const (
 	rkeClusterDNSProviderKube = "kube-dns"
 	rkeClusterDNSProviderCore = "coredns"
 	rkeClusterDNSProviderNone = "none"
+
+	rkeClusterDNSLinearAutoscalerCoresPerReplicaDefault = 128
+	rkeClusterDNSLinearAutoscalerNodesPerReplicaDefault = 4
+	rkeClusterDNSLinearAutoscalerMinDefault             = 1
 )
 
 var (
@@ -17,15 +26,71 @@ var (
 		rkeClusterDNSProviderCore,
 		rkeClusterDNSProviderNone,
 	}
+	rkeClusterDNSOptionsList = []string{
+		cluster.CoreDNSPriorityClassNameKey,
+		cluster.CoreDNSAutoscalerPriorityClassNameKey,
+		cluster.KubeDNSPriorityClassNameKey,
+		cluster.KubeDNSAutoscalerPriorityClassNameKey,
+	}
 )
 
 //Schemas
 
+func rkeClusterDNSLinearAutoscalerParamsFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"cores_per_replica": {
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			Default:      rkeClusterDNSLinearAutoscalerCoresPerReplicaDefault,
+			Description:  "Number of cluster cores per DNS replica",
+			ValidateFunc: validation.FloatAtLeast(0),
+		},
+		"max": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Maximum number of DNS replicas. 0 means unlimited",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"min": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      rkeClusterDNSLinearAutoscalerMinDefault,
+			Description:  "Minimum number of DNS replicas",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"nodes_per_replica": {
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			Default:      rkeClusterDNSLinearAutoscalerNodesPerReplicaDefault,
+			Description:  "Number of cluster nodes per DNS replica",
+			ValidateFunc: validation.FloatAtLeast(0),
+		},
+		"prevent_single_point_failure": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Run at least 2 DNS replicas when the cluster has more than one node",
+		},
+	}
+	return s
+}
+
 func rkeClusterDNSNodelocalFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"ip_address": {
-			Type:     schema.TypeString,
-			Optional: true,
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Link-local IP address for nodelocal DNS, outside of the service cluster IP range",
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v, ok := val.(string)
+				if !ok || len(v) == 0 {
+					return
+				}
+				if ip := net.ParseIP(v); ip == nil || !ip.IsLinkLocalUnicast() {
+					errs = append(errs, fmt.Errorf("%q must be a link-local IP address, got %s", key, v))
+				}
+				return
+			},
 		},
 		"node_selector": {
 			Type:        schema.TypeMap,
@@ -38,6 +103,16 @@ func rkeClusterDNSNodelocalFields() map[string]*schema.Schema {
 
 func rkeClusterDNSFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
+		"linear_autoscaler_params": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "DNS linear autoscaler params",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDNSLinearAutoscalerParamsFields(),
+			},
+		},
 		"node_selector": {
 			Type:        schema.TypeMap,
 			Optional:    true,
@@ -52,6 +127,23 @@ func rkeClusterDNSFields() map[string]*schema.Schema {
 				Schema: rkeClusterDNSNodelocalFields(),
 			},
 		},
+		"options": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "DNS config options",
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v, ok := val.(map[string]interface{})
+				if !ok {
+					return
+				}
+				for k := range v {
+					if !slices.Contains(rkeClusterDNSOptionsList, k) {
+						warns = append(warns, fmt.Sprintf("%q unknown option %s, supported options: %v", key, k, rkeClusterDNSOptionsList))
+					}
+				}
+				return
+			},
+		},
 		"provider": {
 			Type:         schema.TypeString,
 			Optional:     true,
@@ -67,6 +159,24 @@ func rkeClusterDNSFields() map[string]*schema.Schema {
 				Type: schema.TypeString,
 			},
 		},
+		"tolerations": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "DNS tolerations",
+			Elem: &schema.Resource{
+				Schema: rkeClusterTolerationFields(),
+			},
+		},
+		"update_strategy": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "DNS update strategy",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDeploymentUpdateStrategyFields(),
+			},
+		},
 		"upstream_nameservers": {
 			Type:        schema.TypeList,
 			Optional:    true,
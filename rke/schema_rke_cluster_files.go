@@ -0,0 +1,69 @@
+package rke
+
+import (
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	rkeClusterFilesDirPermission  os.FileMode = 0700
+	rkeClusterFilesFilePermission os.FileMode = 0600
+)
+
+//Schemas
+
+func rkeClusterFilesFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"directory": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ForceNew:     true,
+			Description:  "Directory to write RKE cluster files to. It's created if it doesn't exist",
+			ValidateFunc: validation.StringIsNotEmpty,
+		},
+		"rke_cluster_yaml": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Sensitive:   true,
+			Description: "RKE k8s cluster config yaml, written to cluster.yml",
+		},
+		"rke_state": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "RKE k8s cluster state, written to cluster.rkestate",
+		},
+		"kube_config_yaml": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "RKE k8s cluster kube config yaml, written to kube_config_cluster.yml",
+		},
+		"cluster_file_path": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster config file path",
+		},
+		"state_file_path": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster state file path",
+		},
+		"kube_config_path": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "RKE k8s cluster kube config file path",
+		},
+		"file_checksums": {
+			Type:        schema.TypeMap,
+			Computed:    true,
+			Description: "Sha256 checksum of each written file, by argument name",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+	return s
+}
@@ -12,6 +12,8 @@ const (
 	rkeClusterIngressNetworkModeHostNetwork = "hostNetwork"
 	rkeClusterIngressNetworkModeHostPort    = "hostPort"
 	rkeClusterIngressNetworkModeNone        = "none"
+	rkeClusterIngressHTTPPortDefault        = 80
+	rkeClusterIngressHTTPSPortDefault       = 443
 )
 
 var (
@@ -31,10 +33,261 @@ var (
 		rkeClusterIngressNetworkModeHostPort,
 		rkeClusterIngressNetworkModeNone,
 	}
+	rkeClusterIngressHostPathTypeList = []string{
+		string(v1.HostPathUnset),
+		string(v1.HostPathDirectoryOrCreate),
+		string(v1.HostPathDirectory),
+		string(v1.HostPathFileOrCreate),
+		string(v1.HostPathFile),
+		string(v1.HostPathSocket),
+		string(v1.HostPathCharDev),
+		string(v1.HostPathBlockDev),
+	}
+	rkeClusterIngressMountPropagationList = []string{
+		string(v1.MountPropagationNone),
+		string(v1.MountPropagationHostToContainer),
+		string(v1.MountPropagationBidirectional),
+	}
 )
 
 //Schemas
 
+func rkeClusterIngressKeyRefFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"key": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Key to select",
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Name of the referent",
+		},
+		"optional": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Specify whether the referent or its key must be defined",
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraEnvValueFromFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"config_map_key_ref": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Selects a key of a configmap in the ingress namespace",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressKeyRefFields(),
+			},
+		},
+		"field_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Selects a field of the pod, like metadata.namespace",
+		},
+		"secret_key_ref": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Selects a key of a secret in the ingress namespace",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressKeyRefFields(),
+			},
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraEnvFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Env var name",
+		},
+		"value": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Env var value",
+		},
+		"value_from": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Env var value source",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraEnvValueFromFields(),
+			},
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeConfigMapFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"default_mode": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Mode bits used to set permissions on created files",
+			ValidateFunc: validation.IntBetween(0, 0777),
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Configmap name",
+		},
+		"optional": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Specify whether the configmap must be defined",
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeEmptyDirFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"medium": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Storage medium backing the directory",
+			ValidateFunc: validation.StringInSlice([]string{string(v1.StorageMediumDefault), string(v1.StorageMediumMemory)}, false),
+		},
+		"size_limit": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Total amount of local storage required, like 1Gi",
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeHostPathFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"path": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Path of the directory on the host",
+		},
+		"type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Type for the host path volume",
+			ValidateFunc: validation.StringInSlice(rkeClusterIngressHostPathTypeList, false),
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeSecretFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"default_mode": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Mode bits used to set permissions on created files",
+			ValidateFunc: validation.IntBetween(0, 0777),
+		},
+		"optional": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Specify whether the secret must be defined",
+		},
+		"secret_name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Secret name",
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"config_map": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Configmap volume source",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeConfigMapFields(),
+			},
+		},
+		"empty_dir": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Empty dir volume source",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeEmptyDirFields(),
+			},
+		},
+		"host_path": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Host path volume source",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeHostPathFields(),
+			},
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Volume name",
+		},
+		"secret": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Secret volume source",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeSecretFields(),
+			},
+		},
+	}
+	return s
+}
+
+func rkeClusterIngressExtraVolumeMountFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"mount_path": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Path within the container to mount the volume",
+		},
+		"mount_propagation": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Mount propagation mode",
+			ValidateFunc: validation.StringInSlice(rkeClusterIngressMountPropagationList, false),
+		},
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Volume name to mount",
+		},
+		"read_only": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Mount the volume read only",
+		},
+		"sub_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Path within the volume to mount",
+		},
+	}
+	return s
+}
+
 func rkeClusterIngressFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"dns_policy": {
@@ -43,27 +296,72 @@ func rkeClusterIngressFields() map[string]*schema.Schema {
 			ValidateFunc: validation.StringInSlice(rkeClusterIngressDNSPolicyList, true),
 			Description:  "Ingress controller dns policy",
 		},
+		"default_http_backend_priority_class_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Priority class name for the default http backend deployment",
+		},
+		"default_ingress_class": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Set nginx as the default ingress class",
+		},
 		"extra_args": {
 			Type:        schema.TypeMap,
 			Optional:    true,
 			Description: "Extra arguments for the ingress controller",
 		},
-		"http_port": {
-			Type:        schema.TypeInt,
+		"extra_envs": {
+			Type:        schema.TypeList,
 			Optional:    true,
-			Description: "Ingress controller http port",
+			Description: "Extra env vars for the ingress controller",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraEnvFields(),
+			},
 		},
-		"https_port": {
-			Type:        schema.TypeInt,
+		"extra_volume_mounts": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Extra volume mounts for the ingress controller",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeMountFields(),
+			},
+		},
+		"extra_volumes": {
+			Type:        schema.TypeList,
 			Optional:    true,
-			Description: "Ingress controller https port",
+			Description: "Extra volumes for the ingress controller",
+			Elem: &schema.Resource{
+				Schema: rkeClusterIngressExtraVolumeFields(),
+			},
+		},
+		"http_port": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Ingress controller http port",
+			ValidateFunc: validation.IntBetween(0, 65535),
+		},
+		"https_port": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Ingress controller https port",
+			ValidateFunc: validation.IntBetween(0, 65535),
 		},
 		"network_mode": {
 			Type:         schema.TypeString,
 			Optional:     true,
+			Computed:     true,
 			ValidateFunc: validation.StringInSlice(rkeClusterIngressNetworkModeList, true),
 			Description:  "Ingress controller network mode",
 		},
+		"nginx_ingress_controller_priority_class_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Priority class name for the nginx ingress controller daemonset",
+		},
 		"node_selector": {
 			Type:        schema.TypeMap,
 			Optional:    true,
@@ -81,6 +379,24 @@ func rkeClusterIngressFields() map[string]*schema.Schema {
 			ValidateFunc: validation.StringInSlice(rkeClusterIngressProviderList, true),
 			Description:  "Ingress controller provider",
 		},
+		"tolerations": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Ingress controller tolerations",
+			Elem: &schema.Resource{
+				Schema: rkeClusterTolerationFields(),
+			},
+		},
+		"update_strategy": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "Ingress controller update strategy",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDaemonSetUpdateStrategyFields(),
+			},
+		},
 		"default_backend": {
 			Type:        schema.TypeBool,
 			Optional:    true,
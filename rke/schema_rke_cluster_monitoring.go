@@ -2,6 +2,7 @@ package rke
 
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 //Schemas
@@ -24,6 +25,31 @@ func rkeClusterMonitoringFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "Monitoring provider",
 		},
+		"replicas": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Monitoring deployment replicas",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"tolerations": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Monitoring tolerations",
+			Elem: &schema.Resource{
+				Schema: rkeClusterTolerationFields(),
+			},
+		},
+		"update_strategy": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "Monitoring update strategy",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDeploymentUpdateStrategyFields(),
+			},
+		},
 	}
 	return s
 }
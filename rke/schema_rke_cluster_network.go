@@ -3,6 +3,7 @@ package rke
 import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/rancher/rke/cluster"
 )
 
 const (
@@ -12,6 +13,9 @@ const (
 	rkeClusterNetworkPluginNonelName   = "none"
 	rkeClusterNetworkPluginWeaveName   = "weave"
 	rkeClusterNetworkPluginAciName     = "aci"
+
+	rkeClusterNetworkFlannelBackendHostGW = "host-gw"
+	rkeClusterNetworkFlannelBackendVxLan  = cluster.DefaultFlannelBackendVxLan
 )
 
 var (
@@ -24,10 +28,67 @@ var (
 		rkeClusterNetworkPluginWeaveName,
 		rkeClusterNetworkPluginAciName,
 	}
+	rkeClusterNetworkFlannelBackendList = []string{
+		rkeClusterNetworkFlannelBackendHostGW,
+		rkeClusterNetworkFlannelBackendVxLan,
+	}
+	// Typed provider fields, mapped to the network options keys used by RKE
+	rkeClusterNetworkCalicoOptions = map[string]string{
+		"cloud_provider":                       cluster.CalicoCloudProvider,
+		"flex_volume_plugin_dir":               cluster.CalicoFlexVolPluginDirectory,
+		"kube_controllers_priority_class_name": cluster.CalicoKubeControllersPriorityClassNameKeyName,
+		"node_priority_class_name":             cluster.CalicoNodePriorityClassNameKeyName,
+	}
+	rkeClusterNetworkCanalOptions = map[string]string{
+		"backend_port":                         cluster.CanalFlannelBackendPort,
+		"backend_type":                         cluster.CanalFlannelBackendType,
+		"backend_vni":                          cluster.CanalFlannelBackendVxLanNetworkIdentify,
+		"flex_volume_plugin_dir":               cluster.CanalFlexVolPluginDirectory,
+		"iface":                                cluster.CanalIface,
+		"kube_controllers_priority_class_name": cluster.CalicoKubeControllersPriorityClassNameKeyName,
+		"priority_class_name":                  cluster.CanalPriorityClassNameKeyName,
+	}
+	rkeClusterNetworkFlannelOptions = map[string]string{
+		"backend_port":        cluster.FlannelBackendPort,
+		"backend_type":        cluster.FlannelBackendType,
+		"backend_vni":         cluster.FlannelBackendVxLanNetworkIdentify,
+		"iface":               cluster.FlannelIface,
+		"priority_class_name": cluster.KubeFlannelPriorityClassNameKeyName,
+	}
 )
 
 //Schemas
 
+func rkeClusterNetworkFlannelBackendFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"backend_port": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Flannel vxlan backend UDP port",
+			ValidateFunc: validation.IsPortNumber,
+		},
+		"backend_type": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Flannel backend type",
+			ValidateFunc: validation.StringInSlice(rkeClusterNetworkFlannelBackendList, false),
+		},
+		"backend_vni": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Flannel vxlan backend VNI",
+			ValidateFunc: validation.IntBetween(1, 16777215),
+		},
+		"iface": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Network interface used for inter host communication",
+		},
+	}
+	return s
+}
+
 func rkeClusterNetworkCalicoFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"cloud_provider": {
@@ -35,28 +96,58 @@ func rkeClusterNetworkCalicoFields() map[string]*schema.Schema {
 			Optional: true,
 			Computed: true,
 		},
+		"flex_volume_plugin_dir": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Flexvolume plugin directory on nodes",
+		},
+		"kube_controllers_priority_class_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Priority class name for the calico-kube-controllers deployment",
+		},
+		"node_priority_class_name": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Priority class name for the calico-node daemonset",
+		},
 	}
 	return s
 }
 
 func rkeClusterNetworkCanalFields() map[string]*schema.Schema {
-	s := map[string]*schema.Schema{
-		"iface": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
-		},
+	s := rkeClusterNetworkFlannelBackendFields()
+	s["flex_volume_plugin_dir"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "Flexvolume plugin directory on nodes",
+	}
+	s["kube_controllers_priority_class_name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "Priority class name for the calico-kube-controllers deployment",
+	}
+	s["priority_class_name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "Priority class name for the canal daemonset",
 	}
 	return s
 }
 
 func rkeClusterNetworkFlannelFields() map[string]*schema.Schema {
-	s := map[string]*schema.Schema{
-		"iface": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
-		},
+	s := rkeClusterNetworkFlannelBackendFields()
+	s["priority_class_name"] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "Priority class name for the kube-flannel daemonset",
 	}
 	return s
 }
@@ -64,8 +155,9 @@ func rkeClusterNetworkFlannelFields() map[string]*schema.Schema {
 func rkeClusterNetworkWeaveFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"password": {
-			Type:     schema.TypeString,
-			Required: true,
+			Type:      schema.TypeString,
+			Required:  true,
+			Sensitive: true,
 		},
 	}
 	return s
@@ -249,6 +341,24 @@ func rkeClusterNetworkFields() map[string]*schema.Schema {
 			Description:  "Network provider plugin",
 			ValidateFunc: validation.StringInSlice(rkeClusterNetworkPluginList, true),
 		},
+		"tolerations": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Network provider tolerations",
+			Elem: &schema.Resource{
+				Schema: rkeClusterTolerationFields(),
+			},
+		},
+		"update_strategy": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "Network provider update strategy",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDaemonSetUpdateStrategyFields(),
+			},
+		},
 		"enable_br_netfilter": {
 			Type:        schema.TypeBool,
 			Optional:    true,
@@ -1,12 +1,21 @@
 package rke
 
 import (
+	"regexp"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+const (
+	rkeClusterNodeOSLinux   = "linux"
+	rkeClusterNodeOSWindows = "windows"
+)
+
 var (
-	rkeClusterNodesRoles = []string{"controlplane", "etcd", "worker"}
+	rkeClusterNodesRoles                   = []string{"controlplane", "etcd", "worker"}
+	rkeClusterNodeOSList                   = []string{rkeClusterNodeOSLinux, rkeClusterNodeOSWindows}
+	rkeClusterNodeHostKeyFingerprintRegexp = regexp.MustCompile(`^SHA256:[A-Za-z0-9+/]{43}=?$`)
 )
 
 //Schemas
@@ -36,15 +45,31 @@ func rkeClusterNodeFields() map[string]*schema.Schema {
 		},
 		"user": {
 			Type:        schema.TypeString,
-			Required:    true,
+			Optional:    true,
+			Computed:    true,
 			Sensitive:   true,
-			Description: "SSH user that will be used by RKE",
+			Description: "SSH user that will be used by RKE. Default from provider ssh config",
+		},
+		"connection": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Node connection, overriding the cluster connection",
+			Elem: &schema.Resource{
+				Schema: rkeClusterConnectionFields(),
+			},
 		},
 		"docker_socket": {
 			Type:        schema.TypeString,
 			Optional:    true,
 			Description: "Docker socket on the node that will be used in tunneling",
 		},
+		"host_key_fingerprint": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "SHA256 fingerprint of the node SSH host key, e.g. SHA256:...",
+			ValidateFunc: validation.StringMatch(rkeClusterNodeHostKeyFingerprintRegexp, "must be a SHA256 fingerprint like SHA256:<base64>"),
+		},
 		"hostname_override": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -55,6 +80,11 @@ func rkeClusterNodeFields() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Internal address that will be used for components communication",
 		},
+		"known_hosts": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "known_hosts formatted entries used to verify the node SSH host key",
+		},
 		"labels": {
 			Type:        schema.TypeMap,
 			Optional:    true,
@@ -65,6 +95,13 @@ func rkeClusterNodeFields() map[string]*schema.Schema {
 			Optional:    true,
 			Description: "Name of the host provisioned via docker machine",
 		},
+		"os": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      rkeClusterNodeOSLinux,
+			Description:  "Node operating system [linux/windows]. Windows nodes can only be workers",
+			ValidateFunc: validation.StringInSlice(rkeClusterNodeOSList, false),
+		},
 		"port": {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -6,6 +6,30 @@ import (
 
 //Schemas
 
+func rkeClusterPrivateRegistriesECRCredentialPluginFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"aws_access_key_id": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "AWS access key ID. Node instance profile is used if not set",
+		},
+		"aws_secret_access_key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "AWS secret access key",
+		},
+		"aws_session_token": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "AWS session token",
+		},
+	}
+	return s
+}
+
 func rkeClusterPrivateRegistriesFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"url": {
@@ -13,6 +37,20 @@ func rkeClusterPrivateRegistriesFields() map[string]*schema.Schema {
 			Required:    true,
 			Description: "Registry URL",
 		},
+		"docker_config_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Docker config.json file path to read the registry credentials from, at apply time",
+		},
+		"ecr_credential_plugin": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "ECR credential plugin config",
+			Elem: &schema.Resource{
+				Schema: rkeClusterPrivateRegistriesECRCredentialPluginFields(),
+			},
+		},
 		"is_default": {
 			Type:        schema.TypeBool,
 			Optional:    true,
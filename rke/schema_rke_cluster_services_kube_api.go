@@ -3,23 +3,45 @@ package rke
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	apiserverconfig "k8s.io/apiserver/pkg/apis/apiserver/v1"
-	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
 	eventratelimitapi "k8s.io/kubernetes/plugin/pkg/admission/eventratelimit/apis/eventratelimit"
 )
 
 const (
 	clusterServicesKubeAPIApiVersionTag                      = "apiVersion"
 	clusterServicesKubeAPIKindTag                            = "kind"
+	clusterServicesKubeAPIAdmissionConfigurationAPIDefault   = "apiserver.config.k8s.io/v1"
 	clusterServicesKubeAPIAuditLogConfigPolicyAPIDefault     = "audit.k8s.io/v1"
 	clusterServicesKubeAPIEventRateLimitConfigAPIDefault     = "eventratelimit.admission.k8s.io/v1alpha1"
 	clusterServicesKubeAPISecretsEncryptionConfigAPIDefault  = "apiserver.config.k8s.io/v1"
+	clusterServicesKubeAPIAdmissionConfigurationKindDefault  = "AdmissionConfiguration"
 	clusterServicesKubeAPIAuditLogConfigPolicyKindDefault    = "Policy"
 	clusterServicesKubeAPIEventRateLimitConfigKindDefault    = "Configuration"
 	clusterServicesKubeAPISecretsEncryptionConfigKindDefault = "EncryptionConfiguration"
+	clusterServicesKubeAPISecretsEncryptionKMSAPIVersionV1   = "v1"
+	clusterServicesKubeAPISecretsEncryptionKMSAPIVersionV2   = "v2"
+	clusterServicesKubeAPISecretsEncryptionKMSTimeoutDefault = "3s"
+	clusterServicesKubeAPIAuditLogWebhookModeBatch           = "batch"
+	clusterServicesKubeAPIAuditLogWebhookModeBlocking        = "blocking"
+	clusterServicesKubeAPIAuditLogWebhookModeBlockingStrict  = "blocking-strict"
+	clusterServicesKubeAPIAuditLogWebhookConfigDirDefault    = "/etc/kubernetes"
+	clusterServicesKubeAPIAdmissionConfigurationArg          = "admission-control-config-file"
+	clusterServicesKubeAPIAdmissionConfigurationFile         = "admission.yaml"
+	clusterServicesKubeAPIAuditLogWebhookConfigArg           = "audit-webhook-config-file"
+	clusterServicesKubeAPIAuditLogWebhookConfigFile          = "audit-webhook-config.yaml"
+	clusterServicesKubeAPIEventRateLimitPluginName           = "EventRateLimit"
+	clusterServicesKubeAPIPodSecurityPluginName              = "PodSecurity"
+	clusterServicesKubeAPIPodSecurityConfigAPIV1             = "pod-security.admission.config.k8s.io/v1"
+	clusterServicesKubeAPIPodSecurityConfigAPIV1beta1        = "pod-security.admission.config.k8s.io/v1beta1"
+	clusterServicesKubeAPIPodSecurityConfigKindDefault       = "PodSecurityConfiguration"
+	clusterServicesKubeAPIPodSecurityRestricted              = "restricted"
+	clusterServicesKubeAPIFilesDir                           = "/etc/rke-provider/kube-api"
+	clusterServicesKubeAPIFilesChecksumEnv                   = "RKE_PROVIDER_FILES_CHECKSUM"
 )
 
 var (
@@ -31,10 +53,50 @@ var (
 		"privileged",
 		"restricted",
 	}
+	// clusterServicesKubeAPIFilesArgs kube-api args set to the provider managed files, by file name
+	clusterServicesKubeAPIFilesArgs = map[string]string{
+		clusterServicesKubeAPIAdmissionConfigurationFile: clusterServicesKubeAPIAdmissionConfigurationArg,
+		clusterServicesKubeAPIAuditLogWebhookConfigFile:  clusterServicesKubeAPIAuditLogWebhookConfigArg,
+	}
+	clusterServicesKubeAPIAuditLogWebhookModes = []string{
+		clusterServicesKubeAPIAuditLogWebhookModeBatch,
+		clusterServicesKubeAPIAuditLogWebhookModeBlocking,
+		clusterServicesKubeAPIAuditLogWebhookModeBlockingStrict,
+	}
+	clusterServicesKubeAPISecretsEncryptionKMSAPIVersions = []string{
+		clusterServicesKubeAPISecretsEncryptionKMSAPIVersionV1,
+		clusterServicesKubeAPISecretsEncryptionKMSAPIVersionV2,
+	}
 )
 
 // Schemas
 
+func rkeClusterServicesKubeAPIAdmissionConfigurationFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"plugins": {
+			Type:        schema.TypeMap,
+			Required:    true,
+			Description: "Admission plugin configurations, keyed by plugin name, in yaml or json format",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+				v, ok := val.(map[string]interface{})
+				if !ok || len(v) == 0 {
+					return
+				}
+				_, err := newRKEClusterServicesKubeAPIAdmissionConfiguration(v)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%q %v", key, err))
+				}
+				return
+			},
+			DiffSuppressFunc: suppressYamlDiff,
+		},
+	}
+	return s
+}
+
 func rkeClusterServicesKubeAPIAuditLogConfigFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"format": {
@@ -63,23 +125,24 @@ func rkeClusterServicesKubeAPIAuditLogConfigFields() map[string]*schema.Schema {
 			Computed: true,
 		},
 		"policy": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
+			Type:        schema.TypeString,
+			Optional:    true,
+			Computed:    true,
+			Description: "Audit policy in yaml or json format",
 			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
 				v, ok := val.(string)
 				if !ok || len(v) == 0 {
 					return
 				}
-				m, err := jsonToMapInterface(v)
+				m, err := ghodssyamlToMapInterface(v)
 				if err != nil {
-					errs = append(errs, fmt.Errorf("%q must be in json format, error: %v", key, err))
+					errs = append(errs, fmt.Errorf("%q must be in yaml or json format, error: %v", key, err))
 					return
 				}
 				for _, k := range clusterServicesKubeAPIRequired {
 					check, ok := m[k].(string)
 					if !ok || len(check) == 0 {
-						errs = append(errs, fmt.Errorf("%s is required on json", k))
+						errs = append(errs, fmt.Errorf("%s is required on policy", k))
 					}
 					if k == clusterServicesKubeAPIKindTag {
 						if check != clusterServicesKubeAPIAuditLogConfigPolicyKindDefault {
@@ -88,16 +151,26 @@ func rkeClusterServicesKubeAPIAuditLogConfigFields() map[string]*schema.Schema {
 					}
 
 				}
+				if len(errs) > 0 {
+					return
+				}
+				if _, err := expandRKEClusterServicesKubeAPIAuditLogPolicy(v); err != nil {
+					errs = append(errs, fmt.Errorf("%q %v", key, err))
+				}
 				return
 			},
 			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
 				if old == "" || new == "" {
 					return false
 				}
-				oldPolicy := &auditv1.Policy{}
-				newPolicy := &auditv1.Policy{}
-				jsonToInterface(old, oldPolicy)
-				jsonToInterface(new, newPolicy)
+				oldPolicy, err := expandRKEClusterServicesKubeAPIAuditLogPolicy(old)
+				if err != nil {
+					return false
+				}
+				newPolicy, err := expandRKEClusterServicesKubeAPIAuditLogPolicy(new)
+				if err != nil {
+					return false
+				}
 				return reflect.DeepEqual(oldPolicy, newPolicy)
 			},
 		},
@@ -105,6 +178,73 @@ func rkeClusterServicesKubeAPIAuditLogConfigFields() map[string]*schema.Schema {
 	return s
 }
 
+func rkeClusterServicesKubeAPIAuditLogWebhookFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"batch_buffer_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Size of the buffer to store events before batching and writing",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"batch_max_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Maximum size of a batch",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"batch_max_wait": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Amount of time to wait before force writing a batch that hadn't reached the max size, like 30s",
+			ValidateFunc: validateRKEClusterServicesKubeAPIDuration,
+		},
+		"batch_throttle_burst": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Maximum number of requests sent at the same moment if throttle_qps wasn't utilized before",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"batch_throttle_enable": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Whether batching throttling is enabled",
+		},
+		"batch_throttle_qps": {
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			Description:  "Maximum average number of batches per second",
+			ValidateFunc: validation.FloatAtLeast(0),
+		},
+		"config_file": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Absolute path to the webhook kubeconfig file on the controlplane nodes",
+			ValidateFunc: validation.StringMatch(regexp.MustCompile(`^/.+`), "must be an absolute path"),
+		},
+		"initial_backoff": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Amount of time to wait before retrying the first failed request, like 10s",
+			ValidateFunc: validateRKEClusterServicesKubeAPIDuration,
+		},
+		"kubeconfig": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Sensitive:   true,
+			Description: "Webhook kubeconfig yaml, written to the controlplane nodes",
+		},
+		"mode": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      clusterServicesKubeAPIAuditLogWebhookModeBatch,
+			Description:  "Strategy for sending audit events (batch, blocking or blocking-strict)",
+			ValidateFunc: validation.StringInSlice(clusterServicesKubeAPIAuditLogWebhookModes, false),
+		},
+	}
+	return s
+}
+
 func rkeClusterServicesKubeAPIAuditLogFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"configuration": {
@@ -121,6 +261,15 @@ func rkeClusterServicesKubeAPIAuditLogFields() map[string]*schema.Schema {
 			Optional: true,
 			Computed: true,
 		},
+		"webhook": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Audit webhook backend configuration",
+			Elem: &schema.Resource{
+				Schema: rkeClusterServicesKubeAPIAuditLogWebhookFields(),
+			},
+		},
 	}
 	return s
 }
@@ -159,14 +308,17 @@ func rkeClusterServicesKubeAPIEventRateLimitFields() map[string]*schema.Schema {
 				if old == "" || new == "" {
 					return false
 				}
+				if suppressYamlDiff(k, old, new, d) {
+					return true
+				}
 				oldObject := &eventratelimitapi.Configuration{}
 				newObject := &eventratelimitapi.Configuration{}
-				oldMap, _ := ghodssyamlToMapInterface(old)
-				newMap, _ := ghodssyamlToMapInterface(new)
-				oldStr, _ := mapInterfaceToJSON(oldMap)
-				newStr, _ := mapInterfaceToJSON(newMap)
-				jsonToInterface(oldStr, oldObject)
-				jsonToInterface(newStr, newObject)
+				if err := ghodssyamlToInterface(old, oldObject); err != nil {
+					return false
+				}
+				if err := ghodssyamlToInterface(new, newObject); err != nil {
+					return false
+				}
 				return reflect.DeepEqual(oldObject, newObject)
 			},
 		},
@@ -179,12 +331,62 @@ func rkeClusterServicesKubeAPIEventRateLimitFields() map[string]*schema.Schema {
 	return s
 }
 
+func rkeClusterServicesKubeAPISecretsEncryptionKMSFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"api_version": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      clusterServicesKubeAPISecretsEncryptionKMSAPIVersionV2,
+			Description:  "KMS plugin API version (v1 or v2)",
+			ValidateFunc: validation.StringInSlice(clusterServicesKubeAPISecretsEncryptionKMSAPIVersions, false),
+		},
+		"cache_size": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Maximum number of secrets cached in memory. Only supported by KMS v1 plugins",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"endpoint": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "KMS plugin unix socket, like unix:///var/run/kmsplugin/socket.sock",
+			ValidateFunc: validation.StringMatch(regexp.MustCompile(`^unix:///.+`), "must be a unix socket, like unix:///var/run/kmsplugin/socket.sock"),
+		},
+		"name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			Description:  "KMS plugin name",
+			ValidateFunc: validation.StringIsNotEmpty,
+		},
+		"timeout": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      clusterServicesKubeAPISecretsEncryptionKMSTimeoutDefault,
+			Description:  "Timeout for gRPC calls to the KMS plugin, like 3s",
+			ValidateFunc: validateRKEClusterServicesKubeAPIDuration,
+			DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+				oldTimeout, err := time.ParseDuration(old)
+				if err != nil {
+					return false
+				}
+				newTimeout, err := time.ParseDuration(new)
+				if err != nil {
+					return false
+				}
+				return oldTimeout == newTimeout
+			},
+		},
+	}
+	return s
+}
+
 func rkeClusterServicesKubeAPISecretsEncryptionConfigFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"custom_config": {
-			Type:     schema.TypeString,
-			Optional: true,
-			Computed: true,
+			Type:      schema.TypeString,
+			Optional:  true,
+			Computed:  true,
+			Sensitive: true,
 			ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
 				v, ok := val.(string)
 				if !ok || len(v) == 0 {
@@ -213,14 +415,17 @@ func rkeClusterServicesKubeAPISecretsEncryptionConfigFields() map[string]*schema
 				if old == "" || new == "" {
 					return false
 				}
+				if suppressYamlDiff(k, old, new, d) {
+					return true
+				}
 				oldObject := &apiserverconfig.EncryptionConfiguration{}
 				newObject := &apiserverconfig.EncryptionConfiguration{}
-				oldMap, _ := ghodssyamlToMapInterface(old)
-				newMap, _ := ghodssyamlToMapInterface(new)
-				oldStr, _ := mapInterfaceToJSON(oldMap)
-				newStr, _ := mapInterfaceToJSON(newMap)
-				jsonToInterface(oldStr, oldObject)
-				jsonToInterface(newStr, newObject)
+				if err := ghodssyamlToInterface(old, oldObject); err != nil {
+					return false
+				}
+				if err := ghodssyamlToInterface(new, newObject); err != nil {
+					return false
+				}
 				return reflect.DeepEqual(oldObject, newObject)
 			},
 		},
@@ -229,12 +434,30 @@ func rkeClusterServicesKubeAPISecretsEncryptionConfigFields() map[string]*schema
 			Optional: true,
 			Computed: true,
 		},
+		"kms": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "KMS provider used to encrypt secrets. Takes precedence over custom_config",
+			Elem: &schema.Resource{
+				Schema: rkeClusterServicesKubeAPISecretsEncryptionKMSFields(),
+			},
+		},
 	}
 	return s
 }
 
 func rkeClusterServicesKubeAPIFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
+		"admission_configuration": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "Admission plugins configuration delivered to the controlplane nodes",
+			Elem: &schema.Resource{
+				Schema: rkeClusterServicesKubeAPIAdmissionConfigurationFields(),
+			},
+		},
 		"always_pull_images": {
 			Type:        schema.TypeBool,
 			Optional:    true,
@@ -323,3 +546,15 @@ func rkeClusterServicesKubeAPIFields() map[string]*schema.Schema {
 	}
 	return s
 }
+
+func validateRKEClusterServicesKubeAPIDuration(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok || len(v) == 0 {
+		return
+	}
+	duration, err := time.ParseDuration(v)
+	if err != nil || duration <= 0 {
+		errs = append(errs, fmt.Errorf("%q must be a positive duration, like 3s, got: %s", key, v))
+	}
+	return
+}
@@ -1,11 +1,54 @@
 package rke
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+var (
+	rkeClusterServicesBindVolumeRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	rkeClusterServicesBindOptions      = map[string]bool{
+		"ro":         true,
+		"rw":         true,
+		"z":          true,
+		"Z":          true,
+		"shared":     true,
+		"slave":      true,
+		"private":    true,
+		"rshared":    true,
+		"rslave":     true,
+		"rprivate":   true,
+		"nocopy":     true,
+		"consistent": true,
+		"cached":     true,
+		"delegated":  true,
+	}
+)
+
 //Schemas
 
+func rkeClusterServicesExtraArgsArrayFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"name": {
+			Type:        schema.TypeString,
+			Required:    true,
+			Description: "Argument name, without leading dashes",
+		},
+		"value": {
+			Type:        schema.TypeList,
+			Required:    true,
+			Description: "Argument values, the argument is repeated for each value",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+	}
+	return s
+}
+
 func rkeClusterServicesKubeletFields() map[string]*schema.Schema {
 	s := map[string]*schema.Schema{
 		"cluster_dns_server": {
@@ -26,13 +69,22 @@ func rkeClusterServicesKubeletFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "Extra arguments that are added to the kubelet services",
 		},
+		"extra_args_array": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Extra arguments that can be specified multiple times, added to the kubelet services",
+			Elem: &schema.Resource{
+				Schema: rkeClusterServicesExtraArgsArrayFields(),
+			},
+		},
 		"extra_binds": {
 			Type:        schema.TypeList,
 			Optional:    true,
 			Computed:    true,
 			Description: "Extra binds added to the worker nodes",
 			Elem: &schema.Schema{
-				Type: schema.TypeString,
+				Type:         schema.TypeString,
+				ValidateFunc: validateRKEClusterServicesBind,
 			},
 		},
 		"extra_env": {
@@ -67,6 +119,63 @@ func rkeClusterServicesKubeletFields() map[string]*schema.Schema {
 			Computed:    true,
 			Description: "The image whose network/ipc namespaces containers in each pod will use",
 		},
+		"win_extra_args": {
+			Type:        schema.TypeMap,
+			Optional:    true,
+			Description: "Extra arguments that are added to the kubelet services on windows nodes",
+		},
+		"win_extra_args_array": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Extra arguments that can be specified multiple times, added to the kubelet services on windows nodes",
+			Elem: &schema.Resource{
+				Schema: rkeClusterServicesExtraArgsArrayFields(),
+			},
+		},
+		"win_extra_binds": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Extra binds added to the windows worker nodes",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"win_extra_env": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Extra env added to the windows nodes",
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
 	}
 	return s
 }
+
+// validateRKEClusterServicesBind validates docker binds like /src:/dst[:opts], where src may be a volume name
+func validateRKEClusterServicesBind(i interface{}, k string) ([]string, []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+
+	parts := strings.Split(v, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, []error{fmt.Errorf("%s: bind %q must be like <src>:<dst>[:<options>]", k, v)}
+	}
+	if !strings.HasPrefix(parts[0], "/") && !rkeClusterServicesBindVolumeRegexp.MatchString(parts[0]) {
+		return nil, []error{fmt.Errorf("%s: bind %q source must be an absolute path or a volume name", k, v)}
+	}
+	if !strings.HasPrefix(parts[1], "/") {
+		return nil, []error{fmt.Errorf("%s: bind %q destination must be an absolute path", k, v)}
+	}
+	if len(parts) == 3 {
+		for _, option := range strings.Split(parts[2], ",") {
+			if !rkeClusterServicesBindOptions[option] {
+				return nil, []error{fmt.Errorf("%s: bind %q has unsupported option %q", k, v, option)}
+			}
+		}
+	}
+
+	return nil, nil
+}
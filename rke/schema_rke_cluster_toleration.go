@@ -0,0 +1,52 @@
+package rke
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	v1 "k8s.io/api/core/v1"
+)
+
+var (
+	rkeClusterTolerationOperators = []string{
+		string(v1.TolerationOpEqual),
+		string(v1.TolerationOpExists),
+	}
+)
+
+//Schemas
+
+func rkeClusterTolerationFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"key": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Toleration key. Empty key with Exists operator matches all taints",
+		},
+		"effect": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Description:  "Toleration effect. Empty effect matches all effects",
+			ValidateFunc: validation.StringInSlice(rkeClusterTaintEffectTypes, false),
+		},
+		"operator": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      string(v1.TolerationOpEqual),
+			Description:  "Toleration operator",
+			ValidateFunc: validation.StringInSlice(rkeClusterTolerationOperators, false),
+		},
+		"seconds": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Description:  "Toleration seconds for NoExecute effect. Tolerated forever if not set",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+		"value": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Toleration value",
+		},
+	}
+
+	return s
+}
@@ -0,0 +1,102 @@
+package rke
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+var (
+	rkeClusterIntOrPercentRegexp           = regexp.MustCompile(`^[0-9]+%?$`)
+	rkeClusterDaemonSetUpdateStrategyTypes = []string{
+		string(appsv1.OnDeleteDaemonSetStrategyType),
+		string(appsv1.RollingUpdateDaemonSetStrategyType),
+	}
+	rkeClusterDeploymentUpdateStrategyTypes = []string{
+		string(appsv1.RecreateDeploymentStrategyType),
+		string(appsv1.RollingUpdateDeploymentStrategyType),
+	}
+)
+
+//Schemas
+
+func rkeClusterDaemonSetRollingUpdateFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"max_unavailable": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Daemonset rolling update max unavailable. Number or percentage",
+			ValidateFunc: validation.StringMatch(rkeClusterIntOrPercentRegexp, "must be a number or a percentage"),
+		},
+	}
+	return s
+}
+
+func rkeClusterDaemonSetUpdateStrategyFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"rolling_update": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "Daemonset rolling update",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDaemonSetRollingUpdateFields(),
+			},
+		},
+		"strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Daemonset update strategy",
+			ValidateFunc: validation.StringInSlice(rkeClusterDaemonSetUpdateStrategyTypes, false),
+		},
+	}
+	return s
+}
+
+func rkeClusterDeploymentRollingUpdateFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"max_surge": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Deployment rolling update max surge. Number or percentage",
+			ValidateFunc: validation.StringMatch(rkeClusterIntOrPercentRegexp, "must be a number or a percentage"),
+		},
+		"max_unavailable": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Deployment rolling update max unavailable. Number or percentage",
+			ValidateFunc: validation.StringMatch(rkeClusterIntOrPercentRegexp, "must be a number or a percentage"),
+		},
+	}
+	return s
+}
+
+func rkeClusterDeploymentUpdateStrategyFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"rolling_update": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Computed:    true,
+			Description: "Deployment rolling update",
+			Elem: &schema.Resource{
+				Schema: rkeClusterDeploymentRollingUpdateFields(),
+			},
+		},
+		"strategy": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Computed:     true,
+			Description:  "Deployment update strategy",
+			ValidateFunc: validation.StringInSlice(rkeClusterDeploymentUpdateStrategyTypes, false),
+		},
+	}
+	return s
+}
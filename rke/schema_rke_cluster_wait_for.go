@@ -0,0 +1,67 @@
+package rke
+
+import (
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	rkeClusterWaitForTimeoutDefault = 600
+)
+
+var (
+	rkeClusterWaitForWorkloadRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?/[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`)
+)
+
+//Schemas
+
+func rkeClusterWaitForFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"api_readyz": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Wait for k8s api /readyz endpoint",
+		},
+		"daemonsets": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Wait for daemonsets rolled out, as namespace/name",
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(rkeClusterWaitForWorkloadRegexp, "must be namespace/name"),
+			},
+		},
+		"deployments": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Wait for deployments rolled out, as namespace/name",
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(rkeClusterWaitForWorkloadRegexp, "must be namespace/name"),
+			},
+		},
+		"nodes_ready": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Wait for all nodes Ready",
+		},
+		"system_pods_ready": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Wait for kube-system deployments and daemonsets rolled out, jobs complete and pods without controller Ready",
+		},
+		"timeout": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      rkeClusterWaitForTimeoutDefault,
+			Description:  "Wait timeout in seconds",
+			ValidateFunc: validation.IntAtLeast(1),
+		},
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package rke
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	rkeHostPreflightMaxClockSkewDefault  = 2
+	rkeHostPreflightMinDiskFreeMBDefault = 10240
+	rkeHostPreflightNodePortRange        = "30000-32767"
+	rkeHostPreflightUDPSuffix            = "/udp"
+)
+
+//Schemas
+
+func rkeHostPreflightResultFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"address": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"br_netfilter_loaded": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"bridge_nf_call_iptables": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"clock_skew": {
+			Type:     schema.TypeFloat,
+			Computed: true,
+		},
+		"disk_free_mb": {
+			Type:     schema.TypeInt,
+			Computed: true,
+		},
+		"docker_version": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"docker_version_supported": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"errors": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"ip_forward": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"occupied_ports": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"passed": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+		"prefix_path": {
+			Type:     schema.TypeString,
+			Computed: true,
+		},
+		"swap_enabled": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+	}
+	return s
+}
+
+func rkeHostPreflightFields() map[string]*schema.Schema {
+	s := map[string]*schema.Schema{
+		"nodes": {
+			Type:        schema.TypeList,
+			Required:    true,
+			Description: "RKE k8s cluster nodes to check",
+			Elem: &schema.Resource{
+				Schema: rkeClusterNodeFields(),
+			},
+		},
+		"bastion_host": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "RKE k8s cluster bastion Host configuration. Multiple bastion hosts are used as an ordered SSH chain",
+			Elem: &schema.Resource{
+				Schema: rkeClusterBastionHostFields(),
+			},
+		},
+		"default_connection": {
+			Type:        schema.TypeList,
+			MaxItems:    1,
+			Optional:    true,
+			Description: "RKE k8s cluster default connection to nodes",
+			Elem: &schema.Resource{
+				Schema: rkeClusterConnectionFields(),
+			},
+		},
+		"disable_port_check": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Enable/Disable RKE k8s cluster port checking",
+		},
+		"enable_br_netfilter": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     true,
+			Description: "Enable/Disable br_netfilter on nodes. If disabled, br_netfilter must be loaded on nodes",
+		},
+		"fail_swap_on": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Fail if swap is enabled on nodes",
+		},
+		"ignore_docker_version": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Enable/Disable RKE k8s cluster strict docker version checking",
+		},
+		"kubernetes_version": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "K8s version to check docker version against. Default: `rke default`",
+		},
+		"max_clock_skew": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      rkeHostPreflightMaxClockSkewDefault,
+			Description:  "Max clock skew in seconds between nodes and provider host",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"min_disk_free_mb": {
+			Type:         schema.TypeInt,
+			Optional:     true,
+			Default:      rkeHostPreflightMinDiskFreeMBDefault,
+			Description:  "Min free disk space in MB under prefix_path",
+			ValidateFunc: validation.IntAtLeast(0),
+		},
+		"prefix_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "RKE k8s directory path",
+		},
+		"preflight_image": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Image used to run checks on nodes. Default: `rke default alpine image`",
+		},
+		"ssh_agent_auth": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "SSH Agent Auth enable",
+		},
+		"ssh_cert_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "SSH Certificate Path",
+		},
+		"ssh_key_path": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "SSH Private Key Path",
+		},
+		// Computed fields
+		"hosts": {
+			Type:     schema.TypeList,
+			Computed: true,
+			Elem: &schema.Resource{
+				Schema: rkeHostPreflightResultFields(),
+			},
+		},
+		"passed": {
+			Type:     schema.TypeBool,
+			Computed: true,
+		},
+	}
+	return s
+}
@@ -1,14 +1,25 @@
 package rke
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/pki"
 	rancher "github.com/rancher/rke/types"
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/apiserver/v1"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Flatteners
@@ -38,7 +49,11 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 	}
 
 	if v, ok := d.Get("addons").(string); ok && len(v) > 0 && len(in.Addons) > 0 {
-		d.Set("addons", in.Addons)
+		addons, err := flattenRKEClusterCloudProviderExternalAddons(in.Addons, d.Get("cloud_provider").([]interface{}))
+		if err != nil {
+			return err
+		}
+		d.Set("addons", addons)
 	}
 
 	if v, ok := d.Get("addons_include").([]interface{}); ok && len(v) > 0 && len(in.AddonsInclude) > 0 {
@@ -49,7 +64,7 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 	}
 
 	if v, ok := d.Get("authentication").([]interface{}); ok && len(v) > 0 {
-		err = d.Set("authentication", flattenRKEClusterAuthentication(in.Authentication))
+		err = d.Set("authentication", flattenRKEClusterAuthentication(in.Authentication, v, d.Get("api_server_endpoint").(string)))
 		if err != nil {
 			return err
 		}
@@ -62,7 +77,8 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 		}
 	}
 
-	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) > 0 {
+	// Bastion host chains aren't set on RKE config
+	if v, ok := d.Get("bastion_host").([]interface{}); ok && len(v) == 1 {
 		err = d.Set("bastion_host", flattenRKEClusterBastionHost(in.BastionHost))
 		if err != nil {
 			return err
@@ -116,7 +132,7 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 	}
 
 	if v, ok := d.Get("network").([]interface{}); ok && len(v) > 0 {
-		err = d.Set("network", flattenRKEClusterNetwork(in.Network))
+		err = d.Set("network", flattenRKEClusterNetwork(in.Network, v))
 		if err != nil {
 			return err
 		}
@@ -134,8 +150,12 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 		d.Set("prefix_path", in.PrefixPath)
 	}
 
+	if v, ok := d.Get("win_prefix_path").(string); ok && len(v) > 0 && len(in.WindowsPrefixPath) > 0 {
+		d.Set("win_prefix_path", in.WindowsPrefixPath)
+	}
+
 	if v, ok := d.Get("private_registries").([]interface{}); ok && len(v) > 0 && in.PrivateRegistries != nil {
-		err := d.Set("private_registries", flattenRKEClusterPrivateRegistries(in.PrivateRegistries))
+		err := d.Set("private_registries", flattenRKEClusterPrivateRegistries(in.PrivateRegistries, v))
 		if err != nil {
 			return err
 		}
@@ -190,12 +210,24 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 	}
 
 	// computed values
-	d.Set("api_server_url", "") // nolint
-	if in.ControlPlaneHosts != nil && len(in.ControlPlaneHosts) > 0 {
-		apiServerURL := fmt.Sprintf("https://" + in.ControlPlaneHosts[0].Address + ":6443")
-		d.Set("api_server_url", apiServerURL)
+	apiServerURLs := make([]interface{}, 0, len(in.ControlPlaneHosts))
+	for _, host := range in.ControlPlaneHosts {
+		apiServerURLs = append(apiServerURLs, rkeClusterAPIServerURL(host.Address))
+	}
+	d.Set("api_server_urls", apiServerURLs) // nolint
+	d.Set("api_server_url", "")             // nolint
+	if v, ok := d.Get("api_server_endpoint").(string); ok && len(v) > 0 {
+		d.Set("api_server_url", rkeClusterAPIServerEndpointURL(v))
+	} else if len(apiServerURLs) > 0 {
+		d.Set("api_server_url", apiServerURLs[0])
 	}
 
+	renderedCloudConfig, err := flattenRKEClusterRenderedCloudConfig(d.Get("cluster_yaml").(string), d.Get("cloud_provider").([]interface{}))
+	if err != nil {
+		log.Warnf("[rke_provider] Unable to render cloud config: %v", err)
+	}
+	d.Set("rendered_cloud_config", renderedCloudConfig) // nolint
+
 	caCrt, clientCrt, clientKey, certificates := flattenRKEClusterCertificates(in.Certificates)
 	d.Set("ca_crt", caCrt)          // nolint
 	d.Set("client_cert", clientCrt) // nolint
@@ -205,6 +237,15 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 	d.Set("cluster_domain", in.ClusterDomain)        // nolint
 	d.Set("cluster_cidr", in.ClusterCIDR)            // nolint
 	d.Set("cluster_dns_server", in.ClusterDNSServer) // nolint
+	clusterCIDRIPv4, clusterCIDRIPv6 := splitRKEClusterCIDRsByFamily(in.ClusterCIDR)
+	serviceClusterIPRangeIPv4, serviceClusterIPRangeIPv6 := splitRKEClusterCIDRsByFamily(in.Services.KubeAPI.ServiceClusterIPRange)
+	d.Set("cluster_cidr_ipv4", clusterCIDRIPv4)                                                                                                               // nolint
+	d.Set("cluster_cidr_ipv6", clusterCIDRIPv6)                                                                                                               // nolint
+	d.Set("service_cluster_ip_range_ipv4", serviceClusterIPRangeIPv4)                                                                                         // nolint
+	d.Set("service_cluster_ip_range_ipv6", serviceClusterIPRangeIPv6)                                                                                         // nolint
+	d.Set("dual_stack", (len(clusterCIDRIPv4) > 0 && len(clusterCIDRIPv6) > 0) || (len(serviceClusterIPRangeIPv4) > 0 && len(serviceClusterIPRangeIPv6) > 0)) // nolint
+
+	d.Set("encryption_key_name", flattenRKEClusterEncryptionKeyName(in.EncryptionConfig.EncryptionProviderFile)) // nolint
 
 	err = d.Set("etcd_hosts", flattenRKEClusterNodesComputed(in.EtcdHosts))
 	if err != nil {
@@ -237,6 +278,12 @@ func flattenRKECluster(d *schema.ResourceData, in *cluster.Cluster) error {
 		return err
 	}
 
+	rkeClusterYamlRedacted, err := expandRKEClusterYamlRedacted(rkeClusterYaml, d.Get("cloud_provider").([]interface{}), expandRKEClusterYamlRedactedKey(d.Get("rke_state").(string)))
+	if err != nil {
+		log.Warnf("[rke_provider] Unable to redact RKE cluster yaml: %v", err)
+	}
+	d.Set("rke_cluster_yaml_redacted", rkeClusterYamlRedacted) // nolint
+
 	return nil
 }
 
@@ -273,16 +320,25 @@ func expandRKECluster(in *schema.ResourceData) (string, *rancher.RancherKubernet
 		obj.Authentication = expandRKEClusterAuthentication(v)
 	}
 
+	if v, ok := in.Get("api_server_endpoint").(string); ok && len(v) > 0 {
+		obj.Authentication.SANs = appendRKEClusterAuthenticationSAN(obj.Authentication.SANs, rkeClusterAPIServerEndpointHost(v))
+	}
+
 	if v, ok := in.Get("authorization").([]interface{}); ok && len(v) > 0 {
 		obj.Authorization = expandRKEClusterAuthorization(v)
 	}
 
-	if v, ok := in.Get("bastion_host").([]interface{}); ok && len(v) > 0 {
+	if v, ok := in.Get("bastion_host").([]interface{}); ok && len(v) == 1 {
 		obj.BastionHost = expandRKEClusterBastionHost(v)
 	}
 
 	if v, ok := in.Get("cloud_provider").([]interface{}); ok && len(v) > 0 {
 		obj.CloudProvider = expandRKEClusterCloudProvider(v)
+		addons, err := expandRKEClusterCloudProviderExternalAddons(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed expanding cloud_provider: %v", err)
+		}
+		obj.Addons = joinRKEClusterAddons(obj.Addons, addons)
 	}
 
 	if v, ok := in.Get("cluster_name").(string); ok && len(v) > 0 {
@@ -302,7 +358,11 @@ func expandRKECluster(in *schema.ResourceData) (string, *rancher.RancherKubernet
 	}
 
 	if v, ok := in.Get("ingress").([]interface{}); ok && len(v) > 0 {
-		obj.Ingress = expandRKEClusterIngress(v)
+		ingress, err := expandRKEClusterIngress(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed expanding ingress: %v", err)
+		}
+		obj.Ingress = ingress
 	}
 
 	if v, ok := in.Get("kubernetes_version").(string); ok && len(v) > 0 {
@@ -314,7 +374,11 @@ func expandRKECluster(in *schema.ResourceData) (string, *rancher.RancherKubernet
 	}
 
 	if v, ok := in.Get("network").([]interface{}); ok && len(v) > 0 {
-		obj.Network = expandRKEClusterNetwork(v)
+		network, err := expandRKEClusterNetwork(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed expanding network: %v", err)
+		}
+		obj.Network = network
 	}
 
 	if v, ok := in.Get("nodes").([]interface{}); ok && len(v) > 0 {
@@ -325,8 +389,16 @@ func expandRKECluster(in *schema.ResourceData) (string, *rancher.RancherKubernet
 		obj.PrefixPath = v
 	}
 
+	if v, ok := in.Get("win_prefix_path").(string); ok && len(v) > 0 {
+		obj.WindowsPrefixPath = v
+	}
+
 	if v, ok := in.Get("private_registries").([]interface{}); ok && len(v) > 0 {
-		obj.PrivateRegistries = expandRKEClusterPrivateRegistries(v)
+		privateRegistries, err := expandRKEClusterPrivateRegistries(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed expanding private_registries: %v", err)
+		}
+		obj.PrivateRegistries = privateRegistries
 	}
 
 	if v, ok := in.Get("restore").([]interface{}); ok && len(v) > 0 {
@@ -384,9 +456,21 @@ func expandRKECluster(in *schema.ResourceData) (string, *rancher.RancherKubernet
 	return objYml, obj, nil
 }
 
-// patchRKEClusterYaml is needed due to auditv1.Policy{} doesn't provide yaml tags
+// patchRKEClusterYaml is needed due to auditv1.Policy{} and apiserverv1.AdmissionConfiguration{} don't provide yaml tags
 func patchRKEClusterYaml(in *rancher.RancherKubernetesEngineConfig) (string, error) {
 	outFixed := make(map[string]interface{})
+	if in.Services.KubeAPI.AdmissionConfiguration != nil {
+		inJSON, err := interfaceToJSON(in.Services.KubeAPI.AdmissionConfiguration)
+		if err != nil {
+			return "", err
+		}
+		if len(inJSON) > 0 {
+			outFixed["admission_configuration"], err = jsonToMapInterface(inJSON)
+			if err != nil {
+				return "", fmt.Errorf("unmarshalling admission_configuration json: %s", err)
+			}
+		}
+	}
 	if in.Services.KubeAPI.AuditLog != nil && in.Services.KubeAPI.AuditLog.Configuration != nil {
 		inJSON, err := interfaceToJSON(in.Services.KubeAPI.AuditLog.Configuration.Policy)
 		if err != nil {
@@ -462,6 +546,9 @@ func patchRKEClusterYaml(in *rancher.RancherKubernetesEngineConfig) (string, err
 
 	if services, ok := out["services"].(map[string]interface{}); ok {
 		if kubeapi, ok := services["kube-api"].(map[string]interface{}); ok {
+			if outFixed["admission_configuration"] != nil {
+				out["services"].(map[string]interface{})["kube-api"].(map[string]interface{})["admission_configuration"] = outFixed["admission_configuration"]
+			}
 			if auditlog, ok := kubeapi["audit_log"].(map[string]interface{}); ok && outFixed["audit_log"] != nil {
 				if _, ok := auditlog["configuration"].(map[string]interface{}); ok {
 					out["services"].(map[string]interface{})["kube-api"].(map[string]interface{})["audit_log"].(map[string]interface{})["configuration"].(map[string]interface{})["policy"] = outFixed["audit_log"]
@@ -484,6 +571,156 @@ func patchRKEClusterYaml(in *rancher.RancherKubernetesEngineConfig) (string, err
 	return outYaml, nil
 }
 
+// expandRKEClusterYamlRedacted returns the RKE cluster yaml replacing every sensitive argument value by a keyed hash placeholder
+func expandRKEClusterYamlRedacted(rkeClusterYaml string, cloudProvider []interface{}, key []byte) (string, error) {
+	if len(rkeClusterYaml) == 0 {
+		return "", nil
+	}
+
+	obj, err := cluster.ParseConfig(rkeClusterYaml)
+	if err != nil {
+		return "", fmt.Errorf("parsing RKE cluster yaml: %v", err)
+	}
+
+	paths := rkeClusterSensitivePaths()
+	redactRKEClusterSensitiveValues(reflect.ValueOf(obj), "", paths, key)
+
+	// Cloud controller manager secret is generated from cloud_provider block, it has to be rendered again with redacted values
+	addons, err := expandRKEClusterCloudProviderExternalAddons(cloudProvider)
+	if err != nil {
+		return "", err
+	}
+	if len(addons) > 0 && strings.HasSuffix(obj.Addons, addons) {
+		redactedAddons, err := renderRKEClusterCloudProviderExternalAddons(cloudProvider, func(config *rancher.CloudProvider) error {
+			redactRKEClusterSensitiveValues(reflect.ValueOf(config), "cloud_provider", paths, key)
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		obj.Addons = strings.TrimSuffix(obj.Addons, addons) + redactedAddons
+	}
+
+	return patchRKEClusterYaml(obj)
+}
+
+// expandRKEClusterYamlRedactedKey returns the hash key for redacted values. It's derived from the kube-ca private key on RKE state,
+// so placeholders are stable between applies and can't be guessed without the state. A random key is used if there is no state yet
+func expandRKEClusterYamlRedactedKey(rkeState string) []byte {
+	if len(rkeState) > 0 {
+		state := &cluster.FullState{}
+		if err := json.Unmarshal([]byte(rkeState), state); err == nil {
+			for _, bundle := range []map[string]pki.CertificatePKI{state.CurrentState.CertificatesBundle, state.DesiredState.CertificatesBundle} {
+				if caKey := bundle[pki.CACertName].KeyPEM; len(caKey) > 0 {
+					sum := sha256.Sum256([]byte(rkeClusterYamlRedactedPrefix + caKey))
+					return sum[:]
+				}
+			}
+		}
+	}
+
+	key := make([]byte, sha256.Size)
+	rand.Read(key) // nolint
+	return key
+}
+
+// rkeClusterSensitivePaths returns the normalized path of every sensitive rke_cluster argument
+func rkeClusterSensitivePaths() map[string]bool {
+	out := map[string]bool{}
+	var walk func(prefix string, in map[string]*schema.Schema)
+	walk = func(prefix string, in map[string]*schema.Schema) {
+		for k, v := range in {
+			if v.Computed && !v.Optional && !v.Required {
+				continue
+			}
+			path := joinRKEClusterYamlPath(prefix, k)
+			if v.Sensitive {
+				out[path] = true
+			}
+			if r, ok := v.Elem.(*schema.Resource); ok {
+				walk(path, r.Schema)
+			}
+		}
+	}
+	walk("", rkeClusterFields())
+
+	// Secrets encryption custom_config is a yaml argument, just its key material is redacted
+	for _, provider := range []string{"aescbc", "aesgcm", "secretbox"} {
+		out["services.kubeapi.secretsencryptionconfig.customconfig.resources.providers."+provider+".keys.secret"] = true
+	}
+	return out
+}
+
+// joinRKEClusterYamlPath joins and normalizes path elements, so that argument names match RKE yaml tags, e.g. kube_api and kube-api
+func joinRKEClusterYamlPath(prefix, name string) string {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	if len(prefix) == 0 {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// redactRKEClusterSensitiveValues replaces in place every non empty string which path is sensitive or is tagged as password by RKE
+func redactRKEClusterSensitiveValues(v reflect.Value, path string, paths map[string]bool, key []byte) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			redactRKEClusterSensitiveValues(v.Elem(), path, paths, key)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if len(name) == 0 {
+				name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+			}
+			if name == "-" {
+				continue
+			}
+			fieldPath := path
+			if len(name) > 0 {
+				fieldPath = joinRKEClusterYamlPath(path, name)
+			} else if !field.Anonymous {
+				fieldPath = joinRKEClusterYamlPath(path, field.Name)
+			}
+			fieldValue := v.Field(i)
+			if fieldValue.Kind() == reflect.String && strings.Contains(field.Tag.Get("norman"), "type=password") {
+				redactRKEClusterSensitiveValue(fieldValue, key)
+				continue
+			}
+			redactRKEClusterSensitiveValues(fieldValue, fieldPath, paths, key)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			redactRKEClusterSensitiveValues(v.Index(i), path, paths, key)
+		}
+	case reflect.Map:
+		for _, mapKey := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(mapKey))
+			redactRKEClusterSensitiveValues(value, path, paths, key)
+			v.SetMapIndex(mapKey, value)
+		}
+	case reflect.String:
+		if paths[path] {
+			redactRKEClusterSensitiveValue(v, key)
+		}
+	}
+}
+
+func redactRKEClusterSensitiveValue(v reflect.Value, key []byte) {
+	if !v.CanSet() || v.Len() == 0 {
+		return
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(v.String())) // nolint
+	v.SetString(fmt.Sprintf("%s%x", rkeClusterYamlRedactedPrefix, mac.Sum(nil)[:8]))
+}
+
 func expandRKEClusterFlag(in *schema.ResourceData, clusterFilePath string) cluster.ExternalFlags {
 	if in == nil {
 		return cluster.ExternalFlags{}
@@ -520,3 +757,259 @@ func k8sVersionRequiresCri(kubernetesVersion string) bool {
 	}
 	return parsedRangeAtLeast124(version)
 }
+
+// rkeClusterAPIServerURL returns the kube-apiserver URL for a control plane address, bracketing IPv6 addresses
+func rkeClusterAPIServerURL(address string) string {
+	return "https://" + net.JoinHostPort(address, cluster.KubeAPIPort)
+}
+
+// joinRKEClusterAddons appends provider generated addons to the user addons
+func joinRKEClusterAddons(addons, generated string) string {
+	if len(generated) == 0 {
+		return addons
+	}
+	if len(addons) == 0 {
+		return generated
+	}
+	return addons + rkeClusterAddonsSeparator + generated
+}
+
+// rkeClusterAPIServerEndpointHost returns the host of an api_server_endpoint, in host or host:port format
+func rkeClusterAPIServerEndpointHost(endpoint string) string {
+	if host, _, err := net.SplitHostPort(endpoint); err == nil {
+		return host
+	}
+	return strings.Trim(endpoint, "[]")
+}
+
+// rkeClusterAPIServerEndpointURL returns the kube-apiserver URL for an api_server_endpoint, using 6443 if port isn't set
+func rkeClusterAPIServerEndpointURL(endpoint string) string {
+	if _, _, err := net.SplitHostPort(endpoint); err == nil {
+		return "https://" + endpoint
+	}
+	return rkeClusterAPIServerURL(rkeClusterAPIServerEndpointHost(endpoint))
+}
+
+// rkeClusterKubeConfigWithServer returns the kube config with every cluster server set to the url
+func rkeClusterKubeConfigWithServer(kubeConfig, url string) (string, error) {
+	config, err := clientcmd.Load([]byte(kubeConfig))
+	if err != nil {
+		return "", fmt.Errorf("parsing kube config: %v", err)
+	}
+	for _, cluster := range config.Clusters {
+		cluster.Server = url
+	}
+	out, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("writing kube config: %v", err)
+	}
+	return string(out), nil
+}
+
+// splitRKEClusterCIDRsByFamily splits a comma separated, maybe dual-stack, CIDR list into its IPv4 and IPv6 CIDRs
+func splitRKEClusterCIDRsByFamily(in string) (string, string) {
+	var ipv4, ipv6 string
+	for _, cidr := range strings.Split(in, ",") {
+		cidr = strings.TrimSpace(cidr)
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			ipv4 = cidr
+		} else {
+			ipv6 = cidr
+		}
+	}
+	return ipv4, ipv6
+}
+
+// Validators
+
+const (
+	rkeClusterServiceCIDRMaxHostBits      = 20
+	rkeClusterNodeCIDRMaxMaskDiff         = 16
+	rkeClusterNodeCIDRMaskSizeArg         = "node-cidr-mask-size"
+	rkeClusterNodeCIDRMaskSizeIPv4Arg     = "node-cidr-mask-size-ipv4"
+	rkeClusterNodeCIDRMaskSizeIPv4Default = 24
+	rkeClusterNodeCIDRMaskSizeIPv6Arg     = "node-cidr-mask-size-ipv6"
+	rkeClusterNodeCIDRMaskSizeIPv6Default = 64
+)
+
+func validateRKEClusterAPIServerEndpoint(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok || len(v) == 0 {
+		return
+	}
+	if strings.Contains(v, "://") {
+		errs = append(errs, fmt.Errorf("%q must be in host or host:port format, got %s", key, v))
+		return
+	}
+	if host, port, err := net.SplitHostPort(v); err == nil {
+		if len(host) == 0 {
+			errs = append(errs, fmt.Errorf("%q host can't be empty, got %s", key, v))
+		}
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("%q port must be between 1 and 65535, got %s", key, port))
+		}
+		return
+	}
+	if strings.Contains(strings.Trim(v, "[]"), ":") && net.ParseIP(strings.Trim(v, "[]")) == nil {
+		errs = append(errs, fmt.Errorf("%q must be in host or host:port format, got %s", key, v))
+	}
+	return
+}
+
+// parseRKEClusterCIDRs parses a comma separated CIDR list, allowing one IPv4 and one IPv6 CIDR for dual-stack
+func parseRKEClusterCIDRs(name, in string) ([]*net.IPNet, error) {
+	cidrs := strings.Split(in, ",")
+	if len(cidrs) > 2 {
+		return nil, fmt.Errorf("%s %s can have up to 2 CIDRs, one IPv4 and one IPv6", name, in)
+	}
+	out := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s is not a valid CIDR: %v", name, cidr, err)
+		}
+		out = append(out, ipNet)
+	}
+	if len(out) == 2 && (out[0].IP.To4() == nil) == (out[1].IP.To4() == nil) {
+		return nil, fmt.Errorf("%s %s dual-stack CIDRs must be one IPv4 and one IPv6", name, in)
+	}
+	return out, nil
+}
+
+// rkeClusterNodeCIDRMaskSize returns the kube-controller node cidr mask size for the IP family
+func rkeClusterNodeCIDRMaskSize(extraArgs map[string]string, ipv6, dualStack bool) (int, error) {
+	arg, def := rkeClusterNodeCIDRMaskSizeIPv4Arg, rkeClusterNodeCIDRMaskSizeIPv4Default
+	if ipv6 {
+		arg, def = rkeClusterNodeCIDRMaskSizeIPv6Arg, rkeClusterNodeCIDRMaskSizeIPv6Default
+	}
+	v, ok := extraArgs[arg]
+	if !ok && !dualStack {
+		arg = rkeClusterNodeCIDRMaskSizeArg
+		v, ok = extraArgs[arg]
+	}
+	if !ok {
+		return def, nil
+	}
+	size, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("kube_controller extra_args %s %s is not a number: %v", arg, v, err)
+	}
+	return size, nil
+}
+
+func rkeClusterCIDRsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+func rkeClusterCIDRsContain(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, cidr := range cidrs {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRKEClusterCIDRs validates that service and pod CIDRs, cluster DNS server and node addresses are consistent.
+// Empty arguments are validated with the RKE defaults
+func validateRKEClusterCIDRs(in *rancher.RancherKubernetesEngineConfig) error {
+	if in == nil {
+		return nil
+	}
+	defaultIfEmpty := func(v, def string) string {
+		if len(v) == 0 {
+			return def
+		}
+		return v
+	}
+	kubeAPIServiceRange := defaultIfEmpty(in.Services.KubeAPI.ServiceClusterIPRange, cluster.DefaultServiceClusterIPRange)
+	kubeControllerServiceRange := defaultIfEmpty(in.Services.KubeController.ServiceClusterIPRange, cluster.DefaultServiceClusterIPRange)
+	clusterCIDR := defaultIfEmpty(in.Services.KubeController.ClusterCIDR, cluster.DefaultClusterCIDR)
+	clusterDNSServer := defaultIfEmpty(in.Services.Kubelet.ClusterDNSServer, cluster.DefaultClusterDNSService)
+
+	serviceCIDRs, err := parseRKEClusterCIDRs("kube_api service_cluster_ip_range", kubeAPIServiceRange)
+	if err != nil {
+		return err
+	}
+	kubeControllerServiceCIDRs, err := parseRKEClusterCIDRs("kube_controller service_cluster_ip_range", kubeControllerServiceRange)
+	if err != nil {
+		return err
+	}
+	equal := len(serviceCIDRs) == len(kubeControllerServiceCIDRs)
+	for i := 0; equal && i < len(serviceCIDRs); i++ {
+		equal = serviceCIDRs[i].String() == kubeControllerServiceCIDRs[i].String()
+	}
+	if !equal {
+		return fmt.Errorf("kube_api service_cluster_ip_range %s and kube_controller service_cluster_ip_range %s must be equal", kubeAPIServiceRange, kubeControllerServiceRange)
+	}
+
+	for _, serviceCIDR := range serviceCIDRs {
+		ones, bits := serviceCIDR.Mask.Size()
+		if bits-ones > rkeClusterServiceCIDRMaxHostBits {
+			return fmt.Errorf("service_cluster_ip_range %s is too large, mask must be /%d or longer for IPv4 and /%d or longer for IPv6", serviceCIDR, 32-rkeClusterServiceCIDRMaxHostBits, 128-rkeClusterServiceCIDRMaxHostBits)
+		}
+	}
+
+	clusterCIDRs, err := parseRKEClusterCIDRs("kube_controller cluster_cidr", clusterCIDR)
+	if err != nil {
+		return err
+	}
+	if len(serviceCIDRs) > len(clusterCIDRs) {
+		return fmt.Errorf("service_cluster_ip_range %s is dual-stack, kube_controller cluster_cidr %s must be dual-stack too", kubeAPIServiceRange, clusterCIDR)
+	}
+	dualStack := len(clusterCIDRs) > 1
+	if dualStack {
+		plugin := defaultIfEmpty(in.Network.Plugin, cluster.DefaultNetworkPlugin)
+		if !slices.Contains(cluster.IPv6CompatibleNetworkPlugins, plugin) {
+			return fmt.Errorf("network plugin %s doesn't support dual-stack, supported plugins: %v", plugin, cluster.IPv6CompatibleNetworkPlugins)
+		}
+	}
+	for _, podCIDR := range clusterCIDRs {
+		nodeMaskSize, err := rkeClusterNodeCIDRMaskSize(in.Services.KubeController.ExtraArgs, podCIDR.IP.To4() == nil, dualStack)
+		if err != nil {
+			return err
+		}
+		ones, _ := podCIDR.Mask.Size()
+		if ones > nodeMaskSize || nodeMaskSize-ones > rkeClusterNodeCIDRMaxMaskDiff {
+			return fmt.Errorf("kube_controller cluster_cidr %s mask must be between /%d and /%d, for node cidr mask size /%d", podCIDR, nodeMaskSize-rkeClusterNodeCIDRMaxMaskDiff, nodeMaskSize, nodeMaskSize)
+		}
+	}
+	for _, serviceCIDR := range serviceCIDRs {
+		for _, podCIDR := range clusterCIDRs {
+			if rkeClusterCIDRsOverlap(serviceCIDR, podCIDR) {
+				return fmt.Errorf("service_cluster_ip_range %s overlaps with kube_controller cluster_cidr %s", serviceCIDR, podCIDR)
+			}
+		}
+	}
+
+	for _, dnsServer := range strings.Split(clusterDNSServer, ",") {
+		ip := net.ParseIP(strings.TrimSpace(dnsServer))
+		if ip == nil {
+			return fmt.Errorf("kubelet cluster_dns_server %s is not a valid IP address", dnsServer)
+		}
+		if !rkeClusterCIDRsContain(serviceCIDRs, ip) {
+			return fmt.Errorf("kubelet cluster_dns_server %s must be inside service_cluster_ip_range %s", dnsServer, kubeAPIServiceRange)
+		}
+	}
+
+	for _, node := range in.Nodes {
+		for _, address := range []string{node.Address, node.InternalAddress} {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				continue
+			}
+			if rkeClusterCIDRsContain(serviceCIDRs, ip) {
+				return fmt.Errorf("node address %s must be outside of service_cluster_ip_range %s", address, kubeAPIServiceRange)
+			}
+			if rkeClusterCIDRsContain(clusterCIDRs, ip) {
+				return fmt.Errorf("node address %s must be outside of kube_controller cluster_cidr %s", address, clusterCIDR)
+			}
+		}
+	}
+
+	return nil
+}
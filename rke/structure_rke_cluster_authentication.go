@@ -1,16 +1,50 @@
 package rke
 
 import (
+	"slices"
+
 	rancher "github.com/rancher/rke/types"
 )
 
+func appendRKEClusterAuthenticationSAN(sans []string, san string) []string {
+	if len(san) == 0 || slices.Contains(sans, san) {
+		return sans
+	}
+	return append(sans, san)
+}
+
+func removeRKEClusterAuthenticationSAN(sans []string, san string) []string {
+	out := []string{}
+	for _, v := range sans {
+		if v != san {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // Flatteners
 
-func flattenRKEClusterAuthentication(in rancher.AuthnConfig) []interface{} {
+func flattenRKEClusterAuthentication(in rancher.AuthnConfig, p []interface{}, apiServerEndpoint string) []interface{} {
 	obj := make(map[string]interface{})
 
-	if len(in.SANs) > 0 {
-		obj["sans"] = toArrayInterface(in.SANs)
+	// SAN added by api_server_endpoint is only flattened if it was set on sans
+	sans := in.SANs
+	if len(apiServerEndpoint) > 0 {
+		san := rkeClusterAPIServerEndpointHost(apiServerEndpoint)
+		configured := false
+		if len(p) > 0 && p[0] != nil {
+			if v, ok := p[0].(map[string]interface{})["sans"].([]interface{}); ok {
+				configured = slices.Contains(toArrayString(v), san)
+			}
+		}
+		if !configured {
+			sans = removeRKEClusterAuthenticationSAN(sans, san)
+		}
+	}
+
+	if len(sans) > 0 {
+		obj["sans"] = toArrayInterface(sans)
 	}
 
 	if len(in.Strategy) > 0 {
@@ -28,17 +28,48 @@ func init() {
 func TestFlattenRKEClusterAuthentication(t *testing.T) {
 
 	cases := []struct {
-		Input          rancher.AuthnConfig
-		ExpectedOutput []interface{}
+		Input             rancher.AuthnConfig
+		Prior             []interface{}
+		APIServerEndpoint string
+		ExpectedOutput    []interface{}
 	}{
 		{
 			testRKEClusterAuthenticationConf,
+			nil,
+			"",
 			testRKEClusterAuthenticationInterface,
 		},
+		{
+			rancher.AuthnConfig{
+				SANs:     []string{"sans1", "sans2", "lb.example.com"},
+				Strategy: "strategy",
+			},
+			testRKEClusterAuthenticationInterface,
+			"lb.example.com:443",
+			testRKEClusterAuthenticationInterface,
+		},
+		{
+			rancher.AuthnConfig{
+				SANs:     []string{"sans1", "lb.example.com"},
+				Strategy: "strategy",
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"sans": []interface{}{"sans1", "lb.example.com"},
+				},
+			},
+			"lb.example.com",
+			[]interface{}{
+				map[string]interface{}{
+					"sans":     []interface{}{"sans1", "lb.example.com"},
+					"strategy": "strategy",
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
-		output := flattenRKEClusterAuthentication(tc.Input)
+		output := flattenRKEClusterAuthentication(tc.Input, tc.Prior, tc.APIServerEndpoint)
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
@@ -1,6 +1,8 @@
 package rke
 
 import (
+	"fmt"
+
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -45,6 +47,19 @@ func flattenRKEClusterBastionHost(in rancher.BastionHost) []interface{} {
 
 // Expanders
 
+func expandRKEClusterBastionHosts(p []interface{}) []rancher.BastionHost {
+	out := []rancher.BastionHost{}
+	if len(p) == 0 || p[0] == nil {
+		return out
+	}
+
+	for i := range p {
+		out = append(out, expandRKEClusterBastionHost(p[i:i+1]))
+	}
+
+	return out
+}
+
 func expandRKEClusterBastionHost(p []interface{}) rancher.BastionHost {
 	obj := rancher.BastionHost{}
 	if len(p) == 0 || p[0] == nil {
@@ -90,3 +105,16 @@ func expandRKEClusterBastionHost(p []interface{}) rancher.BastionHost {
 
 	return obj
 }
+
+// Validators
+
+// validateRKEClusterBastionHosts checks bastion host chain settings. Just the first bastion host is dialed from the
+// provider, so proxy env vars can only be ignored there
+func validateRKEClusterBastionHosts(in []rancher.BastionHost) error {
+	for i := 1; i < len(in); i++ {
+		if in[i].IgnoreProxyEnvVars {
+			return fmt.Errorf("bastion_host %s: ignore_proxy_env_vars is only supported on the first bastion host", in[i].Address)
+		}
+	}
+	return nil
+}
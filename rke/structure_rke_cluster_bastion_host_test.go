@@ -80,3 +80,74 @@ func TestExpandRKEClusterBastionHost(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandRKEClusterBastionHosts(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput []rancher.BastionHost
+	}{
+		{
+			[]interface{}{
+				testRKEClusterBastionHostInterface[0],
+				map[string]interface{}{
+					"address":               "bastion2.terraform.test",
+					"ignore_proxy_env_vars": false,
+					"port":                  "2222",
+					"user":                  "test2",
+				},
+			},
+			[]rancher.BastionHost{
+				testRKEClusterBastionHostConf,
+				{
+					Address: "bastion2.terraform.test",
+					Port:    "2222",
+					User:    "test2",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterBastionHosts(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestValidateRKEClusterBastionHosts(t *testing.T) {
+
+	cases := []struct {
+		Input         []rancher.BastionHost
+		ExpectedError bool
+	}{
+		{
+			[]rancher.BastionHost{testRKEClusterBastionHostConf},
+			false,
+		},
+		{
+			[]rancher.BastionHost{
+				testRKEClusterBastionHostConf,
+				{Address: "bastion2.terraform.test", User: "test2"},
+			},
+			false,
+		},
+		{
+			[]rancher.BastionHost{
+				{Address: "bastion.terraform.test", User: "test"},
+				{Address: "bastion2.terraform.test", User: "test2", IgnoreProxyEnvVars: true},
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterBastionHosts(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
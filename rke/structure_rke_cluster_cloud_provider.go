@@ -1,9 +1,22 @@
 package rke
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rancher/rke/cloudprovider"
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
+const (
+	rkeClusterCloudProviderRedactedValue    = "REDACTED"
+	rkeClusterCloudProviderSecretEnvPrefix  = "env://"
+	rkeClusterCloudProviderSecretFilePrefix = "file://"
+)
+
 // Flatteners
 
 func flattenRKEClusterCloudProvider(in rancher.CloudProvider, p []interface{}) []interface{} {
@@ -55,6 +68,32 @@ func flattenRKEClusterCloudProvider(in rancher.CloudProvider, p []interface{}) [
 	return []interface{}{obj}
 }
 
+// flattenRKEClusterRenderedCloudConfig returns the redacted cloud config file deployed by RKE or by external_cloud_provider
+func flattenRKEClusterRenderedCloudConfig(clusterYaml string, p []interface{}) (string, error) {
+	in := rancher.CloudProvider{}
+	if len(clusterYaml) > 0 {
+		config, err := cluster.ParseConfig(clusterYaml)
+		if err != nil {
+			return "", fmt.Errorf("parsing cluster_yaml: %v", err)
+		}
+		in = config.CloudProvider
+	}
+
+	if len(p) > 0 && p[0] != nil {
+		in = expandRKEClusterCloudProvider(p)
+		if v, ok := p[0].(map[string]interface{})["external_cloud_provider"].([]interface{}); ok && len(v) > 0 {
+			name, _, _ := expandRKEClusterCloudProviderExternal(v)
+			config, err := expandRKEClusterCloudProviderExternalConfig(p[0].(map[string]interface{}), name)
+			if err != nil {
+				return "", err
+			}
+			in = config
+		}
+	}
+
+	return renderRKEClusterCloudConfig(in, true)
+}
+
 // Expanders
 
 func expandRKEClusterCloudProvider(p []interface{}) rancher.CloudProvider {
@@ -64,6 +103,15 @@ func expandRKEClusterCloudProvider(p []interface{}) rancher.CloudProvider {
 	}
 	in := p[0].(map[string]interface{})
 
+	if v, ok := in["name"].(string); ok && len(v) > 0 {
+		obj.Name = v
+	}
+
+	// Typed providers config is deployed by external_cloud_provider, RKE would set them in-tree
+	if v, ok := in["external_cloud_provider"].([]interface{}); ok && len(v) > 0 {
+		return obj
+	}
+
 	if v, ok := in["aws_cloud_provider"].([]interface{}); ok && len(v) > 0 {
 		obj.AWSCloudProvider = expandRKEClusterCloudProviderAws(v)
 	}
@@ -76,10 +124,6 @@ func expandRKEClusterCloudProvider(p []interface{}) rancher.CloudProvider {
 		obj.CustomCloudProvider = v
 	}
 
-	if v, ok := in["name"].(string); ok && len(v) > 0 {
-		obj.Name = v
-	}
-
 	if v, ok := in["openstack_cloud_provider"].([]interface{}); ok && len(v) > 0 {
 		obj.OpenstackCloudProvider = expandRKEClusterCloudProviderOpenstack(v)
 	}
@@ -90,3 +134,197 @@ func expandRKEClusterCloudProvider(p []interface{}) rancher.CloudProvider {
 
 	return obj
 }
+
+// renderRKEClusterCloudConfig generates the cloud config file, like RKE does. Custom cloud provider config isn't rendered if redacted
+func renderRKEClusterCloudConfig(in rancher.CloudProvider, redact bool) (string, error) {
+	if err := validateRKEClusterCloudProvider(in); err != nil {
+		return "", err
+	}
+
+	if redact {
+		in = redactRKEClusterCloudProvider(in)
+	}
+
+	provider, err := cloudprovider.InitCloudProvider(in)
+	if err != nil {
+		return "", fmt.Errorf("initializing cloud provider: %v", err)
+	}
+	if provider == nil {
+		return "", nil
+	}
+
+	out, err := provider.GenerateCloudConfigFile()
+	if err != nil {
+		return "", fmt.Errorf("generating cloud config: %v", err)
+	}
+	return out, nil
+}
+
+func redactRKEClusterCloudProvider(in rancher.CloudProvider) rancher.CloudProvider {
+	obj := *in.DeepCopy()
+	obj.CustomCloudProvider = ""
+	mapRKEClusterCloudProviderSecrets(&obj, func(v string) (string, error) { // nolint
+		return rkeClusterCloudProviderRedactedValue, nil
+	})
+	return obj
+}
+
+// mapRKEClusterCloudProviderSecrets replaces every non empty cloud provider secret with f output
+func mapRKEClusterCloudProviderSecrets(in *rancher.CloudProvider, f func(string) (string, error)) error {
+	var err error
+	secret := func(v *string) {
+		if err != nil || len(*v) == 0 {
+			return
+		}
+		*v, err = f(*v)
+	}
+
+	if in.AzureCloudProvider != nil {
+		secret(&in.AzureCloudProvider.AADClientSecret)
+		secret(&in.AzureCloudProvider.AADClientCertPassword)
+	}
+
+	if in.OpenstackCloudProvider != nil {
+		secret(&in.OpenstackCloudProvider.Global.Password)
+	}
+
+	if in.VsphereCloudProvider != nil {
+		secret(&in.VsphereCloudProvider.Global.Password)
+		for name, vc := range in.VsphereCloudProvider.VirtualCenter {
+			secret(&vc.Password)
+			in.VsphereCloudProvider.VirtualCenter[name] = vc
+		}
+	}
+
+	return err
+}
+
+// resolveRKEClusterCloudProviderSecret returns the env:// or file:// secret reference value, or the secret itself if it isn't a reference
+func resolveRKEClusterCloudProviderSecret(in string) (string, error) {
+	switch {
+	case strings.HasPrefix(in, rkeClusterCloudProviderSecretEnvPrefix):
+		name := strings.TrimPrefix(in, rkeClusterCloudProviderSecretEnvPrefix)
+		v, ok := os.LookupEnv(name)
+		if !ok || len(v) == 0 {
+			return "", fmt.Errorf("secret env var %s is not set", name)
+		}
+		return v, nil
+	case strings.HasPrefix(in, rkeClusterCloudProviderSecretFilePrefix):
+		path := strings.TrimPrefix(in, rkeClusterCloudProviderSecretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading secret file: %v", err)
+		}
+		v := strings.TrimRight(string(data), "\r\n")
+		if len(v) == 0 {
+			return "", fmt.Errorf("secret file %s is empty", path)
+		}
+		return v, nil
+	}
+	return in, nil
+}
+
+// rkeClusterCloudProviderSecrets keeps cloud provider secret references resolved on the config passed to RKE,
+// to set them back on the RKE state saved to tf state
+type rkeClusterCloudProviderSecrets struct {
+	references     map[string]string
+	addons         string
+	resolvedAddons string
+}
+
+// expandRKEClusterCloudProviderSecrets resolves the cloud provider secret references on the config passed to RKE
+func expandRKEClusterCloudProviderSecrets(p []interface{}, in *rancher.RancherKubernetesEngineConfig) (*rkeClusterCloudProviderSecrets, error) {
+	obj := &rkeClusterCloudProviderSecrets{
+		references: map[string]string{},
+	}
+	if in == nil {
+		return obj, nil
+	}
+
+	err := mapRKEClusterCloudProviderSecrets(&in.CloudProvider, func(v string) (string, error) {
+		resolved, err := resolveRKEClusterCloudProviderSecret(v)
+		if err != nil {
+			return "", err
+		}
+		if resolved != v {
+			obj.references[resolved] = v
+		}
+		return resolved, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addons, err := renderRKEClusterCloudProviderExternalAddons(p, nil)
+	if err != nil {
+		return nil, err
+	}
+	resolvedAddons, err := renderRKEClusterCloudProviderExternalAddons(p, func(config *rancher.CloudProvider) error {
+		return mapRKEClusterCloudProviderSecrets(config, resolveRKEClusterCloudProviderSecret)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if addons != resolvedAddons && strings.HasSuffix(in.Addons, addons) {
+		in.Addons = strings.TrimSuffix(in.Addons, addons) + resolvedAddons
+		obj.addons = addons
+		obj.resolvedAddons = resolvedAddons
+	}
+
+	return obj, nil
+}
+
+// flattenRKEClusterCloudProviderSecrets sets the cloud provider secret references back on the RKE state
+func flattenRKEClusterCloudProviderSecrets(in *rkeClusterCloudProviderSecrets, rkeState string) (string, error) {
+	if in == nil || (len(in.references) == 0 && len(in.resolvedAddons) == 0) || len(rkeState) == 0 {
+		return rkeState, nil
+	}
+
+	state := &cluster.FullState{}
+	if err := json.Unmarshal([]byte(rkeState), state); err != nil {
+		return "", fmt.Errorf("parsing RKE state: %v", err)
+	}
+	for _, config := range []*rancher.RancherKubernetesEngineConfig{state.DesiredState.RancherKubernetesEngineConfig, state.CurrentState.RancherKubernetesEngineConfig} {
+		if config == nil {
+			continue
+		}
+		mapRKEClusterCloudProviderSecrets(&config.CloudProvider, func(v string) (string, error) { // nolint
+			if reference, ok := in.references[v]; ok {
+				return reference, nil
+			}
+			return v, nil
+		})
+		if len(in.resolvedAddons) > 0 && strings.HasSuffix(config.Addons, in.resolvedAddons) {
+			config.Addons = strings.TrimSuffix(config.Addons, in.resolvedAddons) + in.addons
+		}
+	}
+
+	out, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("writing RKE state: %v", err)
+	}
+	return string(out), nil
+}
+
+// Validators
+
+func validateRKEClusterCloudProvider(in rancher.CloudProvider) error {
+	if err := validateRKEClusterCloudProviderAzure(in.AzureCloudProvider); err != nil {
+		return err
+	}
+
+	return validateRKEClusterCloudProviderVsphere(in.VsphereCloudProvider)
+}
+
+func validateRKEClusterCloudProviderSecret(val interface{}, key string) (warns []string, errs []error) {
+	v, ok := val.(string)
+	if !ok {
+		return
+	}
+	for _, prefix := range []string{rkeClusterCloudProviderSecretEnvPrefix, rkeClusterCloudProviderSecretFilePrefix} {
+		if v == prefix {
+			errs = append(errs, fmt.Errorf("%q %s reference can't be empty", key, prefix))
+		}
+	}
+	return
+}
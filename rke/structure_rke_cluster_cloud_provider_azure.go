@@ -1,6 +1,8 @@
 package rke
 
 import (
+	"fmt"
+
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -247,3 +249,32 @@ func expandRKEClusterCloudProviderAzure(p []interface{}) *rancher.AzureCloudProv
 
 	return obj
 }
+
+// Validators
+
+func validateRKEClusterCloudProviderAzure(in *rancher.AzureCloudProvider) error {
+	if in == nil {
+		return nil
+	}
+
+	if len(in.AADClientCertPassword) > 0 && len(in.AADClientCertPath) == 0 {
+		return fmt.Errorf("azure_cloud_provider aad_client_cert_password requires aad_client_cert_path")
+	}
+
+	// Managed identity and service principal authentication are exclusive
+	if in.UseManagedIdentityExtension {
+		if len(in.AADClientSecret) > 0 || len(in.AADClientCertPath) > 0 {
+			return fmt.Errorf("azure_cloud_provider use_managed_identity_extension conflicts with aad_client_secret and aad_client_cert_path")
+		}
+		return nil
+	}
+
+	if len(in.AADClientID) == 0 {
+		return fmt.Errorf("azure_cloud_provider aad_client_id is required if use_managed_identity_extension is false")
+	}
+	if len(in.AADClientSecret) == 0 && len(in.AADClientCertPath) == 0 {
+		return fmt.Errorf("azure_cloud_provider aad_client_secret or aad_client_cert_path is required if use_managed_identity_extension is false")
+	}
+
+	return nil
+}
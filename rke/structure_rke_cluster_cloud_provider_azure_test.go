@@ -118,3 +118,68 @@ func TestExpandRKEClusterCloudProviderAzure(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRKEClusterCloudProviderAzure(t *testing.T) {
+
+	cases := []struct {
+		Input         *rancher.AzureCloudProvider
+		ExpectedError bool
+	}{
+		{
+			testRKEClusterCloudProviderAzureConf,
+			true,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				AADClientID:     "XXXXXXXX",
+				AADClientSecret: "XXXXXXXXXXXX",
+				SubscriptionID:  "YYYYYYYY",
+				TenantID:        "ZZZZZZZZ",
+			},
+			false,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				SubscriptionID:              "YYYYYYYY",
+				TenantID:                    "ZZZZZZZZ",
+				UseManagedIdentityExtension: true,
+			},
+			false,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				AADClientID:                 "XXXXXXXX",
+				AADClientSecret:             "XXXXXXXXXXXX",
+				UseManagedIdentityExtension: true,
+			},
+			true,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				AADClientID: "XXXXXXXX",
+			},
+			true,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				AADClientSecret: "XXXXXXXXXXXX",
+			},
+			true,
+		},
+		{
+			&rancher.AzureCloudProvider{
+				AADClientID:           "XXXXXXXX",
+				AADClientSecret:       "XXXXXXXXXXXX",
+				AADClientCertPassword: "password",
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterCloudProviderAzure(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package rke
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rancher "github.com/rancher/rke/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type rkeClusterCloudProviderExternal struct {
+	command string
+	image   string
+	args    map[string]string
+}
+
+var rkeClusterCloudProviderExternals = map[string]rkeClusterCloudProviderExternal{
+	rkeClusterCloudProviderAwsName: {
+		command: "/bin/aws-cloud-controller-manager",
+		image:   rkeClusterCloudProviderExternalAwsImage,
+		args: map[string]string{
+			"configure-cloud-routes": "false",
+		},
+	},
+	rkeClusterCloudProviderAzureName: {
+		command: "cloud-controller-manager",
+		image:   rkeClusterCloudProviderExternalAzureImage,
+		args: map[string]string{
+			"configure-cloud-routes": "false",
+		},
+	},
+	rkeClusterCloudProviderOpenstackName: {
+		command: "/bin/openstack-cloud-controller-manager",
+		image:   rkeClusterCloudProviderExternalOpenstackImage,
+	},
+	rkeClusterCloudProviderVsphereName: {
+		command: "/bin/vsphere-cloud-controller-manager",
+		image:   rkeClusterCloudProviderExternalVsphereImage,
+	},
+}
+
+// Flatteners
+
+func flattenRKEClusterCloudProviderExternalAddons(addons string, p []interface{}) (string, error) {
+	externalAddons, err := expandRKEClusterCloudProviderExternalAddons(p)
+	if err != nil || len(externalAddons) == 0 {
+		return addons, err
+	}
+
+	addons = strings.TrimSuffix(addons, externalAddons)
+	return strings.TrimSuffix(addons, rkeClusterAddonsSeparator), nil
+}
+
+// Expanders
+
+func expandRKEClusterCloudProviderExternal(p []interface{}) (string, map[string]string, string) {
+	if len(p) == 0 || p[0] == nil {
+		return "", nil, ""
+	}
+	in := p[0].(map[string]interface{})
+
+	var name, image string
+	var extraArgs map[string]string
+
+	if v, ok := in["name"].(string); ok && len(v) > 0 {
+		name = v
+	}
+
+	if v, ok := in["extra_args"].(map[string]interface{}); ok && len(v) > 0 {
+		extraArgs = toMapString(v)
+	}
+
+	if v, ok := in["image"].(string); ok && len(v) > 0 {
+		image = v
+	}
+
+	return name, extraArgs, image
+}
+
+// expandRKEClusterCloudProviderExternalAddons renders the cloud controller manager manifests for the cloud_provider external_cloud_provider block
+func expandRKEClusterCloudProviderExternalAddons(p []interface{}) (string, error) {
+	return renderRKEClusterCloudProviderExternalAddons(p, nil)
+}
+
+// renderRKEClusterCloudProviderExternalAddons renders the cloud controller manager manifests, applying secrets to the cloud config if not nil
+func renderRKEClusterCloudProviderExternalAddons(p []interface{}, secrets func(*rancher.CloudProvider) error) (string, error) {
+	if len(p) == 0 || p[0] == nil {
+		return "", nil
+	}
+	in := p[0].(map[string]interface{})
+
+	v, ok := in["external_cloud_provider"].([]interface{})
+	if !ok || len(v) == 0 {
+		return "", nil
+	}
+	name, extraArgs, image := expandRKEClusterCloudProviderExternal(v)
+
+	if cloudProviderName, ok := in["name"].(string); !ok || cloudProviderName != rkeClusterCloudProviderExternalName {
+		return "", fmt.Errorf("external_cloud_provider requires cloud_provider name %q, got %q", rkeClusterCloudProviderExternalName, cloudProviderName)
+	}
+
+	external, ok := rkeClusterCloudProviderExternals[name]
+	if !ok {
+		return "", fmt.Errorf("external_cloud_provider name %q is not supported", name)
+	}
+	if len(image) == 0 {
+		image = external.image
+	}
+
+	config, err := expandRKEClusterCloudProviderExternalConfig(in, name)
+	if err != nil {
+		return "", err
+	}
+	if secrets != nil {
+		if err := secrets(&config); err != nil {
+			return "", fmt.Errorf("external_cloud_provider %s secrets: %v", name, err)
+		}
+	}
+	cloudConfig, err := renderRKEClusterCloudConfig(config, false)
+	if err != nil {
+		return "", fmt.Errorf("external_cloud_provider %s cloud config: %v", name, err)
+	}
+
+	args := map[string]string{
+		"cloud-provider":                  name,
+		"leader-elect":                    "true",
+		"use-service-account-credentials": "true",
+	}
+	if len(cloudConfig) > 0 {
+		args["cloud-config"] = rkeClusterCloudProviderExternalConfigPath + "/" + rkeClusterCloudProviderExternalSecretKey
+	}
+	for k, v := range external.args {
+		args[k] = v
+	}
+	for k, v := range extraArgs {
+		args[k] = v
+	}
+
+	objects := []interface{}{
+		newRKEClusterCloudProviderExternalServiceAccount(),
+		newRKEClusterCloudProviderExternalClusterRole(),
+		newRKEClusterCloudProviderExternalClusterRoleBinding(),
+	}
+	if len(cloudConfig) > 0 {
+		objects = append(objects, newRKEClusterCloudProviderExternalSecret(cloudConfig))
+	}
+	objects = append(objects, newRKEClusterCloudProviderExternalDaemonSet(external.command, image, args, len(cloudConfig) > 0))
+
+	docs := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		doc, err := interfaceToGhodssyaml(obj)
+		if err != nil {
+			return "", fmt.Errorf("rendering external_cloud_provider manifests: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return strings.Join(docs, "---\n"), nil
+}
+
+// expandRKEClusterCloudProviderExternalConfig returns the <name>_cloud_provider block config for the cloud controller manager
+func expandRKEClusterCloudProviderExternalConfig(in map[string]interface{}, name string) (rancher.CloudProvider, error) {
+	obj := rancher.CloudProvider{}
+	configKey := name + "_cloud_provider"
+	v, ok := in[configKey].([]interface{})
+	if !ok || len(v) == 0 || v[0] == nil {
+		// AWS cloud controller manager may use instance profile credentials
+		if name == rkeClusterCloudProviderAwsName {
+			return obj, nil
+		}
+		return obj, fmt.Errorf("external_cloud_provider name %q requires %s block", name, configKey)
+	}
+
+	switch name {
+	case rkeClusterCloudProviderAwsName:
+		obj.AWSCloudProvider = expandRKEClusterCloudProviderAws(v)
+	case rkeClusterCloudProviderAzureName:
+		obj.AzureCloudProvider = expandRKEClusterCloudProviderAzure(v)
+	case rkeClusterCloudProviderOpenstackName:
+		obj.OpenstackCloudProvider = expandRKEClusterCloudProviderOpenstack(v)
+	case rkeClusterCloudProviderVsphereName:
+		obj.VsphereCloudProvider = expandRKEClusterCloudProviderVsphere(v)
+	}
+
+	return obj, nil
+}
+
+func newRKEClusterCloudProviderExternalObjectMeta(namespaced bool) metav1.ObjectMeta {
+	obj := metav1.ObjectMeta{
+		Name: rkeClusterCloudProviderExternalResourceName,
+		Labels: map[string]string{
+			"app.kubernetes.io/name":       rkeClusterCloudProviderExternalResourceName,
+			"app.kubernetes.io/managed-by": "terraform-provider-rke",
+		},
+	}
+	if namespaced {
+		obj.Namespace = rkeClusterCloudProviderExternalNamespace
+	}
+	return obj
+}
+
+func newRKEClusterCloudProviderExternalServiceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: newRKEClusterCloudProviderExternalObjectMeta(true),
+	}
+}
+
+func newRKEClusterCloudProviderExternalClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+		ObjectMeta: newRKEClusterCloudProviderExternalObjectMeta(false),
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch", "update"}},
+			{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"*"}},
+			{APIGroups: []string{""}, Resources: []string{"nodes/status"}, Verbs: []string{"patch"}},
+			{APIGroups: []string{""}, Resources: []string{"services", "services/status"}, Verbs: []string{"get", "list", "patch", "update", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"create", "get", "list", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"serviceaccounts/token"}, Verbs: []string{"create"}},
+			{APIGroups: []string{""}, Resources: []string{"persistentvolumes"}, Verbs: []string{"get", "list", "patch", "update", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"endpoints", "configmaps", "secrets"}, Verbs: []string{"create", "get", "list", "update", "watch"}},
+			{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"create", "get", "list", "update", "watch"}},
+		},
+	}
+}
+
+func newRKEClusterCloudProviderExternalClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+		ObjectMeta: newRKEClusterCloudProviderExternalObjectMeta(false),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     rkeClusterCloudProviderExternalResourceName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      rkeClusterCloudProviderExternalResourceName,
+				Namespace: rkeClusterCloudProviderExternalNamespace,
+			},
+		},
+	}
+}
+
+func newRKEClusterCloudProviderExternalSecret(cloudConfig string) *corev1.Secret {
+	obj := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: newRKEClusterCloudProviderExternalObjectMeta(true),
+		Type:       corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			rkeClusterCloudProviderExternalSecretKey: cloudConfig,
+		},
+	}
+	obj.Name = rkeClusterCloudProviderExternalSecretName
+	return obj
+}
+
+func newRKEClusterCloudProviderExternalDaemonSet(command, image string, args map[string]string, cloudConfig bool) *appsv1.DaemonSet {
+	labels := map[string]string{
+		"app.kubernetes.io/name": rkeClusterCloudProviderExternalResourceName,
+	}
+
+	argKeys := make([]string, 0, len(args))
+	for k := range args {
+		argKeys = append(argKeys, k)
+	}
+	sort.Strings(argKeys)
+	containerArgs := make([]string, 0, len(argKeys))
+	for _, k := range argKeys {
+		containerArgs = append(containerArgs, fmt.Sprintf("--%s=%s", k, args[k]))
+	}
+
+	container := corev1.Container{
+		Name:    rkeClusterCloudProviderExternalResourceName,
+		Image:   image,
+		Command: []string{command},
+		Args:    containerArgs,
+	}
+	podSpec := corev1.PodSpec{
+		ServiceAccountName: rkeClusterCloudProviderExternalResourceName,
+		HostNetwork:        true,
+		DNSPolicy:          corev1.DNSClusterFirstWithHostNet,
+		PriorityClassName:  "system-cluster-critical",
+		NodeSelector: map[string]string{
+			"node-role.kubernetes.io/controlplane": "true",
+		},
+		// Nodes are tainted as uninitialized until the cloud controller manager is running
+		Tolerations: []corev1.Toleration{
+			{Operator: corev1.TolerationOpExists},
+		},
+	}
+	if cloudConfig {
+		container.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      rkeClusterCloudProviderExternalSecretName,
+				MountPath: rkeClusterCloudProviderExternalConfigPath,
+				ReadOnly:  true,
+			},
+		}
+		podSpec.Volumes = []corev1.Volume{
+			{
+				Name: rkeClusterCloudProviderExternalSecretName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: rkeClusterCloudProviderExternalSecretName,
+					},
+				},
+			},
+		}
+	}
+	podSpec.Containers = []corev1.Container{container}
+
+	return &appsv1.DaemonSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"},
+		ObjectMeta: newRKEClusterCloudProviderExternalObjectMeta(true),
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package rke
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	rancher "github.com/rancher/rke/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// testRKEClusterCloudProviderExternalInterface isn't set on init, it depends on openstack test vars
+func testRKEClusterCloudProviderExternalInterface() []interface{} {
+	return []interface{}{
+		map[string]interface{}{
+			"name": "external",
+			"external_cloud_provider": []interface{}{
+				map[string]interface{}{
+					"name": "openstack",
+					"extra_args": map[string]interface{}{
+						"v": "4",
+					},
+				},
+			},
+			"openstack_cloud_provider": testRKEClusterCloudProviderOpenstackInterface,
+		},
+	}
+}
+
+func TestExpandRKEClusterCloudProviderExternalAddons(t *testing.T) {
+	output, err := expandRKEClusterCloudProviderExternalAddons(testRKEClusterCloudProviderExternalInterface())
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+
+	docs := strings.Split(output, "---\n")
+	kinds := []string{}
+	for _, doc := range docs {
+		obj, err := ghodssyamlToMapInterface(doc)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander output: %#v", err)
+		}
+		kinds = append(kinds, obj["kind"].(string))
+	}
+	expectedKinds := []string{"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Secret", "DaemonSet"}
+	if !reflect.DeepEqual(kinds, expectedKinds) {
+		t.Fatalf("Unexpected output kinds from expander.\nExpected: %#v\nGiven:    %#v", expectedKinds, kinds)
+	}
+
+	secret := &corev1.Secret{}
+	if err := ghodssyamlToInterface(docs[3], secret); err != nil {
+		t.Fatalf("[ERROR] on expander output: %#v", err)
+	}
+	if !strings.Contains(secret.StringData[rkeClusterCloudProviderExternalSecretKey], "[Global]") {
+		t.Fatalf("Unexpected cloud config from expander: %#v", secret.StringData)
+	}
+
+	daemonSet := &appsv1.DaemonSet{}
+	if err := ghodssyamlToInterface(docs[4], daemonSet); err != nil {
+		t.Fatalf("[ERROR] on expander output: %#v", err)
+	}
+	container := daemonSet.Spec.Template.Spec.Containers[0]
+	expectedArgs := []string{
+		"--cloud-config=/etc/kubernetes/cloud-controller-manager/cloud-config",
+		"--cloud-provider=openstack",
+		"--leader-elect=true",
+		"--use-service-account-credentials=true",
+		"--v=4",
+	}
+	if container.Image != rkeClusterCloudProviderExternalOpenstackImage || !reflect.DeepEqual(container.Args, expectedArgs) {
+		t.Fatalf("Unexpected container from expander.\nExpected: %#v %#v\nGiven:    %#v %#v", rkeClusterCloudProviderExternalOpenstackImage, expectedArgs, container.Image, container.Args)
+	}
+}
+
+func TestExpandRKEClusterCloudProviderExternalAddonsErrors(t *testing.T) {
+
+	cases := []struct {
+		Input         []interface{}
+		ExpectedError bool
+	}{
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name": "external",
+					"external_cloud_provider": []interface{}{
+						map[string]interface{}{
+							"name": "aws",
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name": "openstack",
+					"external_cloud_provider": []interface{}{
+						map[string]interface{}{
+							"name": "openstack",
+						},
+					},
+					"openstack_cloud_provider": testRKEClusterCloudProviderOpenstackInterface,
+				},
+			},
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name": "external",
+					"external_cloud_provider": []interface{}{
+						map[string]interface{}{
+							"name": "vsphere",
+						},
+					},
+				},
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := expandRKEClusterCloudProviderExternalAddons(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected error from expander on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
+
+func TestFlattenRKEClusterCloudProviderExternalAddons(t *testing.T) {
+	externalAddons, err := expandRKEClusterCloudProviderExternalAddons(testRKEClusterCloudProviderExternalInterface())
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+
+	for _, addons := range []string{"", "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n"} {
+		output, err := flattenRKEClusterCloudProviderExternalAddons(joinRKEClusterAddons(addons, externalAddons), testRKEClusterCloudProviderExternalInterface())
+		if err != nil {
+			t.Fatalf("[ERROR] on flattener: %#v", err)
+		}
+		if output != addons {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v", addons, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterCloudProviderExternal(t *testing.T) {
+	output := expandRKEClusterCloudProvider(testRKEClusterCloudProviderExternalInterface())
+	expectedOutput := rancher.CloudProvider{Name: "external"}
+	if !reflect.DeepEqual(output, expectedOutput) {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v", expectedOutput, output)
+	}
+}
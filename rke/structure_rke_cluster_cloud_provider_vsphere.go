@@ -1,6 +1,10 @@
 package rke
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -354,3 +358,52 @@ func expandRKEClusterCloudProviderVsphere(p []interface{}) *rancher.VsphereCloud
 
 	return obj
 }
+
+// Validators
+
+func validateRKEClusterCloudProviderVsphere(in *rancher.VsphereCloudProvider) error {
+	if in == nil {
+		return nil
+	}
+
+	if len(in.VirtualCenter) == 0 {
+		return fmt.Errorf("vsphere_cloud_provider virtual_center is required")
+	}
+
+	// virtual_center user, password and datacenters default to global ones
+	for name, vc := range in.VirtualCenter {
+		if len(vc.User) == 0 && len(in.Global.User) == 0 {
+			return fmt.Errorf("vsphere_cloud_provider virtual_center %q user is required if global user isn't set", name)
+		}
+		if len(vc.Password) == 0 && len(in.Global.Password) == 0 {
+			return fmt.Errorf("vsphere_cloud_provider virtual_center %q password is required if global password isn't set", name)
+		}
+		if len(vc.Datacenters) == 0 && len(in.Global.Datacenters) == 0 {
+			return fmt.Errorf("vsphere_cloud_provider virtual_center %q datacenters is required if global datacenters isn't set", name)
+		}
+	}
+
+	vc, ok := in.VirtualCenter[in.Workspace.VCenterIP]
+	if !ok {
+		return fmt.Errorf("vsphere_cloud_provider workspace server %q must be a virtual_center name", in.Workspace.VCenterIP)
+	}
+	datacenters := vc.Datacenters
+	if len(datacenters) == 0 {
+		datacenters = in.Global.Datacenters
+	}
+	if !slices.Contains(splitRKEClusterCloudProviderVsphereDatacenters(datacenters), strings.Trim(in.Workspace.Datacenter, "/")) {
+		return fmt.Errorf("vsphere_cloud_provider workspace datacenter %q must be one of virtual_center %q datacenters %q", in.Workspace.Datacenter, in.Workspace.VCenterIP, datacenters)
+	}
+
+	return nil
+}
+
+func splitRKEClusterCloudProviderVsphereDatacenters(in string) []string {
+	out := []string{}
+	for _, v := range strings.Split(in, ",") {
+		if v = strings.Trim(strings.TrimSpace(v), "/"); len(v) > 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -361,3 +361,68 @@ func TestExpandRKEClusterCloudProviderVsphere(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRKEClusterCloudProviderVsphere(t *testing.T) {
+	newVsphere := func(global rancher.GlobalVsphereOpts, vc rancher.VirtualCenterConfig, server, datacenter string) *rancher.VsphereCloudProvider {
+		return &rancher.VsphereCloudProvider{
+			Global: global,
+			VirtualCenter: map[string]rancher.VirtualCenterConfig{
+				"vcenter": vc,
+			},
+			Workspace: rancher.WorkspaceVsphereOpts{
+				Datacenter: datacenter,
+				VCenterIP:  server,
+			},
+		}
+	}
+	vc := rancher.VirtualCenterConfig{
+		Datacenters: "dc1, dc2",
+		Password:    "YYYYYYYY",
+		User:        "user",
+	}
+
+	cases := []struct {
+		Input         *rancher.VsphereCloudProvider
+		ExpectedError bool
+	}{
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, vc, "vcenter", "dc2"),
+			false,
+		},
+		{
+			newVsphere(testRKEClusterCloudProviderVsphereGlobalConf, rancher.VirtualCenterConfig{}, "vcenter", "/auth.terraform.test"),
+			false,
+		},
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, rancher.VirtualCenterConfig{Datacenters: "dc1", User: "user"}, "vcenter", "dc1"),
+			true,
+		},
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, rancher.VirtualCenterConfig{Datacenters: "dc1", Password: "YYYYYYYY"}, "vcenter", "dc1"),
+			true,
+		},
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, rancher.VirtualCenterConfig{Password: "YYYYYYYY", User: "user"}, "vcenter", "dc1"),
+			true,
+		},
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, vc, "other", "dc1"),
+			true,
+		},
+		{
+			newVsphere(rancher.GlobalVsphereOpts{}, vc, "vcenter", "dc3"),
+			true,
+		},
+		{
+			&rancher.VsphereCloudProvider{},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterCloudProviderVsphere(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
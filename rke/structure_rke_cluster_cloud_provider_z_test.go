@@ -1,9 +1,14 @@
 package rke
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -126,3 +131,166 @@ func TestExpandRKEClusterCloudProvider(t *testing.T) {
 		}
 	}
 }
+
+func TestFlattenRKEClusterRenderedCloudConfig(t *testing.T) {
+	openstack := []interface{}{
+		map[string]interface{}{
+			"name":                     "openstack",
+			"openstack_cloud_provider": testRKEClusterCloudProviderOpenstackInterface,
+		},
+	}
+	output, err := flattenRKEClusterRenderedCloudConfig("", openstack)
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	password := testRKEClusterCloudProviderOpenstackConf.Global.Password
+	if !strings.Contains(output, "[Global]") || !strings.Contains(output, rkeClusterCloudProviderRedactedValue) || strings.Contains(output, password) {
+		t.Fatalf("Unexpected output from flattener:\n%s", output)
+	}
+	if testRKEClusterCloudProviderOpenstackConf.Global.Password != password {
+		t.Fatalf("Unexpected input modified by flattener: %#v", testRKEClusterCloudProviderOpenstackConf.Global)
+	}
+
+	clusterYaml := "cloud_provider:\n  name: azure\n  azureCloudProvider:\n    aadClientId: XXXXXXXX\n    aadClientSecret: secret\n    subscriptionId: YYYYYYYY\n    tenantId: ZZZZZZZZ\n"
+	output, err = flattenRKEClusterRenderedCloudConfig(clusterYaml, nil)
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	if !strings.Contains(output, "XXXXXXXX") || !strings.Contains(output, rkeClusterCloudProviderRedactedValue) || strings.Contains(output, "secret") {
+		t.Fatalf("Unexpected output from flattener:\n%s", output)
+	}
+
+	output, err = flattenRKEClusterRenderedCloudConfig("", testRKEClusterCloudProviderInterface)
+	if err != nil || len(output) > 0 {
+		t.Fatalf("Unexpected output from flattener on custom cloud provider: %#v %v", output, err)
+	}
+
+	invalid := []interface{}{
+		map[string]interface{}{
+			"name": "azure",
+			"azure_cloud_provider": []interface{}{
+				map[string]interface{}{
+					"subscription_id": "YYYYYYYY",
+					"tenant_id":       "ZZZZZZZZ",
+				},
+			},
+		},
+	}
+	if _, err := flattenRKEClusterRenderedCloudConfig("", invalid); err == nil {
+		t.Fatalf("Expected error from flattener on input %#v", invalid)
+	}
+}
+
+func TestResolveRKEClusterCloudProviderSecret(t *testing.T) {
+	t.Setenv("TEST_RKE_CLOUD_PROVIDER_SECRET", "env_secret")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("file_secret\n"), 0600); err != nil {
+		t.Fatalf("[ERROR] writing secret file: %v", err)
+	}
+
+	cases := []struct {
+		Input          string
+		ExpectedOutput string
+		ExpectedError  bool
+	}{
+		{"secret", "secret", false},
+		{"env://TEST_RKE_CLOUD_PROVIDER_SECRET", "env_secret", false},
+		{"file://" + secretFile, "file_secret", false},
+		{"env://TEST_RKE_CLOUD_PROVIDER_SECRET_UNSET", "", true},
+		{"file://" + secretFile + ".missing", "", true},
+	}
+
+	for _, tc := range cases {
+		output, err := resolveRKEClusterCloudProviderSecret(tc.Input)
+		if (err != nil) != tc.ExpectedError || output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output on input %#v\nExpected: %#v %t\nGiven:    %#v %v", tc.Input, tc.ExpectedOutput, tc.ExpectedError, output, err)
+		}
+	}
+}
+
+func TestExpandRKEClusterCloudProviderSecrets(t *testing.T) {
+	t.Setenv("TEST_RKE_CLOUD_PROVIDER_SECRET", "env_secret")
+	p := []interface{}{
+		map[string]interface{}{
+			"name": "external",
+			"external_cloud_provider": []interface{}{
+				map[string]interface{}{
+					"name": "azure",
+				},
+			},
+			"azure_cloud_provider": []interface{}{
+				map[string]interface{}{
+					"aad_client_id":     "XXXXXXXX",
+					"aad_client_secret": "env://TEST_RKE_CLOUD_PROVIDER_SECRET",
+					"subscription_id":   "YYYYYYYY",
+					"tenant_id":         "ZZZZZZZZ",
+				},
+			},
+		},
+	}
+	addons, err := expandRKEClusterCloudProviderExternalAddons(p)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	config := &rancher.RancherKubernetesEngineConfig{
+		Addons: addons,
+		CloudProvider: rancher.CloudProvider{
+			Name: "azure",
+			AzureCloudProvider: &rancher.AzureCloudProvider{
+				AADClientID:     "XXXXXXXX",
+				AADClientSecret: "env://TEST_RKE_CLOUD_PROVIDER_SECRET",
+			},
+		},
+	}
+
+	secrets, err := expandRKEClusterCloudProviderSecrets(p, config)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if config.CloudProvider.AzureCloudProvider.AADClientSecret != "env_secret" || !strings.Contains(config.Addons, "env_secret") || config.Addons == addons {
+		t.Fatalf("Unexpected resolved config from expander: %#v", config)
+	}
+
+	stateJSON, err := json.MarshalIndent(&cluster.FullState{
+		DesiredState: cluster.State{RancherKubernetesEngineConfig: config},
+		CurrentState: cluster.State{RancherKubernetesEngineConfig: config.DeepCopy()},
+	}, "", "  ")
+	if err != nil {
+		t.Fatalf("[ERROR] writing state: %v", err)
+	}
+	output, err := flattenRKEClusterCloudProviderSecrets(secrets, string(stateJSON))
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	if strings.Contains(output, "env_secret") || !strings.Contains(output, "env://TEST_RKE_CLOUD_PROVIDER_SECRET") {
+		t.Fatalf("Unexpected output from flattener:\n%s", output)
+	}
+	state := &cluster.FullState{}
+	if err := json.Unmarshal([]byte(output), state); err != nil {
+		t.Fatalf("[ERROR] reading state: %v", err)
+	}
+	if state.DesiredState.RancherKubernetesEngineConfig.Addons != addons || state.CurrentState.RancherKubernetesEngineConfig.Addons != addons {
+		t.Fatalf("Unexpected addons from flattener:\n%s", output)
+	}
+}
+
+func TestValidateRKEClusterCloudProviderSecret(t *testing.T) {
+
+	cases := []struct {
+		Input         string
+		ExpectedError bool
+	}{
+		{"secret", false},
+		{"env://SECRET", false},
+		{"file:///run/secrets/secret", false},
+		{"env://", true},
+		{"file://", true},
+	}
+
+	for _, tc := range cases {
+		_, errs := validateRKEClusterCloudProviderSecret(tc.Input, "password")
+		if (len(errs) > 0) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, errs)
+		}
+	}
+}
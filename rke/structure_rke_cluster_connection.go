@@ -0,0 +1,159 @@
+package rke
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Expanders
+
+func expandRKEClusterConnectionJumpHosts(p []interface{}) []rkeJumpHost {
+	out := []rkeJumpHost{}
+	if len(p) == 0 || p[0] == nil {
+		return out
+	}
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+		obj := rkeJumpHost{}
+
+		if v, ok := in["address"].(string); ok && len(v) > 0 {
+			obj.Address = v
+		}
+
+		if v, ok := in["host_key_fingerprint"].(string); ok && len(v) > 0 {
+			obj.HostKey.Fingerprint = v
+		}
+
+		if v, ok := in["known_hosts"].(string); ok && len(v) > 0 {
+			obj.HostKey.KnownHosts = v
+		}
+
+		if v, ok := in["port"].(string); ok && len(v) > 0 {
+			obj.Port = v
+		}
+
+		if v, ok := in["ssh_agent_auth"].(bool); ok {
+			obj.SSHAgentAuth = v
+		}
+
+		if v, ok := in["ssh_cert"].(string); ok && len(v) > 0 {
+			obj.SSHCert = v
+		}
+
+		if v, ok := in["ssh_cert_path"].(string); ok && len(v) > 0 {
+			obj.SSHCertPath = v
+		}
+
+		if v, ok := in["ssh_key"].(string); ok && len(v) > 0 {
+			obj.SSHKey = v
+		}
+
+		if v, ok := in["ssh_key_path"].(string); ok && len(v) > 0 {
+			obj.SSHKeyPath = v
+		}
+
+		if v, ok := in["user"].(string); ok && len(v) > 0 {
+			obj.User = v
+		}
+
+		out = append(out, obj)
+	}
+
+	return out
+}
+
+func expandRKEClusterConnection(p []interface{}) (*rkeConnection, error) {
+	if len(p) == 0 || p[0] == nil {
+		return nil, nil
+	}
+	in := p[0].(map[string]interface{})
+	obj := &rkeConnection{
+		Type: rkeClusterConnectionTypeSSH,
+	}
+
+	if v, ok := in["type"].(string); ok && len(v) > 0 {
+		obj.Type = v
+	}
+
+	if v, ok := in["docker_ca_cert"].(string); ok && len(v) > 0 {
+		obj.DockerCACert = v
+	}
+
+	if v, ok := in["docker_client_cert"].(string); ok && len(v) > 0 {
+		obj.DockerClientCert = v
+	}
+
+	if v, ok := in["docker_client_key"].(string); ok && len(v) > 0 {
+		obj.DockerClientKey = v
+	}
+
+	if v, ok := in["docker_host"].(string); ok && len(v) > 0 {
+		obj.DockerHost = v
+	}
+
+	if v, ok := in["jump_host"].([]interface{}); ok && len(v) > 0 {
+		obj.JumpHosts = expandRKEClusterConnectionJumpHosts(v)
+	}
+
+	if v, ok := in["proxy_command"].(string); ok && len(v) > 0 {
+		obj.ProxyCommand = v
+	}
+
+	switch obj.Type {
+	case rkeClusterConnectionTypeDocker:
+		if len(obj.DockerHost) == 0 {
+			return nil, fmt.Errorf("docker_host is required for %s connection type", obj.Type)
+		}
+		u, err := url.Parse(obj.DockerHost)
+		if err != nil || (u.Scheme == "tcp" && len(u.Host) == 0) || (u.Scheme == "unix" && len(u.Path) == 0) || (u.Scheme != "tcp" && u.Scheme != "unix") {
+			return nil, fmt.Errorf("docker_host %s must be tcp://<host>:<port> or unix://<path>", obj.DockerHost)
+		}
+		if len(obj.DockerClientCert) > 0 && len(obj.DockerClientKey) == 0 {
+			return nil, fmt.Errorf("docker_client_key is required if docker_client_cert is set")
+		}
+		if len(obj.JumpHosts) > 0 || len(obj.ProxyCommand) > 0 {
+			return nil, fmt.Errorf("jump_host and proxy_command are not supported for %s connection type", obj.Type)
+		}
+	case rkeClusterConnectionTypeProxyCommand:
+		if len(obj.ProxyCommand) == 0 {
+			return nil, fmt.Errorf("proxy_command is required for %s connection type", obj.Type)
+		}
+	default:
+		if len(obj.ProxyCommand) > 0 {
+			return nil, fmt.Errorf("proxy_command is only supported for %s connection type", rkeClusterConnectionTypeProxyCommand)
+		}
+	}
+
+	if len(obj.DockerHost) > 0 && obj.Type != rkeClusterConnectionTypeDocker {
+		return nil, fmt.Errorf("docker_host is only supported for %s connection type", rkeClusterConnectionTypeDocker)
+	}
+
+	return obj, nil
+}
+
+func expandRKEClusterNodesConnections(p []interface{}) (map[string]*rkeConnection, error) {
+	out := map[string]*rkeConnection{}
+	if len(p) == 0 || p[0] == nil {
+		return out, nil
+	}
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+
+		v, ok := in["connection"].([]interface{})
+		if !ok || len(v) == 0 {
+			continue
+		}
+		address, _ := in["address"].(string)
+		obj, err := expandRKEClusterConnection(v)
+		if err != nil {
+			return nil, fmt.Errorf("Failed expanding connection for node [%s]: %v", address, err)
+		}
+		if obj != nil {
+			out[address] = obj
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,197 @@
+package rke
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	testRKEClusterConnectionJumpHostsConf      []rkeJumpHost
+	testRKEClusterConnectionJumpHostsInterface []interface{}
+	testRKEClusterConnectionConf               *rkeConnection
+	testRKEClusterConnectionInterface          []interface{}
+	testRKEClusterConnectionDockerConf         *rkeConnection
+	testRKEClusterConnectionDockerInterface    []interface{}
+)
+
+func init() {
+	testRKEClusterConnectionJumpHostsConf = []rkeJumpHost{
+		{
+			Address:      "jump.terraform.test",
+			Port:         "2222",
+			User:         "test",
+			SSHAgentAuth: true,
+			SSHCert:      "XXXXXXXX",
+			SSHCertPath:  "/home/user/.ssh",
+			SSHKey:       "XXXXXXXX",
+			SSHKeyPath:   "/home/user/.ssh",
+			HostKey: rkeHostKey{
+				Fingerprint: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+				KnownHosts:  "jump.terraform.test ssh-ed25519 XXXXXXXX",
+			},
+		},
+	}
+	testRKEClusterConnectionJumpHostsInterface = []interface{}{
+		map[string]interface{}{
+			"address":              "jump.terraform.test",
+			"host_key_fingerprint": "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+			"known_hosts":          "jump.terraform.test ssh-ed25519 XXXXXXXX",
+			"port":                 "2222",
+			"ssh_agent_auth":       true,
+			"ssh_cert":             "XXXXXXXX",
+			"ssh_cert_path":        "/home/user/.ssh",
+			"ssh_key":              "XXXXXXXX",
+			"ssh_key_path":         "/home/user/.ssh",
+			"user":                 "test",
+		},
+	}
+	testRKEClusterConnectionConf = &rkeConnection{
+		Type:         rkeClusterConnectionTypeProxyCommand,
+		JumpHosts:    testRKEClusterConnectionJumpHostsConf,
+		ProxyCommand: "aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p",
+	}
+	testRKEClusterConnectionInterface = []interface{}{
+		map[string]interface{}{
+			"type":          rkeClusterConnectionTypeProxyCommand,
+			"jump_host":     testRKEClusterConnectionJumpHostsInterface,
+			"proxy_command": "aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p",
+		},
+	}
+	testRKEClusterConnectionDockerConf = &rkeConnection{
+		Type:             rkeClusterConnectionTypeDocker,
+		DockerHost:       "tcp://node.terraform.test:2376",
+		DockerCACert:     "XXXXXXXX",
+		DockerClientCert: "XXXXXXXX",
+		DockerClientKey:  "XXXXXXXX",
+	}
+	testRKEClusterConnectionDockerInterface = []interface{}{
+		map[string]interface{}{
+			"type":               rkeClusterConnectionTypeDocker,
+			"docker_host":        "tcp://node.terraform.test:2376",
+			"docker_ca_cert":     "XXXXXXXX",
+			"docker_client_cert": "XXXXXXXX",
+			"docker_client_key":  "XXXXXXXX",
+		},
+	}
+}
+
+func TestExpandRKEClusterConnectionJumpHosts(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput []rkeJumpHost
+	}{
+		{
+			testRKEClusterConnectionJumpHostsInterface,
+			testRKEClusterConnectionJumpHostsConf,
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterConnectionJumpHosts(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterConnection(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *rkeConnection
+	}{
+		{
+			testRKEClusterConnectionInterface,
+			testRKEClusterConnectionConf,
+		},
+		{
+			testRKEClusterConnectionDockerInterface,
+			testRKEClusterConnectionDockerConf,
+		},
+		{
+			[]interface{}{},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := expandRKEClusterConnection(tc.Input)
+		if err != nil {
+			t.Fatalf("Unexpected error from expander: %v", err)
+		}
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterConnectionValidation(t *testing.T) {
+
+	cases := []map[string]interface{}{
+		{
+			"type": rkeClusterConnectionTypeDocker,
+		},
+		{
+			"type":        rkeClusterConnectionTypeDocker,
+			"docker_host": "http://node.terraform.test:2375",
+		},
+		{
+			"type":        rkeClusterConnectionTypeDocker,
+			"docker_host": "unix://",
+		},
+		{
+			"type": rkeClusterConnectionTypeProxyCommand,
+		},
+		{
+			"type":          rkeClusterConnectionTypeSSH,
+			"proxy_command": "nc %h %p",
+		},
+		{
+			"type":        rkeClusterConnectionTypeSSH,
+			"docker_host": "unix:///var/run/docker.sock",
+		},
+	}
+
+	for _, tc := range cases {
+		if _, err := expandRKEClusterConnection([]interface{}{tc}); err == nil {
+			t.Fatalf("Expected error from expander for input: %#v", tc)
+		}
+	}
+}
+
+func TestExpandRKEClusterNodesConnections(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput map[string]*rkeConnection
+	}{
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"address":    "node1.terraform.test",
+					"connection": testRKEClusterConnectionDockerInterface,
+				},
+				map[string]interface{}{
+					"address": "node2.terraform.test",
+				},
+			},
+			map[string]*rkeConnection{
+				"node1.terraform.test": testRKEClusterConnectionDockerConf,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := expandRKEClusterNodesConnections(tc.Input)
+		if err != nil {
+			t.Fatalf("Unexpected error from expander: %v", err)
+		}
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
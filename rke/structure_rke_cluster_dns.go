@@ -1,11 +1,31 @@
 package rke
 
 import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
 // Flatteners
 
+func flattenRKEClusterDNSLinearAutoscalerParams(in *rancher.LinearAutoscalerParams) []interface{} {
+	obj := make(map[string]interface{})
+	if in == nil {
+		return nil
+	}
+
+	obj["cores_per_replica"] = in.CoresPerReplica
+	obj["max"] = in.Max
+	obj["min"] = in.Min
+	obj["nodes_per_replica"] = in.NodesPerReplica
+	obj["prevent_single_point_failure"] = in.PreventSinglePointFailure
+
+	return []interface{}{obj}
+}
+
 func flattenRKEClusterDNSNodelocal(in *rancher.Nodelocal) []interface{} {
 	obj := make(map[string]interface{})
 	if in == nil {
@@ -29,6 +49,10 @@ func flattenRKEClusterDNS(in *rancher.DNSConfig) []interface{} {
 		return []interface{}{}
 	}
 
+	if in.LinearAutoscalerParams != nil {
+		obj["linear_autoscaler_params"] = flattenRKEClusterDNSLinearAutoscalerParams(in.LinearAutoscalerParams)
+	}
+
 	if in.Nodelocal != nil {
 		obj["nodelocal"] = flattenRKEClusterDNSNodelocal(in.Nodelocal)
 	}
@@ -37,6 +61,10 @@ func flattenRKEClusterDNS(in *rancher.DNSConfig) []interface{} {
 		obj["node_selector"] = toMapInterface(in.NodeSelector)
 	}
 
+	if len(in.Options) > 0 {
+		obj["options"] = toMapInterface(in.Options)
+	}
+
 	if len(in.Provider) > 0 {
 		obj["provider"] = in.Provider
 	}
@@ -45,6 +73,14 @@ func flattenRKEClusterDNS(in *rancher.DNSConfig) []interface{} {
 		obj["reverse_cidrs"] = toArrayInterface(in.ReverseCIDRs)
 	}
 
+	if len(in.Tolerations) > 0 {
+		obj["tolerations"] = flattenRKEClusterTolerations(in.Tolerations)
+	}
+
+	if in.UpdateStrategy != nil {
+		obj["update_strategy"] = flattenRKEClusterDeploymentUpdateStrategy(in.UpdateStrategy)
+	}
+
 	if len(in.UpstreamNameservers) > 0 {
 		obj["upstream_nameservers"] = toArrayInterface(in.UpstreamNameservers)
 	}
@@ -54,6 +90,36 @@ func flattenRKEClusterDNS(in *rancher.DNSConfig) []interface{} {
 
 // Expanders
 
+func expandRKEClusterDNSLinearAutoscalerParams(p []interface{}) *rancher.LinearAutoscalerParams {
+	obj := &rancher.LinearAutoscalerParams{}
+	if len(p) == 0 || p[0] == nil {
+		return nil
+	}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["cores_per_replica"].(float64); ok {
+		obj.CoresPerReplica = v
+	}
+
+	if v, ok := in["max"].(int); ok {
+		obj.Max = v
+	}
+
+	if v, ok := in["min"].(int); ok {
+		obj.Min = v
+	}
+
+	if v, ok := in["nodes_per_replica"].(float64); ok {
+		obj.NodesPerReplica = v
+	}
+
+	if v, ok := in["prevent_single_point_failure"].(bool); ok {
+		obj.PreventSinglePointFailure = v
+	}
+
+	return obj
+}
+
 func expandRKEClusterDNSNodelocal(p []interface{}) *rancher.Nodelocal {
 	obj := &rancher.Nodelocal{}
 	if len(p) == 0 || p[0] == nil {
@@ -79,6 +145,10 @@ func expandRKEClusterDNS(p []interface{}) *rancher.DNSConfig {
 	}
 	in := p[0].(map[string]interface{})
 
+	if v, ok := in["linear_autoscaler_params"].([]interface{}); ok && len(v) > 0 {
+		obj.LinearAutoscalerParams = expandRKEClusterDNSLinearAutoscalerParams(v)
+	}
+
 	if v, ok := in["nodelocal"].([]interface{}); ok && len(v) > 0 {
 		obj.Nodelocal = expandRKEClusterDNSNodelocal(v)
 	}
@@ -87,6 +157,10 @@ func expandRKEClusterDNS(p []interface{}) *rancher.DNSConfig {
 		obj.NodeSelector = toMapString(v)
 	}
 
+	if v, ok := in["options"].(map[string]interface{}); ok && len(v) > 0 {
+		obj.Options = toMapString(v)
+	}
+
 	if v, ok := in["provider"].(string); ok && len(v) > 0 {
 		obj.Provider = v
 	}
@@ -95,9 +169,57 @@ func expandRKEClusterDNS(p []interface{}) *rancher.DNSConfig {
 		obj.ReverseCIDRs = toArrayString(v)
 	}
 
+	if v, ok := in["tolerations"].([]interface{}); ok && len(v) > 0 {
+		obj.Tolerations = expandRKEClusterTolerations(v)
+	}
+
+	if v, ok := in["update_strategy"].([]interface{}); ok && len(v) > 0 {
+		obj.UpdateStrategy = expandRKEClusterDeploymentUpdateStrategy(v)
+	}
+
 	if v, ok := in["upstream_nameservers"].([]interface{}); ok && len(v) > 0 {
 		obj.UpstreamNameservers = toArrayString(v)
 	}
 
 	return obj
 }
+
+// Validators
+
+// validateRKEClusterDNS validates linear autoscaler params and that nodelocal ip_address is link-local and outside of the service cluster IP range
+func validateRKEClusterDNS(in *rancher.DNSConfig, serviceClusterIPRange string) error {
+	if in == nil {
+		return nil
+	}
+
+	if params := in.LinearAutoscalerParams; params != nil {
+		if params.CoresPerReplica == 0 && params.NodesPerReplica == 0 {
+			return fmt.Errorf("linear_autoscaler_params cores_per_replica or nodes_per_replica must be greater than 0")
+		}
+		if params.Max > 0 && params.Max < params.Min {
+			return fmt.Errorf("linear_autoscaler_params max %d must be greater than or equal to min %d", params.Max, params.Min)
+		}
+	}
+
+	if in.Nodelocal == nil || len(in.Nodelocal.IPAddress) == 0 {
+		return nil
+	}
+	ip := net.ParseIP(in.Nodelocal.IPAddress)
+	if ip == nil || !ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("nodelocal ip_address %s must be a link-local IP address", in.Nodelocal.IPAddress)
+	}
+	if len(serviceClusterIPRange) == 0 {
+		serviceClusterIPRange = cluster.DefaultServiceClusterIPRange
+	}
+	for _, cidr := range strings.Split(serviceClusterIPRange, ",") {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return fmt.Errorf("parsing service cluster IP range %s: %v", cidr, err)
+		}
+		if ipNet.Contains(ip) {
+			return fmt.Errorf("nodelocal ip_address %s must be outside of the service cluster IP range %s", in.Nodelocal.IPAddress, cidr)
+		}
+	}
+
+	return nil
+}
@@ -8,19 +8,37 @@ import (
 )
 
 var (
-	testRKEClusterDNSNodelocalConf      *rancher.Nodelocal
-	testRKEClusterDNSNodelocalInterface []interface{}
-	testRKEClusterDNSConf               *rancher.DNSConfig
-	testRKEClusterDNSInterface          []interface{}
+	testRKEClusterDNSLinearAutoscalerParamsConf      *rancher.LinearAutoscalerParams
+	testRKEClusterDNSLinearAutoscalerParamsInterface []interface{}
+	testRKEClusterDNSNodelocalConf                   *rancher.Nodelocal
+	testRKEClusterDNSNodelocalInterface              []interface{}
+	testRKEClusterDNSConf                            *rancher.DNSConfig
+	testRKEClusterDNSInterface                       []interface{}
 )
 
 func init() {
+	testRKEClusterDNSLinearAutoscalerParamsConf = &rancher.LinearAutoscalerParams{
+		CoresPerReplica:           64,
+		NodesPerReplica:           2,
+		Min:                       2,
+		Max:                       10,
+		PreventSinglePointFailure: true,
+	}
+	testRKEClusterDNSLinearAutoscalerParamsInterface = []interface{}{
+		map[string]interface{}{
+			"cores_per_replica":            float64(64),
+			"nodes_per_replica":            float64(2),
+			"min":                          2,
+			"max":                          10,
+			"prevent_single_point_failure": true,
+		},
+	}
 	testRKEClusterDNSNodelocalConf = &rancher.Nodelocal{
 		NodeSelector: map[string]string{
 			"sel1": "value1",
 			"sel2": "value2",
 		},
-		IPAddress: "ip_address",
+		IPAddress: "169.254.20.10",
 	}
 	testRKEClusterDNSNodelocalInterface = []interface{}{
 		map[string]interface{}{
@@ -28,33 +46,66 @@ func init() {
 				"sel1": "value1",
 				"sel2": "value2",
 			},
-			"ip_address": "ip_address",
+			"ip_address": "169.254.20.10",
 		},
 	}
 	testRKEClusterDNSConf = &rancher.DNSConfig{
-		Nodelocal: testRKEClusterDNSNodelocalConf,
+		LinearAutoscalerParams: testRKEClusterDNSLinearAutoscalerParamsConf,
+		Nodelocal:              testRKEClusterDNSNodelocalConf,
 		NodeSelector: map[string]string{
 			"sel1": "value1",
 			"sel2": "value2",
 		},
+		Options: map[string]string{
+			"coredns_priority_class_name": "system-cluster-critical",
+		},
 		Provider:            "kube-dns",
 		ReverseCIDRs:        []string{"rev1", "rev2"},
 		UpstreamNameservers: []string{"up1", "up2"},
+		Tolerations:         testRKEClusterTolerationsConf,
+		UpdateStrategy:      testRKEClusterDeploymentUpdateStrategyConf,
 	}
 	testRKEClusterDNSInterface = []interface{}{
 		map[string]interface{}{
-			"nodelocal": testRKEClusterDNSNodelocalInterface,
+			"linear_autoscaler_params": testRKEClusterDNSLinearAutoscalerParamsInterface,
+			"nodelocal":                testRKEClusterDNSNodelocalInterface,
 			"node_selector": map[string]interface{}{
 				"sel1": "value1",
 				"sel2": "value2",
 			},
+			"options": map[string]interface{}{
+				"coredns_priority_class_name": "system-cluster-critical",
+			},
 			"provider":             "kube-dns",
 			"reverse_cidrs":        []interface{}{"rev1", "rev2"},
 			"upstream_nameservers": []interface{}{"up1", "up2"},
+			"tolerations":          testRKEClusterTolerationsInterface,
+			"update_strategy":      testRKEClusterDeploymentUpdateStrategyInterface,
 		},
 	}
 }
 
+func TestFlattenRKEClusterDNSLinearAutoscalerParams(t *testing.T) {
+
+	cases := []struct {
+		Input          *rancher.LinearAutoscalerParams
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterDNSLinearAutoscalerParamsConf,
+			testRKEClusterDNSLinearAutoscalerParamsInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterDNSLinearAutoscalerParams(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
 func TestFlattenRKEClusterDNSNodelocal(t *testing.T) {
 
 	cases := []struct {
@@ -97,6 +148,27 @@ func TestFlattenRKEClusterDNS(t *testing.T) {
 	}
 }
 
+func TestExpandRKEClusterDNSLinearAutoscalerParams(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *rancher.LinearAutoscalerParams
+	}{
+		{
+			testRKEClusterDNSLinearAutoscalerParamsInterface,
+			testRKEClusterDNSLinearAutoscalerParamsConf,
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterDNSLinearAutoscalerParams(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
 func TestExpandRKEClusterDNSNodelocal(t *testing.T) {
 
 	cases := []struct {
@@ -138,3 +210,60 @@ func TestExpandRKEClusterDNS(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRKEClusterDNS(t *testing.T) {
+
+	cases := []struct {
+		Input                 *rancher.DNSConfig
+		ServiceClusterIPRange string
+		ExpectedError         bool
+	}{
+		{
+			testRKEClusterDNSConf,
+			"",
+			false,
+		},
+		{
+			&rancher.DNSConfig{
+				Nodelocal: &rancher.Nodelocal{IPAddress: "fe80::a"},
+			},
+			"10.43.0.0/16,fd98::/108",
+			false,
+		},
+		{
+			&rancher.DNSConfig{
+				Nodelocal: &rancher.Nodelocal{IPAddress: "10.43.0.10"},
+			},
+			"",
+			true,
+		},
+		{
+			&rancher.DNSConfig{
+				Nodelocal: &rancher.Nodelocal{IPAddress: "169.254.20.10"},
+			},
+			"169.254.0.0/16",
+			true,
+		},
+		{
+			&rancher.DNSConfig{
+				LinearAutoscalerParams: &rancher.LinearAutoscalerParams{Min: 1},
+			},
+			"",
+			true,
+		},
+		{
+			&rancher.DNSConfig{
+				LinearAutoscalerParams: &rancher.LinearAutoscalerParams{CoresPerReplica: 128, Min: 3, Max: 2},
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterDNS(tc.Input, tc.ServiceClusterIPRange)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
@@ -1,14 +1,153 @@
 package rke
 
 import (
+	"fmt"
+
 	rancher "github.com/rancher/rke/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Flatteners
 
+func flattenRKEClusterIngressKeyRef(name, key string, optional *bool) []interface{} {
+	obj := map[string]interface{}{
+		"key":  key,
+		"name": name,
+	}
+
+	if optional != nil {
+		obj["optional"] = *optional
+	}
+
+	return []interface{}{obj}
+}
+
+func flattenRKEClusterIngressExtraEnvs(p []rancher.ExtraEnv) []interface{} {
+	out := make([]interface{}, len(p))
+	for i, in := range p {
+		obj := make(map[string]interface{})
+
+		obj["name"] = in.Name
+
+		if len(in.Value) > 0 {
+			obj["value"] = in.Value
+		}
+
+		if in.ValueFrom != nil {
+			valueFrom := make(map[string]interface{})
+			if ref := in.ValueFrom.ConfigMapKeyRef; ref != nil {
+				valueFrom["config_map_key_ref"] = flattenRKEClusterIngressKeyRef(ref.Name, ref.Key, ref.Optional)
+			}
+			if ref := in.ValueFrom.FieldRef; ref != nil {
+				valueFrom["field_path"] = ref.FieldPath
+			}
+			if ref := in.ValueFrom.SecretKeyRef; ref != nil {
+				valueFrom["secret_key_ref"] = flattenRKEClusterIngressKeyRef(ref.Name, ref.Key, ref.Optional)
+			}
+			obj["value_from"] = []interface{}{valueFrom}
+		}
+
+		out[i] = obj
+	}
+
+	return out
+}
+
+func flattenRKEClusterIngressExtraVolumes(p []rancher.ExtraVolume) []interface{} {
+	out := make([]interface{}, len(p))
+	for i, in := range p {
+		obj := make(map[string]interface{})
+
+		obj["name"] = in.Name
+
+		if source := in.ConfigMap; source != nil {
+			configMap := map[string]interface{}{
+				"name": source.Name,
+			}
+			if source.DefaultMode != nil {
+				configMap["default_mode"] = int(*source.DefaultMode)
+			}
+			if source.Optional != nil {
+				configMap["optional"] = *source.Optional
+			}
+			obj["config_map"] = []interface{}{configMap}
+		}
+
+		if source := in.EmptyDir; source != nil {
+			emptyDir := make(map[string]interface{})
+			if len(source.Medium) > 0 {
+				emptyDir["medium"] = string(source.Medium)
+			}
+			if source.SizeLimit != nil {
+				emptyDir["size_limit"] = source.SizeLimit.String()
+			}
+			obj["empty_dir"] = []interface{}{emptyDir}
+		}
+
+		if source := in.HostPath; source != nil {
+			hostPath := map[string]interface{}{
+				"path": source.Path,
+			}
+			if source.Type != nil && len(*source.Type) > 0 {
+				hostPath["type"] = string(*source.Type)
+			}
+			obj["host_path"] = []interface{}{hostPath}
+		}
+
+		if source := in.Secret; source != nil {
+			secret := map[string]interface{}{
+				"secret_name": source.SecretName,
+			}
+			if source.DefaultMode != nil {
+				secret["default_mode"] = int(*source.DefaultMode)
+			}
+			if source.Optional != nil {
+				secret["optional"] = *source.Optional
+			}
+			obj["secret"] = []interface{}{secret}
+		}
+
+		out[i] = obj
+	}
+
+	return out
+}
+
+func flattenRKEClusterIngressExtraVolumeMounts(p []rancher.ExtraVolumeMount) []interface{} {
+	out := make([]interface{}, len(p))
+	for i, in := range p {
+		obj := make(map[string]interface{})
+
+		obj["mount_path"] = in.MountPath
+		obj["name"] = in.Name
+		obj["read_only"] = in.ReadOnly
+
+		if in.MountPropagation != nil && len(*in.MountPropagation) > 0 {
+			obj["mount_propagation"] = string(*in.MountPropagation)
+		}
+
+		if len(in.SubPath) > 0 {
+			obj["sub_path"] = in.SubPath
+		}
+
+		out[i] = obj
+	}
+
+	return out
+}
+
 func flattenRKEClusterIngress(in rancher.IngressConfig) []interface{} {
 	obj := make(map[string]interface{})
 
+	if len(in.DefaultHTTPBackendPriorityClassName) > 0 {
+		obj["default_http_backend_priority_class_name"] = in.DefaultHTTPBackendPriorityClassName
+	}
+
+	if in.DefaultIngressClass != nil {
+		obj["default_ingress_class"] = *in.DefaultIngressClass
+	}
+
 	if len(in.DNSPolicy) > 0 {
 		obj["dns_policy"] = in.DNSPolicy
 	}
@@ -17,6 +156,18 @@ func flattenRKEClusterIngress(in rancher.IngressConfig) []interface{} {
 		obj["extra_args"] = toMapInterface(in.ExtraArgs)
 	}
 
+	if len(in.ExtraEnvs) > 0 {
+		obj["extra_envs"] = flattenRKEClusterIngressExtraEnvs(in.ExtraEnvs)
+	}
+
+	if len(in.ExtraVolumeMounts) > 0 {
+		obj["extra_volume_mounts"] = flattenRKEClusterIngressExtraVolumeMounts(in.ExtraVolumeMounts)
+	}
+
+	if len(in.ExtraVolumes) > 0 {
+		obj["extra_volumes"] = flattenRKEClusterIngressExtraVolumes(in.ExtraVolumes)
+	}
+
 	if in.HTTPPort > 0 {
 		obj["http_port"] = in.HTTPPort
 	}
@@ -29,6 +180,10 @@ func flattenRKEClusterIngress(in rancher.IngressConfig) []interface{} {
 		obj["network_mode"] = in.NetworkMode
 	}
 
+	if len(in.NginxIngressControllerPriorityClassName) > 0 {
+		obj["nginx_ingress_controller_priority_class_name"] = in.NginxIngressControllerPriorityClassName
+	}
+
 	if len(in.NodeSelector) > 0 {
 		obj["node_selector"] = toMapInterface(in.NodeSelector)
 	}
@@ -41,6 +196,14 @@ func flattenRKEClusterIngress(in rancher.IngressConfig) []interface{} {
 		obj["provider"] = in.Provider
 	}
 
+	if len(in.Tolerations) > 0 {
+		obj["tolerations"] = flattenRKEClusterTolerations(in.Tolerations)
+	}
+
+	if in.UpdateStrategy != nil {
+		obj["update_strategy"] = flattenRKEClusterDaemonSetUpdateStrategy(in.UpdateStrategy)
+	}
+
 	if in.DefaultBackend != nil {
 		obj["default_backend"] = *in.DefaultBackend
 	}
@@ -50,13 +213,191 @@ func flattenRKEClusterIngress(in rancher.IngressConfig) []interface{} {
 
 // Expanders
 
-func expandRKEClusterIngress(p []interface{}) rancher.IngressConfig {
+func expandRKEClusterIngressKeyRef(p []interface{}) (name, key string, optional *bool) {
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["name"].(string); ok && len(v) > 0 {
+		name = v
+	}
+
+	if v, ok := in["key"].(string); ok && len(v) > 0 {
+		key = v
+	}
+
+	if v, ok := in["optional"].(bool); ok && v {
+		optional = &v
+	}
+
+	return name, key, optional
+}
+
+func expandRKEClusterIngressExtraEnvs(p []interface{}) []rancher.ExtraEnv {
+	obj := make([]rancher.ExtraEnv, len(p))
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+
+		if v, ok := in["name"].(string); ok && len(v) > 0 {
+			obj[i].Name = v
+		}
+
+		if v, ok := in["value"].(string); ok && len(v) > 0 {
+			obj[i].Value = v
+		}
+
+		if v, ok := in["value_from"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+			valueFrom := v[0].(map[string]interface{})
+			obj[i].ValueFrom = &v1.EnvVarSource{}
+			if v, ok := valueFrom["config_map_key_ref"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+				ref := &v1.ConfigMapKeySelector{}
+				ref.Name, ref.Key, ref.Optional = expandRKEClusterIngressKeyRef(v)
+				obj[i].ValueFrom.ConfigMapKeyRef = ref
+			}
+			if v, ok := valueFrom["field_path"].(string); ok && len(v) > 0 {
+				obj[i].ValueFrom.FieldRef = &v1.ObjectFieldSelector{FieldPath: v}
+			}
+			if v, ok := valueFrom["secret_key_ref"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+				ref := &v1.SecretKeySelector{}
+				ref.Name, ref.Key, ref.Optional = expandRKEClusterIngressKeyRef(v)
+				obj[i].ValueFrom.SecretKeyRef = ref
+			}
+		}
+	}
+
+	return obj
+}
+
+func expandRKEClusterIngressExtraVolumes(p []interface{}) ([]rancher.ExtraVolume, error) {
+	obj := make([]rancher.ExtraVolume, len(p))
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+		sources := 0
+
+		if v, ok := in["name"].(string); ok && len(v) > 0 {
+			obj[i].Name = v
+		}
+
+		if v, ok := in["config_map"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+			source := v[0].(map[string]interface{})
+			configMap := &v1.ConfigMapVolumeSource{}
+			if v, ok := source["name"].(string); ok && len(v) > 0 {
+				configMap.Name = v
+			}
+			if v, ok := source["default_mode"].(int); ok && v > 0 {
+				mode := int32(v)
+				configMap.DefaultMode = &mode
+			}
+			if v, ok := source["optional"].(bool); ok && v {
+				configMap.Optional = &v
+			}
+			obj[i].ConfigMap = configMap
+			sources++
+		}
+
+		if v, ok := in["empty_dir"].([]interface{}); ok && len(v) > 0 {
+			emptyDir := &v1.EmptyDirVolumeSource{}
+			if source, ok := v[0].(map[string]interface{}); ok {
+				if v, ok := source["medium"].(string); ok && len(v) > 0 {
+					emptyDir.Medium = v1.StorageMedium(v)
+				}
+				if v, ok := source["size_limit"].(string); ok && len(v) > 0 {
+					sizeLimit, err := resource.ParseQuantity(v)
+					if err != nil {
+						return nil, fmt.Errorf("Failed parsing extra_volumes %s empty_dir size_limit: %v", obj[i].Name, err)
+					}
+					emptyDir.SizeLimit = &sizeLimit
+				}
+			}
+			obj[i].EmptyDir = emptyDir
+			sources++
+		}
+
+		if v, ok := in["host_path"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+			source := v[0].(map[string]interface{})
+			hostPath := &v1.HostPathVolumeSource{}
+			if v, ok := source["path"].(string); ok && len(v) > 0 {
+				hostPath.Path = v
+			}
+			if v, ok := source["type"].(string); ok && len(v) > 0 {
+				hostPathType := v1.HostPathType(v)
+				hostPath.Type = &hostPathType
+			}
+			obj[i].HostPath = hostPath
+			sources++
+		}
+
+		if v, ok := in["secret"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+			source := v[0].(map[string]interface{})
+			secret := &v1.SecretVolumeSource{}
+			if v, ok := source["secret_name"].(string); ok && len(v) > 0 {
+				secret.SecretName = v
+			}
+			if v, ok := source["default_mode"].(int); ok && v > 0 {
+				mode := int32(v)
+				secret.DefaultMode = &mode
+			}
+			if v, ok := source["optional"].(bool); ok && v {
+				secret.Optional = &v
+			}
+			obj[i].Secret = secret
+			sources++
+		}
+
+		if sources != 1 {
+			return nil, fmt.Errorf("Extra volume %s must set exactly one of config_map, empty_dir, host_path or secret", obj[i].Name)
+		}
+	}
+
+	return obj, nil
+}
+
+func expandRKEClusterIngressExtraVolumeMounts(p []interface{}) []rancher.ExtraVolumeMount {
+	obj := make([]rancher.ExtraVolumeMount, len(p))
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+
+		if v, ok := in["mount_path"].(string); ok && len(v) > 0 {
+			obj[i].MountPath = v
+		}
+
+		if v, ok := in["mount_propagation"].(string); ok && len(v) > 0 {
+			mountPropagation := v1.MountPropagationMode(v)
+			obj[i].MountPropagation = &mountPropagation
+		}
+
+		if v, ok := in["name"].(string); ok && len(v) > 0 {
+			obj[i].Name = v
+		}
+
+		if v, ok := in["read_only"].(bool); ok {
+			obj[i].ReadOnly = v
+		}
+
+		if v, ok := in["sub_path"].(string); ok && len(v) > 0 {
+			obj[i].SubPath = v
+		}
+	}
+
+	return obj
+}
+
+func expandRKEClusterIngress(p []interface{}) (rancher.IngressConfig, error) {
 	obj := rancher.IngressConfig{}
 	if len(p) == 0 || p[0] == nil {
-		return obj
+		return obj, nil
 	}
 	in := p[0].(map[string]interface{})
 
+	if v, ok := in["default_http_backend_priority_class_name"].(string); ok && len(v) > 0 {
+		obj.DefaultHTTPBackendPriorityClassName = v
+	}
+
+	if v, ok := in["default_ingress_class"].(bool); ok {
+		obj.DefaultIngressClass = &v
+	}
+
 	if v, ok := in["dns_policy"].(string); ok && len(v) > 0 {
 		obj.DNSPolicy = v
 	}
@@ -65,6 +406,22 @@ func expandRKEClusterIngress(p []interface{}) rancher.IngressConfig {
 		obj.ExtraArgs = toMapString(v)
 	}
 
+	if v, ok := in["extra_envs"].([]interface{}); ok && len(v) > 0 {
+		obj.ExtraEnvs = expandRKEClusterIngressExtraEnvs(v)
+	}
+
+	if v, ok := in["extra_volume_mounts"].([]interface{}); ok && len(v) > 0 {
+		obj.ExtraVolumeMounts = expandRKEClusterIngressExtraVolumeMounts(v)
+	}
+
+	if v, ok := in["extra_volumes"].([]interface{}); ok && len(v) > 0 {
+		extraVolumes, err := expandRKEClusterIngressExtraVolumes(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.ExtraVolumes = extraVolumes
+	}
+
 	if v, ok := in["http_port"].(int); ok && v > 0 {
 		obj.HTTPPort = v
 	}
@@ -77,6 +434,10 @@ func expandRKEClusterIngress(p []interface{}) rancher.IngressConfig {
 		obj.NetworkMode = v
 	}
 
+	if v, ok := in["nginx_ingress_controller_priority_class_name"].(string); ok && len(v) > 0 {
+		obj.NginxIngressControllerPriorityClassName = v
+	}
+
 	if v, ok := in["node_selector"].(map[string]interface{}); ok && len(v) > 0 {
 		obj.NodeSelector = toMapString(v)
 	}
@@ -89,9 +450,70 @@ func expandRKEClusterIngress(p []interface{}) rancher.IngressConfig {
 		obj.Provider = v
 	}
 
+	if v, ok := in["tolerations"].([]interface{}); ok && len(v) > 0 {
+		obj.Tolerations = expandRKEClusterTolerations(v)
+	}
+
+	if v, ok := in["update_strategy"].([]interface{}); ok && len(v) > 0 {
+		obj.UpdateStrategy = expandRKEClusterDaemonSetUpdateStrategy(v)
+	}
+
 	if v, ok := in["default_backend"].(bool); ok {
 		obj.DefaultBackend = &v
 	}
 
-	return obj
+	return obj, nil
+}
+
+// Validators
+
+// validateRKEClusterIngress validates ingress arguments RKE would only reject at apply time, or silently ignore
+func validateRKEClusterIngress(in rancher.IngressConfig) error {
+	if in.Provider == rkeClusterIngressNone {
+		unsupported := []string{}
+		if len(in.DNSPolicy) > 0 {
+			unsupported = append(unsupported, "dns_policy")
+		}
+		if len(in.ExtraArgs) > 0 {
+			unsupported = append(unsupported, "extra_args")
+		}
+		if len(in.ExtraEnvs) > 0 {
+			unsupported = append(unsupported, "extra_envs")
+		}
+		if len(in.ExtraVolumeMounts) > 0 {
+			unsupported = append(unsupported, "extra_volume_mounts")
+		}
+		if len(in.ExtraVolumes) > 0 {
+			unsupported = append(unsupported, "extra_volumes")
+		}
+		if len(in.NodeSelector) > 0 {
+			unsupported = append(unsupported, "node_selector")
+		}
+		if len(in.Options) > 0 {
+			unsupported = append(unsupported, "options")
+		}
+		if len(in.Tolerations) > 0 {
+			unsupported = append(unsupported, "tolerations")
+		}
+		if len(unsupported) > 0 {
+			return fmt.Errorf("Ingress provider %q doesn't support %v", rkeClusterIngressNone, unsupported)
+		}
+		return nil
+	}
+
+	if in.HTTPPort > 0 && in.HTTPPort == in.HTTPSPort {
+		return fmt.Errorf("Ingress http_port and https_port need to be different, got %d", in.HTTPPort)
+	}
+
+	// Custom ports are only published by the ingress controller on hostPort network mode
+	if len(in.NetworkMode) > 0 && in.NetworkMode != rkeClusterIngressNetworkModeHostPort {
+		if in.HTTPPort > 0 && in.HTTPPort != rkeClusterIngressHTTPPortDefault {
+			return fmt.Errorf("Ingress http_port %d is only supported with network_mode %q", in.HTTPPort, rkeClusterIngressNetworkModeHostPort)
+		}
+		if in.HTTPSPort > 0 && in.HTTPSPort != rkeClusterIngressHTTPSPortDefault {
+			return fmt.Errorf("Ingress https_port %d is only supported with network_mode %q", in.HTTPSPort, rkeClusterIngressNetworkModeHostPort)
+		}
+	}
+
+	return nil
 }
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	rancher "github.com/rancher/rke/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
@@ -13,15 +15,100 @@ var (
 )
 
 func init() {
+	optional := true
+	defaultMode := int32(0644)
+	hostPathType := v1.HostPathDirectory
+	mountPropagation := v1.MountPropagationHostToContainer
+	sizeLimit := resource.MustParse("1Gi")
 	testRKEClusterIngressConf = rancher.IngressConfig{
-		DNSPolicy: "test",
+		DefaultHTTPBackendPriorityClassName: "backend_priority",
+		DefaultIngressClass:                 newTrue(),
+		DNSPolicy:                           "test",
 		ExtraArgs: map[string]string{
 			"arg_one": "one",
 			"arg_two": "two",
 		},
-		HTTPPort:    8080,
-		HTTPSPort:   8443,
-		NetworkMode: "network_mode",
+		ExtraEnvs: []rancher.ExtraEnv{
+			{
+				EnvVar: v1.EnvVar{
+					Name:  "env_one",
+					Value: "one",
+				},
+			},
+			{
+				EnvVar: v1.EnvVar{
+					Name: "env_two",
+					ValueFrom: &v1.EnvVarSource{
+						FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+						SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "secret"},
+							Key:                  "key",
+							Optional:             &optional,
+						},
+					},
+				},
+			},
+		},
+		ExtraVolumeMounts: []rancher.ExtraVolumeMount{
+			{
+				VolumeMount: v1.VolumeMount{
+					Name:             "config",
+					MountPath:        "/etc/config",
+					ReadOnly:         true,
+					MountPropagation: &mountPropagation,
+				},
+			},
+		},
+		ExtraVolumes: []rancher.ExtraVolume{
+			{
+				Volume: v1.Volume{
+					Name: "config",
+					VolumeSource: v1.VolumeSource{
+						ConfigMap: &v1.ConfigMapVolumeSource{
+							LocalObjectReference: v1.LocalObjectReference{Name: "config"},
+							DefaultMode:          &defaultMode,
+						},
+					},
+				},
+			},
+			{
+				Volume: v1.Volume{
+					Name: "cache",
+					VolumeSource: v1.VolumeSource{
+						EmptyDir: &v1.EmptyDirVolumeSource{
+							Medium:    v1.StorageMediumMemory,
+							SizeLimit: &sizeLimit,
+						},
+					},
+				},
+			},
+			{
+				Volume: v1.Volume{
+					Name: "logs",
+					VolumeSource: v1.VolumeSource{
+						HostPath: &v1.HostPathVolumeSource{
+							Path: "/var/log/nginx",
+							Type: &hostPathType,
+						},
+					},
+				},
+			},
+			{
+				Volume: v1.Volume{
+					Name: "certs",
+					VolumeSource: v1.VolumeSource{
+						Secret: &v1.SecretVolumeSource{
+							SecretName: "certs",
+							Optional:   &optional,
+						},
+					},
+				},
+			},
+		},
+		HTTPPort:                                8080,
+		HTTPSPort:                               8443,
+		NetworkMode:                             "network_mode",
+		NginxIngressControllerPriorityClassName: "controller_priority",
 		NodeSelector: map[string]string{
 			"node_one": "one",
 			"node_two": "two",
@@ -32,17 +119,89 @@ func init() {
 		},
 		Provider:       "test",
 		DefaultBackend: newTrue(),
+		Tolerations:    testRKEClusterTolerationsConf,
+		UpdateStrategy: testRKEClusterDaemonSetUpdateStrategyConf,
 	}
 	testRKEClusterIngressInterface = []interface{}{
 		map[string]interface{}{
-			"dns_policy": "test",
+			"default_http_backend_priority_class_name": "backend_priority",
+			"default_ingress_class":                    true,
+			"dns_policy":                               "test",
 			"extra_args": map[string]interface{}{
 				"arg_one": "one",
 				"arg_two": "two",
 			},
+			"extra_envs": []interface{}{
+				map[string]interface{}{
+					"name":  "env_one",
+					"value": "one",
+				},
+				map[string]interface{}{
+					"name": "env_two",
+					"value_from": []interface{}{
+						map[string]interface{}{
+							"field_path": "metadata.namespace",
+							"secret_key_ref": []interface{}{
+								map[string]interface{}{
+									"key":      "key",
+									"name":     "secret",
+									"optional": true,
+								},
+							},
+						},
+					},
+				},
+			},
+			"extra_volume_mounts": []interface{}{
+				map[string]interface{}{
+					"mount_path":        "/etc/config",
+					"mount_propagation": "HostToContainer",
+					"name":              "config",
+					"read_only":         true,
+				},
+			},
+			"extra_volumes": []interface{}{
+				map[string]interface{}{
+					"name": "config",
+					"config_map": []interface{}{
+						map[string]interface{}{
+							"default_mode": 0644,
+							"name":         "config",
+						},
+					},
+				},
+				map[string]interface{}{
+					"name": "cache",
+					"empty_dir": []interface{}{
+						map[string]interface{}{
+							"medium":     "Memory",
+							"size_limit": "1Gi",
+						},
+					},
+				},
+				map[string]interface{}{
+					"name": "logs",
+					"host_path": []interface{}{
+						map[string]interface{}{
+							"path": "/var/log/nginx",
+							"type": "Directory",
+						},
+					},
+				},
+				map[string]interface{}{
+					"name": "certs",
+					"secret": []interface{}{
+						map[string]interface{}{
+							"optional":    true,
+							"secret_name": "certs",
+						},
+					},
+				},
+			},
 			"http_port":    8080,
 			"https_port":   8443,
 			"network_mode": "network_mode",
+			"nginx_ingress_controller_priority_class_name": "controller_priority",
 			"node_selector": map[string]interface{}{
 				"node_one": "one",
 				"node_two": "two",
@@ -53,6 +212,8 @@ func init() {
 			},
 			"provider":        "test",
 			"default_backend": true,
+			"tolerations":     testRKEClusterTolerationsInterface,
+			"update_strategy": testRKEClusterDaemonSetUpdateStrategyInterface,
 		},
 	}
 }
@@ -91,10 +252,137 @@ func TestExpandRKEClusterIngress(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		output := expandRKEClusterIngress(tc.Input)
+		output, err := expandRKEClusterIngress(tc.Input)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander: %#v", err)
+		}
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
 		}
 	}
 }
+
+func TestExpandRKEClusterIngressExtraVolumesError(t *testing.T) {
+
+	cases := []struct {
+		Input []interface{}
+	}{
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name": "no_source",
+				},
+			},
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name":      "two_sources",
+					"empty_dir": []interface{}{nil},
+					"host_path": []interface{}{
+						map[string]interface{}{
+							"path": "/tmp",
+						},
+					},
+				},
+			},
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"name": "bad_size",
+					"empty_dir": []interface{}{
+						map[string]interface{}{
+							"size_limit": "one",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := expandRKEClusterIngressExtraVolumes(tc.Input)
+		if err == nil {
+			t.Fatalf("Expected error from expander on input %#v", tc.Input)
+		}
+	}
+}
+
+func TestValidateRKEClusterIngress(t *testing.T) {
+
+	cases := []struct {
+		Input         rancher.IngressConfig
+		ExpectedError bool
+	}{
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNginx,
+				NetworkMode: rkeClusterIngressNetworkModeHostPort,
+				HTTPPort:    8080,
+				HTTPSPort:   8443,
+			},
+			false,
+		},
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNginx,
+				NetworkMode: rkeClusterIngressNetworkModeHostNetwork,
+				HTTPPort:    rkeClusterIngressHTTPPortDefault,
+				HTTPSPort:   rkeClusterIngressHTTPSPortDefault,
+			},
+			false,
+		},
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNginx,
+				NetworkMode: rkeClusterIngressNetworkModeHostNetwork,
+				HTTPPort:    8080,
+			},
+			true,
+		},
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNginx,
+				NetworkMode: rkeClusterIngressNetworkModeNone,
+				HTTPSPort:   8443,
+			},
+			true,
+		},
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNginx,
+				NetworkMode: rkeClusterIngressNetworkModeHostPort,
+				HTTPPort:    8080,
+				HTTPSPort:   8080,
+			},
+			true,
+		},
+		{
+			rancher.IngressConfig{
+				Provider:    rkeClusterIngressNone,
+				NetworkMode: rkeClusterIngressNetworkModeHostNetwork,
+				HTTPPort:    rkeClusterIngressHTTPPortDefault,
+			},
+			false,
+		},
+		{
+			rancher.IngressConfig{
+				Provider: rkeClusterIngressNone,
+				ExtraArgs: map[string]string{
+					"arg_one": "one",
+				},
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterIngress(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
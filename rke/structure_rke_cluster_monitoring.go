@@ -9,6 +9,10 @@ import (
 func flattenRKEClusterMonitoring(in rancher.MonitoringConfig) []interface{} {
 	obj := make(map[string]interface{})
 
+	if len(in.NodeSelector) > 0 {
+		obj["node_selector"] = toMapInterface(in.NodeSelector)
+	}
+
 	if len(in.Options) > 0 {
 		obj["options"] = toMapInterface(in.Options)
 	}
@@ -17,6 +21,18 @@ func flattenRKEClusterMonitoring(in rancher.MonitoringConfig) []interface{} {
 		obj["provider"] = in.Provider
 	}
 
+	if in.Replicas != nil {
+		obj["replicas"] = int(*in.Replicas)
+	}
+
+	if len(in.Tolerations) > 0 {
+		obj["tolerations"] = flattenRKEClusterTolerations(in.Tolerations)
+	}
+
+	if in.UpdateStrategy != nil {
+		obj["update_strategy"] = flattenRKEClusterDeploymentUpdateStrategy(in.UpdateStrategy)
+	}
+
 	return []interface{}{obj}
 }
 
@@ -29,6 +45,10 @@ func expandRKEClusterMonitoring(p []interface{}) rancher.MonitoringConfig {
 	}
 	in := p[0].(map[string]interface{})
 
+	if v, ok := in["node_selector"].(map[string]interface{}); ok && len(v) > 0 {
+		obj.NodeSelector = toMapString(v)
+	}
+
 	if v, ok := in["options"].(map[string]interface{}); ok && len(v) > 0 {
 		obj.Options = toMapString(v)
 	}
@@ -37,5 +57,18 @@ func expandRKEClusterMonitoring(p []interface{}) rancher.MonitoringConfig {
 		obj.Provider = v
 	}
 
+	if v, ok := in["replicas"].(int); ok && v > 0 {
+		replicas := int32(v)
+		obj.Replicas = &replicas
+	}
+
+	if v, ok := in["tolerations"].([]interface{}); ok && len(v) > 0 {
+		obj.Tolerations = expandRKEClusterTolerations(v)
+	}
+
+	if v, ok := in["update_strategy"].([]interface{}); ok && len(v) > 0 {
+		obj.UpdateStrategy = expandRKEClusterDeploymentUpdateStrategy(v)
+	}
+
 	return obj
 }
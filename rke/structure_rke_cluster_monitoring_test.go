@@ -13,20 +13,35 @@ var (
 )
 
 func init() {
+	replicas := int32(2)
 	testRKEClusterMonitoringConf = rancher.MonitoringConfig{
+		NodeSelector: map[string]string{
+			"node_one": "one",
+			"node_two": "two",
+		},
 		Options: map[string]string{
 			"option1": "value1",
 			"option2": "value2",
 		},
-		Provider: "test",
+		Provider:       "test",
+		Replicas:       &replicas,
+		Tolerations:    testRKEClusterTolerationsConf,
+		UpdateStrategy: testRKEClusterDeploymentUpdateStrategyConf,
 	}
 	testRKEClusterMonitoringInterface = []interface{}{
 		map[string]interface{}{
+			"node_selector": map[string]interface{}{
+				"node_one": "one",
+				"node_two": "two",
+			},
 			"options": map[string]interface{}{
 				"option1": "value1",
 				"option2": "value2",
 			},
-			"provider": "test",
+			"provider":        "test",
+			"replicas":        2,
+			"tolerations":     testRKEClusterTolerationsInterface,
+			"update_strategy": testRKEClusterDeploymentUpdateStrategyInterface,
 		},
 	}
 }
@@ -62,6 +77,17 @@ func TestExpandRKEClusterMonitoring(t *testing.T) {
 			testRKEClusterMonitoringInterface,
 			testRKEClusterMonitoringConf,
 		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"node_selector": map[string]interface{}{},
+					"provider":      "metrics-server",
+				},
+			},
+			rancher.MonitoringConfig{
+				Provider: "metrics-server",
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -1,11 +1,52 @@
 package rke
 
 import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	rancher "github.com/rancher/rke/types"
 )
 
+type rkeClusterNetworkProvider struct {
+	name    string
+	plugin  string
+	options map[string]string
+	fields  map[string]*schema.Schema
+}
+
+// rkeClusterNetworkProviders are the typed provider blocks whose fields are set as network options
+var rkeClusterNetworkProviders = []rkeClusterNetworkProvider{
+	{"calico_network_provider", rkeClusterNetworkPluginCalicoName, rkeClusterNetworkCalicoOptions, rkeClusterNetworkCalicoFields()},
+	{"canal_network_provider", rkeClusterNetworkPluginCanalName, rkeClusterNetworkCanalOptions, rkeClusterNetworkCanalFields()},
+	{"flannel_network_provider", rkeClusterNetworkPluginFlannelName, rkeClusterNetworkFlannelOptions, rkeClusterNetworkFlannelFields()},
+}
+
 // Flatteners
 
+// flattenRKEClusterNetworkProviderOptions returns the provider block with the options of the fields set on p
+func flattenRKEClusterNetworkProviderOptions(in map[string]string, provider rkeClusterNetworkProvider, p []interface{}) []interface{} {
+	obj := make(map[string]interface{})
+	input := expandRKEClusterNetworkProviderOptions(p, provider)
+
+	for field, key := range provider.options {
+		v, ok := in[key]
+		if _, set := input[key]; !set || !ok || len(v) == 0 {
+			continue
+		}
+		if provider.fields[field].Type != schema.TypeInt {
+			obj[field] = v
+			continue
+		}
+		if i, err := strconv.Atoi(v); err == nil {
+			obj[field] = i
+		}
+	}
+
+	return []interface{}{obj}
+}
+
 func flattenRKEClusterNetworkCalico(in *rancher.CalicoNetworkProvider) []interface{} {
 	obj := make(map[string]interface{})
 	if in == nil {
@@ -139,7 +180,7 @@ func flattenRKEClusterNetworkAci(in *rancher.AciNetworkProvider) []interface{} {
 	return []interface{}{obj}
 }
 
-func flattenRKEClusterNetwork(in rancher.NetworkConfig) []interface{} {
+func flattenRKEClusterNetwork(in rancher.NetworkConfig, p []interface{}) []interface{} {
 	obj := make(map[string]interface{})
 
 	if in.CalicoNetworkProvider != nil {
@@ -162,6 +203,14 @@ func flattenRKEClusterNetwork(in rancher.NetworkConfig) []interface{} {
 		obj["aci_network_provider"] = flattenRKEClusterNetworkAci(in.AciNetworkProvider)
 	}
 
+	if len(in.Tolerations) > 0 {
+		obj["tolerations"] = flattenRKEClusterTolerations(in.Tolerations)
+	}
+
+	if in.UpdateStrategy != nil {
+		obj["update_strategy"] = flattenRKEClusterDaemonSetUpdateStrategy(in.UpdateStrategy)
+	}
+
 	if in.EnableBrNetfilter != nil {
 		obj["enable_br_netfilter"] = in.EnableBrNetfilter
 	}
@@ -170,8 +219,27 @@ func flattenRKEClusterNetwork(in rancher.NetworkConfig) []interface{} {
 		obj["mtu"] = in.MTU
 	}
 
-	if len(in.Options) > 0 {
-		obj["options"] = toMapInterface(in.Options)
+	// Options set by typed provider block fields are flattened back to them, if they were set
+	options := make(map[string]string, len(in.Options))
+	for k, v := range in.Options {
+		options[k] = v
+	}
+	if len(p) > 0 && p[0] != nil {
+		input := p[0].(map[string]interface{})
+		for _, provider := range rkeClusterNetworkProviders {
+			v, ok := input[provider.name].([]interface{})
+			if !ok || len(v) == 0 {
+				continue
+			}
+			obj[provider.name] = flattenRKEClusterNetworkProviderOptions(in.Options, provider, v)
+			for key := range expandRKEClusterNetworkProviderOptions(v, provider) {
+				delete(options, key)
+			}
+		}
+	}
+
+	if len(options) > 0 {
+		obj["options"] = toMapInterface(options)
 	}
 
 	if len(in.Plugin) > 0 {
@@ -183,6 +251,29 @@ func flattenRKEClusterNetwork(in rancher.NetworkConfig) []interface{} {
 
 // Expanders
 
+func expandRKEClusterNetworkProviderOptions(p []interface{}, provider rkeClusterNetworkProvider) map[string]string {
+	obj := make(map[string]string)
+	if len(p) == 0 || p[0] == nil {
+		return obj
+	}
+	in := p[0].(map[string]interface{})
+
+	for field, key := range provider.options {
+		switch v := in[field].(type) {
+		case string:
+			if len(v) > 0 {
+				obj[key] = v
+			}
+		case int:
+			if v > 0 {
+				obj[key] = strconv.Itoa(v)
+			}
+		}
+	}
+
+	return obj
+}
+
 func expandRKEClusterNetworkCalico(p []interface{}) *rancher.CalicoNetworkProvider {
 	obj := &rancher.CalicoNetworkProvider{}
 	if len(p) == 0 || p[0] == nil {
@@ -322,11 +413,11 @@ func expandRKEClusterNetworkAci(p []interface{}) *rancher.AciNetworkProvider {
 	return obj
 }
 
-func expandRKEClusterNetwork(p []interface{}) rancher.NetworkConfig {
+func expandRKEClusterNetwork(p []interface{}) (rancher.NetworkConfig, error) {
 	obj := rancher.NetworkConfig{}
 	if len(p) == 0 || p[0] == nil {
 		obj.Plugin = rkeClusterNetworkPluginDefault
-		return obj
+		return obj, nil
 	}
 	in := p[0].(map[string]interface{})
 
@@ -362,9 +453,50 @@ func expandRKEClusterNetwork(p []interface{}) rancher.NetworkConfig {
 		obj.Plugin = v
 	}
 
+	if v, ok := in["tolerations"].([]interface{}); ok && len(v) > 0 {
+		obj.Tolerations = expandRKEClusterTolerations(v)
+	}
+
+	if v, ok := in["update_strategy"].([]interface{}); ok && len(v) > 0 {
+		obj.UpdateStrategy = expandRKEClusterDaemonSetUpdateStrategy(v)
+	}
+
 	if v, ok := in["enable_br_netfilter"].(bool); ok {
 		obj.EnableBrNetfilter = &v
 	}
 
-	return obj
+	// RKE only reads calico, canal and flannel config from options. Typed provider fields are set as options if they are set,
+	// options also set by them are rejected by validateRKEClusterNetworkOptions
+	for _, provider := range rkeClusterNetworkProviders {
+		v, ok := in[provider.name].([]interface{})
+		if !ok || len(v) == 0 {
+			continue
+		}
+		if obj.Plugin != provider.plugin {
+			return obj, fmt.Errorf("%s can only be set with network plugin %s, got %s", provider.name, provider.plugin, obj.Plugin)
+		}
+		if obj.Options == nil {
+			obj.Options = make(map[string]string)
+		}
+		for key, value := range expandRKEClusterNetworkProviderOptions(v, provider) {
+			obj.Options[key] = value
+		}
+	}
+
+	return obj, nil
+}
+
+// Validators
+
+// validateRKEClusterNetworkOptions returns an error if an option is also set by a typed provider field.
+// configured returns true if the field of the provider block is set on config
+func validateRKEClusterNetworkOptions(options []string, configured func(provider, field string) bool) error {
+	for _, provider := range rkeClusterNetworkProviders {
+		for field, key := range provider.options {
+			if configured(provider.name, field) && slices.Contains(options, key) {
+				return fmt.Errorf("network option %s conflicts with %s %s. Set it only once", key, provider.name, field)
+			}
+		}
+	}
+	return nil
 }
@@ -2,6 +2,7 @@ package rke
 
 import (
 	"reflect"
+	"slices"
 	"testing"
 
 	rancher "github.com/rancher/rke/types"
@@ -123,8 +124,9 @@ func init() {
 	testRKEClusterNetworkConfCalico = rancher.NetworkConfig{
 		CalicoNetworkProvider: testRKEClusterNetworkCalicoConf,
 		Options: map[string]string{
-			"option1": "value1",
-			"option2": "value2",
+			"calico_cloud_provider": "aws",
+			"option1":               "value1",
+			"option2":               "value2",
 		},
 		Plugin: rkeClusterNetworkPluginCalicoName,
 	}
@@ -141,10 +143,13 @@ func init() {
 	testRKEClusterNetworkConfCanal = rancher.NetworkConfig{
 		CanalNetworkProvider: testRKEClusterNetworkCanalConf,
 		Options: map[string]string{
-			"option1": "value1",
-			"option2": "value2",
+			"canal_iface": "eth0",
+			"option1":     "value1",
+			"option2":     "value2",
 		},
-		Plugin: rkeClusterNetworkPluginCanalName,
+		Plugin:         rkeClusterNetworkPluginCanalName,
+		Tolerations:    testRKEClusterTolerationsConf,
+		UpdateStrategy: testRKEClusterDaemonSetUpdateStrategyConf,
 	}
 	testRKEClusterNetworkInterfaceCanal = []interface{}{
 		map[string]interface{}{
@@ -153,20 +158,33 @@ func init() {
 				"option1": "value1",
 				"option2": "value2",
 			},
-			"plugin": rkeClusterNetworkPluginCanalName,
+			"plugin":          rkeClusterNetworkPluginCanalName,
+			"tolerations":     testRKEClusterTolerationsInterface,
+			"update_strategy": testRKEClusterDaemonSetUpdateStrategyInterface,
 		},
 	}
 	testRKEClusterNetworkConfFlannel = rancher.NetworkConfig{
 		FlannelNetworkProvider: testRKEClusterNetworkFlannelConf,
 		Options: map[string]string{
-			"option1": "value1",
-			"option2": "value2",
+			"flannel_backend_port": "4789",
+			"flannel_backend_type": "vxlan",
+			"flannel_backend_vni":  "4096",
+			"flannel_iface":        "eth0",
+			"option1":              "value1",
+			"option2":              "value2",
 		},
 		Plugin: rkeClusterNetworkPluginFlannelName,
 	}
 	testRKEClusterNetworkInterfaceFlannel = []interface{}{
 		map[string]interface{}{
-			"flannel_network_provider": testRKEClusterNetworkFlannelInterface,
+			"flannel_network_provider": []interface{}{
+				map[string]interface{}{
+					"backend_port": 4789,
+					"backend_type": "vxlan",
+					"backend_vni":  4096,
+					"iface":        "eth0",
+				},
+			},
 			"options": map[string]interface{}{
 				"option1": "value1",
 				"option2": "value2",
@@ -350,7 +368,117 @@ func TestFlattenRKEClusterNetwork(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		output := flattenRKEClusterNetwork(tc.Input)
+		output := flattenRKEClusterNetwork(tc.Input, tc.ExpectedOutput)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestFlattenRKEClusterNetworkOptions(t *testing.T) {
+
+	cases := []struct {
+		Input          rancher.NetworkConfig
+		Prior          []interface{}
+		ExpectedOutput []interface{}
+	}{
+		{
+			rancher.NetworkConfig{
+				Options: map[string]string{
+					"flannel_backend_type": "host-gw",
+					"flannel_iface":        "eth1",
+				},
+				Plugin: rkeClusterNetworkPluginFlannelName,
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"plugin": rkeClusterNetworkPluginFlannelName,
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"options": map[string]interface{}{
+						"flannel_backend_type": "host-gw",
+						"flannel_iface":        "eth1",
+					},
+					"plugin": rkeClusterNetworkPluginFlannelName,
+				},
+			},
+		},
+		{
+			rancher.NetworkConfig{
+				Options: map[string]string{
+					"flannel_backend_type": "host-gw",
+					"flannel_iface":        "eth1",
+				},
+				Plugin: rkeClusterNetworkPluginFlannelName,
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"flannel_network_provider": testRKEClusterNetworkFlannelInterface,
+					"plugin":                   rkeClusterNetworkPluginFlannelName,
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"flannel_network_provider": []interface{}{
+						map[string]interface{}{
+							"iface": "eth1",
+						},
+					},
+					"options": map[string]interface{}{
+						"flannel_backend_type": "host-gw",
+					},
+					"plugin": rkeClusterNetworkPluginFlannelName,
+				},
+			},
+		},
+		{
+			rancher.NetworkConfig{
+				Options: map[string]string{
+					"flannel_backend_port": "4789",
+					"flannel_backend_type": "vxlan",
+					"flannel_backend_vni":  "4096",
+					"flannel_iface":        "eth1",
+				},
+				Plugin: rkeClusterNetworkPluginFlannelName,
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"flannel_network_provider": []interface{}{
+						map[string]interface{}{
+							"backend_port": 4789,
+							"backend_vni":  4096,
+							"iface":        "eth0",
+						},
+					},
+					"options": map[string]interface{}{
+						"flannel_backend_type": "vxlan",
+					},
+					"plugin": rkeClusterNetworkPluginFlannelName,
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"flannel_network_provider": []interface{}{
+						map[string]interface{}{
+							"backend_port": 4789,
+							"backend_vni":  4096,
+							"iface":        "eth1",
+						},
+					},
+					"options": map[string]interface{}{
+						"flannel_backend_type": "vxlan",
+					},
+					"plugin": rkeClusterNetworkPluginFlannelName,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterNetwork(tc.Input, tc.Prior)
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
@@ -492,10 +620,117 @@ func TestExpandRKEClusterNetwork(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		output := expandRKEClusterNetwork(tc.Input)
+		output, err := expandRKEClusterNetwork(tc.Input)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander: %#v", err)
+		}
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
 		}
 	}
+
+	errCases := []struct {
+		Input         []interface{}
+		ExpectedError bool
+	}{
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"calico_network_provider": testRKEClusterNetworkCalicoInterface,
+					"plugin":                  rkeClusterNetworkPluginCanalName,
+				},
+			},
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"flannel_network_provider": testRKEClusterNetworkFlannelInterface,
+					"plugin":                   rkeClusterNetworkPluginNonelName,
+				},
+			},
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"plugin": rkeClusterNetworkPluginNonelName,
+				},
+			},
+			false,
+		},
+	}
+
+	// Options not set by typed provider fields are kept
+	input := []interface{}{
+		map[string]interface{}{
+			"flannel_network_provider": testRKEClusterNetworkFlannelInterface,
+			"options": map[string]interface{}{
+				"flannel_backend_port": "4789",
+				"flannel_backend_type": "host-gw",
+				"flannel_backend_vni":  "4096",
+			},
+			"plugin": rkeClusterNetworkPluginFlannelName,
+		},
+	}
+	expectedOptions := map[string]string{
+		"flannel_backend_port": "4789",
+		"flannel_backend_type": "host-gw",
+		"flannel_backend_vni":  "4096",
+		"flannel_iface":        "eth0",
+	}
+	output, err := expandRKEClusterNetwork(input)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if !reflect.DeepEqual(output.Options, expectedOptions) {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v", expectedOptions, output.Options)
+	}
+
+	for _, tc := range errCases {
+		_, err := expandRKEClusterNetwork(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected error from expander on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
+
+func TestValidateRKEClusterNetworkOptions(t *testing.T) {
+	cases := []struct {
+		Options       []string
+		Configured    []string
+		ExpectedError bool
+	}{
+		{
+			[]string{"flannel_backend_type", "flannel_backend_port"},
+			[]string{"flannel_network_provider.iface"},
+			false,
+		},
+		{
+			[]string{"flannel_backend_type"},
+			[]string{"flannel_network_provider.backend_type"},
+			true,
+		},
+		{
+			[]string{"canal_iface"},
+			[]string{"flannel_network_provider.iface"},
+			false,
+		},
+		{
+			[]string{"canal_iface"},
+			[]string{"canal_network_provider.iface"},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		configured := func(provider, field string) bool {
+			return slices.Contains(tc.Configured, provider+"."+field)
+		}
+		err := validateRKEClusterNetworkOptions(tc.Options, configured)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Options, tc.ExpectedError, err)
+		}
+	}
 }
@@ -1,10 +1,20 @@
 package rke
 
 import (
+	"fmt"
+	"strconv"
+
+	"github.com/rancher/rke/cluster"
 	"github.com/rancher/rke/hosts"
 	rancher "github.com/rancher/rke/types"
 )
 
+const (
+	rkeClusterWindowsFlannelVxLanPort   = 4789
+	rkeClusterWindowsFlannelVxLanMinVNI = 4096
+	rkeClusterWindowsNodeRoleWorker     = "worker"
+)
+
 // Flatteners
 
 func flattenRKEClusterNodeDrainInput(in *rancher.NodeDrainInput) []interface{} {
@@ -98,7 +108,10 @@ func flattenRKEClusterNodes(input []rancher.RKEConfigNode, p []interface{}) []in
 
 		obj["address"] = in.Address
 		obj["role"] = toArrayInterface(in.Role)
-		obj["user"] = in.User
+
+		if len(in.User) > 0 {
+			obj["user"] = in.User
+		}
 
 		if len(in.DockerSocket) > 0 {
 			obj["docker_socket"] = in.DockerSocket
@@ -303,3 +316,89 @@ func expandRKEClusterNodes(p []interface{}) []rancher.RKEConfigNode {
 
 	return out
 }
+
+func expandRKEClusterNodesHostKeys(p []interface{}) map[string]rkeHostKey {
+	out := map[string]rkeHostKey{}
+	if len(p) == 0 || p[0] == nil {
+		return out
+	}
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+		obj := rkeHostKey{}
+
+		if v, ok := in["host_key_fingerprint"].(string); ok && len(v) > 0 {
+			obj.Fingerprint = v
+		}
+
+		if v, ok := in["known_hosts"].(string); ok && len(v) > 0 {
+			obj.KnownHosts = v
+		}
+
+		if v, ok := in["address"].(string); ok && len(v) > 0 && (len(obj.Fingerprint) > 0 || len(obj.KnownHosts) > 0) {
+			out[v] = obj
+		}
+	}
+
+	return out
+}
+
+// Validators
+
+// validateRKEClusterNodesWindows validates windows nodes are workers and the network config supports them
+func validateRKEClusterNodesWindows(p []interface{}, network rancher.NetworkConfig) error {
+	windowsNodes := 0
+	for i := range p {
+		in, ok := p[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if v, ok := in["os"].(string); !ok || v != rkeClusterNodeOSWindows {
+			continue
+		}
+		windowsNodes++
+		address, _ := in["address"].(string)
+		roles := []string{}
+		if v, ok := in["role"].([]interface{}); ok {
+			roles = toArrayString(v)
+		}
+		if v, ok := in["roles"].(string); ok && len(v) > 0 {
+			roles = append(roles, v)
+		}
+		for _, role := range roles {
+			if role != rkeClusterWindowsNodeRoleWorker {
+				return fmt.Errorf("Windows node %s can only have %s role, got %s", address, rkeClusterWindowsNodeRoleWorker, role)
+			}
+		}
+	}
+
+	if windowsNodes == 0 {
+		return nil
+	}
+
+	plugin := network.Plugin
+	if len(plugin) == 0 {
+		plugin = rkeClusterNetworkPluginDefault
+	}
+	if plugin != cluster.FlannelNetworkPlugin {
+		return fmt.Errorf("Windows nodes require network plugin %s, got %s", cluster.FlannelNetworkPlugin, plugin)
+	}
+
+	backendType := network.Options[cluster.FlannelBackendType]
+	switch backendType {
+	case rkeClusterNetworkFlannelBackendHostGW:
+		return nil
+	case "", rkeClusterNetworkFlannelBackendVxLan:
+		port, err := strconv.Atoi(network.Options[cluster.FlannelBackendPort])
+		if err != nil || port != rkeClusterWindowsFlannelVxLanPort {
+			return fmt.Errorf("Windows nodes with flannel vxlan backend require network option %s = \"%d\"", cluster.FlannelBackendPort, rkeClusterWindowsFlannelVxLanPort)
+		}
+		vni, err := strconv.Atoi(network.Options[cluster.FlannelBackendVxLanNetworkIdentify])
+		if err != nil || vni < rkeClusterWindowsFlannelVxLanMinVNI {
+			return fmt.Errorf("Windows nodes with flannel vxlan backend require network option %s >= %d", cluster.FlannelBackendVxLanNetworkIdentify, rkeClusterWindowsFlannelVxLanMinVNI)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Windows nodes require flannel backend %s or %s, got %s", rkeClusterNetworkFlannelBackendHostGW, rkeClusterNetworkFlannelBackendVxLan, backendType)
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -16,6 +17,7 @@ var (
 	testRKEClusterNodesTaintsInterface         []interface{}
 	testRKEClusterNodesConf                    []rancher.RKEConfigNode
 	testRKEClusterNodesInterface               []interface{}
+	testRKEClusterNodesHostKeysConf            map[string]rkeHostKey
 )
 
 func init() {
@@ -87,10 +89,12 @@ func init() {
 	}
 	testRKEClusterNodesInterface = []interface{}{
 		map[string]interface{}{
-			"address":           "url.terraform.test",
-			"docker_socket":     "docker.sock",
-			"hostname_override": "terra-test",
-			"internal_address":  "192.168.1.1",
+			"address":              "url.terraform.test",
+			"docker_socket":        "docker.sock",
+			"host_key_fingerprint": "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+			"hostname_override":    "terra-test",
+			"internal_address":     "192.168.1.1",
+			"known_hosts":          "url.terraform.test ssh-ed25519 XXXXXXXX",
 			"labels": map[string]interface{}{
 				"label_one": "one",
 				"label_two": "two",
@@ -107,6 +111,12 @@ func init() {
 			"taints":         testRKEClusterNodesTaintsInterface,
 		},
 	}
+	testRKEClusterNodesHostKeysConf = map[string]rkeHostKey{
+		"url.terraform.test": {
+			Fingerprint: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8",
+			KnownHosts:  "url.terraform.test ssh-ed25519 XXXXXXXX",
+		},
+	}
 }
 
 func TestFlattenRKEClusterNodeDrainInput(t *testing.T) {
@@ -172,6 +182,13 @@ func TestFlattenRKEClusterNodes(t *testing.T) {
 	}
 }
 
+func TestFlattenRKEClusterNodesImport(t *testing.T) {
+	output := flattenRKEClusterNodes([]rancher.RKEConfigNode{{Address: "1.1.1.1", User: "ubuntu"}}, nil)
+	if user := output[0].(map[string]interface{})["user"]; user != "ubuntu" {
+		t.Fatalf("Unexpected user from flattener without prior nodes.\nExpected: %#v\nGiven:    %#v", "ubuntu", user)
+	}
+}
+
 func TestExpandRKEClusterNodeDrainInput(t *testing.T) {
 
 	cases := []struct {
@@ -234,3 +251,121 @@ func TestExpandRKEClusterNodes(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandRKEClusterNodesHostKeys(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput map[string]rkeHostKey
+	}{
+		{
+			testRKEClusterNodesInterface,
+			testRKEClusterNodesHostKeysConf,
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterNodesHostKeys(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestValidateRKEClusterNodesWindows(t *testing.T) {
+	linuxNode := map[string]interface{}{
+		"address": "linux.terraform.test",
+		"os":      rkeClusterNodeOSLinux,
+		"role":    []interface{}{"controlplane", "etcd", "worker"},
+	}
+	windowsWorker := map[string]interface{}{
+		"address": "windows.terraform.test",
+		"os":      rkeClusterNodeOSWindows,
+		"role":    []interface{}{"worker"},
+	}
+	windowsEtcd := map[string]interface{}{
+		"address": "windows.terraform.test",
+		"os":      rkeClusterNodeOSWindows,
+		"role":    []interface{}{"etcd", "worker"},
+	}
+	flannelVxLan := rancher.NetworkConfig{
+		Plugin: rkeClusterNetworkPluginFlannelName,
+		Options: map[string]string{
+			"flannel_backend_type": "vxlan",
+			"flannel_backend_port": "4789",
+			"flannel_backend_vni":  "4096",
+		},
+	}
+
+	clusterYaml, err := cluster.ParseConfig("network:\n  plugin: flannel\n  options:\n    flannel_backend_type: host-gw\n")
+	if err != nil {
+		t.Fatalf("[ERROR] on cluster yaml: %#v", err)
+	}
+
+	cases := []struct {
+		Input         []interface{}
+		Network       rancher.NetworkConfig
+		ExpectedError bool
+	}{
+		{
+			[]interface{}{linuxNode},
+			rancher.NetworkConfig{Plugin: rkeClusterNetworkPluginCanalName},
+			false,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			clusterYaml.Network,
+			false,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			rancher.NetworkConfig{},
+			true,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			flannelVxLan,
+			false,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			rancher.NetworkConfig{
+				Plugin:  rkeClusterNetworkPluginFlannelName,
+				Options: map[string]string{"flannel_backend_type": "host-gw"},
+			},
+			false,
+		},
+		{
+			[]interface{}{linuxNode, windowsEtcd},
+			flannelVxLan,
+			true,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			rancher.NetworkConfig{Plugin: rkeClusterNetworkPluginCanalName},
+			true,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			rancher.NetworkConfig{Plugin: rkeClusterNetworkPluginFlannelName},
+			true,
+		},
+		{
+			[]interface{}{linuxNode, windowsWorker},
+			rancher.NetworkConfig{
+				Plugin:  rkeClusterNetworkPluginFlannelName,
+				Options: map[string]string{"flannel_backend_type": "wireguard"},
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterNodesWindows(tc.Input, tc.Network)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
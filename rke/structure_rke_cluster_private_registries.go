@@ -1,26 +1,85 @@
 package rke
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
+// rkeDockerConfig is the subset of a docker config.json file used to get registry credentials
+type rkeDockerConfig struct {
+	Auths map[string]rkeDockerConfigAuth `json:"auths"`
+}
+
+type rkeDockerConfigAuth struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
 // Flatteners
 
-func flattenRKEClusterPrivateRegistries(p []rancher.PrivateRegistry) []interface{} {
+func flattenRKEClusterPrivateRegistriesECRCredentialPlugin(in *rancher.ECRCredentialPlugin) []interface{} {
+	obj := make(map[string]interface{})
+	if in == nil {
+		return []interface{}{}
+	}
+
+	if len(in.AwsAccessKeyID) > 0 {
+		obj["aws_access_key_id"] = in.AwsAccessKeyID
+	}
+
+	if len(in.AwsSecretAccessKey) > 0 {
+		obj["aws_secret_access_key"] = in.AwsSecretAccessKey
+	}
+
+	if len(in.AwsSessionToken) > 0 {
+		obj["aws_session_token"] = in.AwsSessionToken
+	}
+
+	return []interface{}{obj}
+}
+
+func flattenRKEClusterPrivateRegistries(p []rancher.PrivateRegistry, v []interface{}) []interface{} {
 	out := []interface{}{}
 
+	// Credentials read from docker config files aren't saved on tfstate
+	dockerConfigPaths := map[string]string{}
+	for i := range v {
+		if row, ok := v[i].(map[string]interface{}); ok {
+			if path, ok := row["docker_config_path"].(string); ok && len(path) > 0 {
+				dockerConfigPaths[row["url"].(string)] = path
+			}
+		}
+	}
+
 	for _, in := range p {
 		obj := make(map[string]interface{})
 		obj["is_default"] = in.IsDefault
 
-		if len(in.Password) > 0 {
-			obj["password"] = in.Password
+		if in.ECRCredentialPlugin != nil {
+			obj["ecr_credential_plugin"] = flattenRKEClusterPrivateRegistriesECRCredentialPlugin(in.ECRCredentialPlugin)
 		}
 
 		if len(in.URL) > 0 {
 			obj["url"] = in.URL
 		}
 
+		if path, ok := dockerConfigPaths[in.URL]; ok {
+			obj["docker_config_path"] = path
+			out = append(out, obj)
+			continue
+		}
+
+		if len(in.Password) > 0 {
+			obj["password"] = in.Password
+		}
+
 		if len(in.User) > 0 {
 			obj["user"] = in.User
 		}
@@ -33,10 +92,32 @@ func flattenRKEClusterPrivateRegistries(p []rancher.PrivateRegistry) []interface
 
 // Expanders
 
-func expandRKEClusterPrivateRegistries(p []interface{}) []rancher.PrivateRegistry {
+func expandRKEClusterPrivateRegistriesECRCredentialPlugin(p []interface{}) *rancher.ECRCredentialPlugin {
+	obj := &rancher.ECRCredentialPlugin{}
+	if len(p) == 0 || p[0] == nil {
+		return obj
+	}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["aws_access_key_id"].(string); ok && len(v) > 0 {
+		obj.AwsAccessKeyID = v
+	}
+
+	if v, ok := in["aws_secret_access_key"].(string); ok && len(v) > 0 {
+		obj.AwsSecretAccessKey = v
+	}
+
+	if v, ok := in["aws_session_token"].(string); ok && len(v) > 0 {
+		obj.AwsSessionToken = v
+	}
+
+	return obj
+}
+
+func expandRKEClusterPrivateRegistries(p []interface{}) ([]rancher.PrivateRegistry, error) {
 	out := []rancher.PrivateRegistry{}
 	if len(p) == 0 || p[0] == nil {
-		return out
+		return out, nil
 	}
 
 	for i := range p {
@@ -58,8 +139,139 @@ func expandRKEClusterPrivateRegistries(p []interface{}) []rancher.PrivateRegistr
 		if v, ok := in["user"].(string); ok && len(v) > 0 {
 			obj.User = v
 		}
+
+		hasCredentials := len(obj.User) > 0 || len(obj.Password) > 0
+
+		if v, ok := in["ecr_credential_plugin"].([]interface{}); ok && len(v) > 0 {
+			if hasCredentials {
+				return nil, fmt.Errorf("Registry %s: user and password can't be set with ecr_credential_plugin", obj.URL)
+			}
+			obj.ECRCredentialPlugin = expandRKEClusterPrivateRegistriesECRCredentialPlugin(v)
+		}
+
+		// Credentials are read at apply time by expandRKEClusterPrivateRegistriesDockerConfig
+		if v, ok := in["docker_config_path"].(string); ok && len(v) > 0 {
+			if hasCredentials || obj.ECRCredentialPlugin != nil {
+				return nil, fmt.Errorf("Registry %s: docker_config_path can't be set with user, password or ecr_credential_plugin", obj.URL)
+			}
+		}
+
 		out = append(out, obj)
 	}
 
-	return out
+	return out, nil
+}
+
+// expandRKEClusterPrivateRegistriesDockerConfig sets the docker_config_path credentials on the config passed to RKE,
+// returning the registry urls to remove them from the RKE state saved to tf state
+func expandRKEClusterPrivateRegistriesDockerConfig(p []interface{}, in *rancher.RancherKubernetesEngineConfig) ([]string, error) {
+	out := []string{}
+	if in == nil {
+		return out, nil
+	}
+
+	for i := range p {
+		row, ok := p[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, ok := row["docker_config_path"].(string)
+		if !ok || len(path) == 0 {
+			continue
+		}
+		url, _ := row["url"].(string)
+		for j := range in.PrivateRegistries {
+			if in.PrivateRegistries[j].URL != url {
+				continue
+			}
+			user, password, err := readRKEClusterDockerConfigAuth(path, url)
+			if err != nil {
+				return nil, fmt.Errorf("Registry %s: %v", url, err)
+			}
+			in.PrivateRegistries[j].User = user
+			in.PrivateRegistries[j].Password = password
+			out = append(out, url)
+		}
+	}
+
+	return out, nil
+}
+
+// flattenRKEClusterPrivateRegistriesDockerConfig removes the docker_config_path credentials from the RKE state
+func flattenRKEClusterPrivateRegistriesDockerConfig(urls []string, rkeState string) (string, error) {
+	if len(urls) == 0 || len(rkeState) == 0 {
+		return rkeState, nil
+	}
+
+	state := &cluster.FullState{}
+	if err := json.Unmarshal([]byte(rkeState), state); err != nil {
+		return "", fmt.Errorf("parsing RKE state: %v", err)
+	}
+	for _, config := range []*rancher.RancherKubernetesEngineConfig{state.DesiredState.RancherKubernetesEngineConfig, state.CurrentState.RancherKubernetesEngineConfig} {
+		if config == nil {
+			continue
+		}
+		for i := range config.PrivateRegistries {
+			if slices.Contains(urls, config.PrivateRegistries[i].URL) {
+				config.PrivateRegistries[i].User = ""
+				config.PrivateRegistries[i].Password = ""
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("writing RKE state: %v", err)
+	}
+	return string(out), nil
+}
+
+// readRKEClusterDockerConfigAuth returns the registry user and password from the auths of a docker config.json file
+func readRKEClusterDockerConfigAuth(path, url string) (string, string, error) {
+	path = expandHomePath(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed reading docker config file: %v", err)
+	}
+
+	dockerConfig := &rkeDockerConfig{}
+	if err := json.Unmarshal(data, dockerConfig); err != nil {
+		return "", "", fmt.Errorf("Failed parsing docker config file %s: %v", path, err)
+	}
+
+	registry := normalizeRKEClusterRegistryURL(url)
+	for key, auth := range dockerConfig.Auths {
+		if normalizeRKEClusterRegistryURL(key) != registry {
+			continue
+		}
+		if len(auth.Auth) > 0 {
+			decoded, err := base64Decode(auth.Auth)
+			if err != nil {
+				return "", "", fmt.Errorf("Failed decoding docker config auth for %s: %v", key, err)
+			}
+			user, password, ok := strings.Cut(decoded, ":")
+			if !ok {
+				return "", "", fmt.Errorf("Docker config auth for %s is not user:password", key)
+			}
+			return user, password, nil
+		}
+		if len(auth.Username) > 0 {
+			return auth.Username, auth.Password, nil
+		}
+		return "", "", fmt.Errorf("Docker config auth for %s has no credentials. Credential helpers are not supported", key)
+	}
+
+	return "", "", fmt.Errorf("Docker config file %s has no auth for registry %s", path, url)
+}
+
+// normalizeRKEClusterRegistryURL returns the registry host as used on docker config auths keys
+func normalizeRKEClusterRegistryURL(url string) string {
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "http://")
+	host, _, _ := strings.Cut(url, "/")
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
 }
@@ -1,9 +1,14 @@
 package rke
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/rancher/rke/cluster"
 	rancher "github.com/rancher/rke/types"
 )
 
@@ -20,6 +25,14 @@ func init() {
 			URL:       "url.terraform.test",
 			User:      "user",
 		},
+		{
+			URL: "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			ECRCredentialPlugin: &rancher.ECRCredentialPlugin{
+				AwsAccessKeyID:     "access_key",
+				AwsSecretAccessKey: "secret_key",
+				AwsSessionToken:    "session_token",
+			},
+		},
 	}
 	testRKEClusterPrivateRegistriesInterface = []interface{}{
 		map[string]interface{}{
@@ -28,6 +41,17 @@ func init() {
 			"url":        "url.terraform.test",
 			"user":       "user",
 		},
+		map[string]interface{}{
+			"is_default": false,
+			"url":        "123456789012.dkr.ecr.us-east-1.amazonaws.com",
+			"ecr_credential_plugin": []interface{}{
+				map[string]interface{}{
+					"aws_access_key_id":     "access_key",
+					"aws_secret_access_key": "secret_key",
+					"aws_session_token":     "session_token",
+				},
+			},
+		},
 	}
 }
 
@@ -35,16 +59,40 @@ func TestFlattenPrivateRegistries(t *testing.T) {
 
 	cases := []struct {
 		Input          []rancher.PrivateRegistry
+		InputConfig    []interface{}
 		ExpectedOutput []interface{}
 	}{
 		{
 			testRKEClusterPrivateRegistriesConf,
 			testRKEClusterPrivateRegistriesInterface,
+			testRKEClusterPrivateRegistriesInterface,
+		},
+		{
+			[]rancher.PrivateRegistry{
+				{
+					Password: "XXXXXXXX",
+					URL:      "url.terraform.test",
+					User:     "user",
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"docker_config_path": "config.json",
+					"url":                "url.terraform.test",
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"docker_config_path": "config.json",
+					"is_default":         false,
+					"url":                "url.terraform.test",
+				},
+			},
 		},
 	}
 
 	for _, tc := range cases {
-		output := flattenRKEClusterPrivateRegistries(tc.Input)
+		output := flattenRKEClusterPrivateRegistries(tc.Input, tc.InputConfig)
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
@@ -53,22 +101,134 @@ func TestFlattenPrivateRegistries(t *testing.T) {
 }
 
 func TestExpandPrivateRegistries(t *testing.T) {
+	dockerConfigPath := filepath.Join(t.TempDir(), "config.json")
+	dockerConfig := `{"auths":{"https://url.terraform.test/v2/":{"auth":"dXNlcjpYWFhYWFhYWA=="},"other.terraform.test":{"username":"other","password":"YYYYYYYY"}}}`
+	if err := os.WriteFile(dockerConfigPath, []byte(dockerConfig), 0600); err != nil {
+		t.Fatalf("[ERROR] writing docker config file: %v", err)
+	}
 
 	cases := []struct {
 		Input          []interface{}
 		ExpectedOutput []rancher.PrivateRegistry
+		ExpectedError  bool
 	}{
 		{
 			testRKEClusterPrivateRegistriesInterface,
 			testRKEClusterPrivateRegistriesConf,
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"docker_config_path": dockerConfigPath,
+					"is_default":         true,
+					"url":                "url.terraform.test",
+				},
+				map[string]interface{}{
+					"docker_config_path": dockerConfigPath,
+					"url":                "other.terraform.test",
+				},
+			},
+			[]rancher.PrivateRegistry{
+				{
+					IsDefault: true,
+					Password:  "XXXXXXXX",
+					URL:       "url.terraform.test",
+					User:      "user",
+				},
+				{
+					Password: "YYYYYYYY",
+					URL:      "other.terraform.test",
+					User:     "other",
+				},
+			},
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"docker_config_path": dockerConfigPath,
+					"url":                "missing.terraform.test",
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"docker_config_path": dockerConfigPath,
+					"url":                "url.terraform.test",
+					"user":               "user",
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"ecr_credential_plugin": []interface{}{nil},
+					"password":              "XXXXXXXX",
+					"url":                   "url.terraform.test",
+				},
+			},
+			nil,
+			true,
 		},
 	}
 
 	for _, tc := range cases {
-		output := expandRKEClusterPrivateRegistries(tc.Input)
+		output, err := expandRKEClusterPrivateRegistries(tc.Input)
+		if err == nil {
+			// docker_config_path credentials are set on the config passed to RKE
+			rkeConfig := &rancher.RancherKubernetesEngineConfig{PrivateRegistries: output}
+			_, err = expandRKEClusterPrivateRegistriesDockerConfig(tc.Input, rkeConfig)
+			output = rkeConfig.PrivateRegistries
+		}
+		if tc.ExpectedError {
+			if err == nil {
+				t.Fatalf("Expected error from expander on input %#v", tc.Input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[ERROR] on expander: %#v", err)
+		}
 		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
 			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
 				tc.ExpectedOutput, output)
 		}
 	}
 }
+
+func TestFlattenRKEClusterPrivateRegistriesDockerConfig(t *testing.T) {
+	registries := []rancher.PrivateRegistry{
+		{
+			URL:      "url.terraform.test",
+			User:     "user",
+			Password: "XXXXXXXX",
+		},
+		{
+			URL:      "other.terraform.test",
+			User:     "other",
+			Password: "YYYYYYYY",
+		},
+	}
+	state := cluster.FullState{
+		DesiredState: cluster.State{RancherKubernetesEngineConfig: &rancher.RancherKubernetesEngineConfig{PrivateRegistries: registries}},
+		CurrentState: cluster.State{RancherKubernetesEngineConfig: &rancher.RancherKubernetesEngineConfig{PrivateRegistries: registries}},
+	}
+	rkeState, err := json.Marshal(state)
+	if err != nil {
+		t.Fatalf("[ERROR] marshalling RKE state: %v", err)
+	}
+
+	output, err := flattenRKEClusterPrivateRegistriesDockerConfig([]string{"url.terraform.test"}, string(rkeState))
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	if strings.Contains(output, "XXXXXXXX") || !strings.Contains(output, "YYYYYYYY") {
+		t.Fatalf("Unexpected output from flattener, only docker config credentials should be removed:\n%s", output)
+	}
+}
@@ -19,7 +19,11 @@ func flattenRKEClusterServices(in rancher.RKEConfigServices, p []interface{}) ([
 		v = []interface{}{}
 	}
 	obj["etcd"] = flattenRKEClusterServicesEtcd(in.Etcd, v)
-	kubeAPI, err := flattenRKEClusterServicesKubeAPI(in.KubeAPI)
+	v, ok = obj["kube_api"].([]interface{})
+	if !ok {
+		v = []interface{}{}
+	}
+	kubeAPI, err := flattenRKEClusterServicesKubeAPI(in.KubeAPI, v)
 	if err != nil {
 		return []interface{}{obj}, err
 	}
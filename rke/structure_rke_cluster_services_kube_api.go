@@ -1,18 +1,63 @@
 package rke
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-
+	"net/url"
+	"path"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ghodssyaml "github.com/ghodss/yaml"
 	rancher "github.com/rancher/rke/types"
+	"github.com/rancher/rke/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	apiserverconfig "k8s.io/apiserver/pkg/apis/apiserver"
 	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/apiserver/v1"
+	apiservervalidation "k8s.io/apiserver/pkg/apis/apiserver/validation"
 	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
 	eventratelimitapi "k8s.io/kubernetes/plugin/pkg/admission/eventratelimit/apis/eventratelimit"
 )
 
 // Flatteners
 
+func flattenRKEClusterServicesKubeAPIAdmissionConfiguration(in *apiserverconfigv1.AdmissionConfiguration) ([]interface{}, error) {
+	obj := make(map[string]interface{})
+	if in == nil {
+		return []interface{}{}, nil
+	}
+
+	plugins := make(map[string]interface{})
+	for _, plugin := range in.Plugins {
+		if plugin.Configuration == nil || len(plugin.Configuration.Raw) == 0 {
+			continue
+		}
+		configMap, err := ghodssyamlToMapInterface(string(plugin.Configuration.Raw))
+		if err != nil {
+			return []interface{}{}, fmt.Errorf("unmarshalling %s plugin configuration: %v", plugin.Name, err)
+		}
+		configStr, err := interfaceToGhodssyaml(configMap)
+		if err != nil {
+			return []interface{}{}, fmt.Errorf("marshalling %s plugin configuration yaml: %v", plugin.Name, err)
+		}
+		plugins[plugin.Name] = configStr
+	}
+	if len(plugins) == 0 {
+		return []interface{}{}, nil
+	}
+	obj["plugins"] = plugins
+
+	return []interface{}{obj}, nil
+}
+
 func flattenRKEClusterServicesKubeAPIAuditLogConfig(in *rancher.AuditLogConfig) ([]interface{}, error) {
 	obj := make(map[string]interface{})
 	if in == nil {
@@ -31,9 +76,12 @@ func flattenRKEClusterServicesKubeAPIAuditLogConfig(in *rancher.AuditLogConfig)
 		if err != nil {
 			return []interface{}{}, fmt.Errorf("interface to map err: %v", err)
 		}
-		policyStr, err := interfaceToJSON(policyMap)
+		if metadata, ok := policyMap["metadata"].(map[string]interface{}); ok && len(metadata) == 1 && metadata["creationTimestamp"] == nil {
+			delete(policyMap, "metadata")
+		}
+		policyStr, err := interfaceToGhodssyaml(policyMap)
 		if err != nil {
-			return []interface{}{}, fmt.Errorf("interface to json err: %v", err)
+			return []interface{}{}, fmt.Errorf("interface to yaml err: %v", err)
 		}
 		obj["policy"] = policyStr
 	}
@@ -88,6 +136,33 @@ func flattenRKEClusterServicesKubeAPIEventRateLimit(in *rancher.EventRateLimit)
 	return []interface{}{obj}, nil
 }
 
+// flattenRKEClusterServicesKubeAPISecretsEncryptionKMS returns the kms block if in was generated from one
+func flattenRKEClusterServicesKubeAPISecretsEncryptionKMS(in *apiserverconfigv1.EncryptionConfiguration) []interface{} {
+	if in == nil || len(in.Resources) != 1 || len(in.Resources[0].Providers) == 0 || in.Resources[0].Providers[0].KMS == nil {
+		return []interface{}{}
+	}
+	kms := in.Resources[0].Providers[0].KMS
+	obj := make(map[string]interface{})
+
+	obj["api_version"] = kms.APIVersion
+	if kms.CacheSize != nil {
+		obj["cache_size"] = int(*kms.CacheSize)
+	}
+	obj["endpoint"] = kms.Endpoint
+	obj["name"] = kms.Name
+	if kms.Timeout != nil {
+		obj["timeout"] = kms.Timeout.Duration.String()
+	}
+
+	out := []interface{}{obj}
+	expanded, err := expandRKEClusterServicesKubeAPISecretsEncryptionKMS(out)
+	if err != nil || !reflect.DeepEqual(expanded, in) {
+		return []interface{}{}
+	}
+
+	return out
+}
+
 func flattenRKEClusterServicesKubeAPISecretsEncryptionConfig(in *rancher.SecretsEncryptionConfig) ([]interface{}, error) {
 	obj := make(map[string]interface{})
 	if in == nil {
@@ -96,7 +171,9 @@ func flattenRKEClusterServicesKubeAPISecretsEncryptionConfig(in *rancher.Secrets
 
 	obj["enabled"] = in.Enabled
 
-	if in.CustomConfig != nil {
+	if kms := flattenRKEClusterServicesKubeAPISecretsEncryptionKMS(in.CustomConfig); len(kms) > 0 {
+		obj["kms"] = kms
+	} else if in.CustomConfig != nil {
 		configStr, err := interfaceToGhodssyaml(in.CustomConfig)
 		if err != nil {
 			return []interface{}{}, fmt.Errorf("marshalling custom_config yaml: %v", err)
@@ -107,8 +184,197 @@ func flattenRKEClusterServicesKubeAPISecretsEncryptionConfig(in *rancher.Secrets
 	return []interface{}{obj}, nil
 }
 
-func flattenRKEClusterServicesKubeAPI(in rancher.KubeAPIService) ([]interface{}, error) {
+// flattenRKEClusterEncryptionKeyName returns the name of the key used to encrypt new secrets,
+// that is the first key of the first provider on the encryption provider configuration
+func flattenRKEClusterEncryptionKeyName(in string) string {
+	if len(in) == 0 {
+		return ""
+	}
+	config := &apiserverconfigv1.EncryptionConfiguration{}
+	if err := ghodssyamlToInterface(in, config); err != nil || len(config.Resources) == 0 {
+		return ""
+	}
+	for _, provider := range config.Resources[0].Providers {
+		var keys []apiserverconfigv1.Key
+		switch {
+		case provider.AESCBC != nil:
+			keys = provider.AESCBC.Keys
+		case provider.AESGCM != nil:
+			keys = provider.AESGCM.Keys
+		case provider.Secretbox != nil:
+			keys = provider.Secretbox.Keys
+		case provider.KMS != nil:
+			return provider.KMS.Name
+		}
+		if len(keys) > 0 {
+			return keys[0].Name
+		}
+		return ""
+	}
+	return ""
+}
+
+func removeRKEClusterServicesBind(binds []string, bind string) []string {
+	if len(bind) == 0 {
+		return binds
+	}
+	out := []string{}
+	for _, b := range binds {
+		if b != bind {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// flattenRKEClusterServicesKubeAPIFiles removes the args, bind and env set by expandRKEClusterServicesKubeAPIFiles
+func flattenRKEClusterServicesKubeAPIFiles(in rancher.KubeAPIService) rancher.KubeAPIService {
+	if len(in.ExtraArgs) > 0 {
+		extraArgs := make(map[string]string)
+		for k, v := range in.ExtraArgs {
+			if file, ok := strings.CutPrefix(v, clusterServicesKubeAPIFilesDir+"/"); ok && clusterServicesKubeAPIFilesArgs[file] == k {
+				continue
+			}
+			extraArgs[k] = v
+		}
+		in.ExtraArgs = extraArgs
+	}
+
+	binds := []string{}
+	for _, bind := range in.ExtraBinds {
+		if !strings.HasSuffix(bind, ":"+clusterServicesKubeAPIFilesDir+":ro") {
+			binds = append(binds, bind)
+		}
+	}
+	in.ExtraBinds = binds
+
+	env := []string{}
+	for _, e := range in.ExtraEnv {
+		if !strings.HasPrefix(e, clusterServicesKubeAPIFilesChecksumEnv+"=") {
+			env = append(env, e)
+		}
+	}
+	in.ExtraEnv = env
+
+	return in
+}
+
+// flattenRKEClusterServicesKubeAPIAuditLogWebhook returns the audit_log.webhook block set by args, if any.
+// kubeconfig isn't on args, so it's taken from p if the provider managed file is used
+func flattenRKEClusterServicesKubeAPIAuditLogWebhook(args map[string]string, p []interface{}) []interface{} {
+	obj := make(map[string]interface{})
+	found := false
+	arg := func(name string) (string, bool) {
+		v, ok := args[name]
+		found = found || ok
+		return v, ok
+	}
+
+	if v, ok := arg("audit-webhook-batch-buffer-size"); ok {
+		if i, err := strconv.Atoi(v); err == nil {
+			obj["batch_buffer_size"] = i
+		}
+	}
+
+	if v, ok := arg("audit-webhook-batch-max-size"); ok {
+		if i, err := strconv.Atoi(v); err == nil {
+			obj["batch_max_size"] = i
+		}
+	}
+
+	if v, ok := arg("audit-webhook-batch-max-wait"); ok {
+		obj["batch_max_wait"] = v
+	}
+
+	if v, ok := arg("audit-webhook-batch-throttle-burst"); ok {
+		if i, err := strconv.Atoi(v); err == nil {
+			obj["batch_throttle_burst"] = i
+		}
+	}
+
+	obj["batch_throttle_enable"] = true
+	if v, ok := arg("audit-webhook-batch-throttle-enable"); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			obj["batch_throttle_enable"] = b
+		}
+	}
+
+	if v, ok := arg("audit-webhook-batch-throttle-qps"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			obj["batch_throttle_qps"] = f
+		}
+	}
+
+	if v, ok := arg(clusterServicesKubeAPIAuditLogWebhookConfigArg); ok {
+		if v == path.Join(clusterServicesKubeAPIFilesDir, clusterServicesKubeAPIAuditLogWebhookConfigFile) {
+			if len(p) > 0 && p[0] != nil {
+				if kubeconfig, ok := p[0].(map[string]interface{})["kubeconfig"].(string); ok && len(kubeconfig) > 0 {
+					obj["kubeconfig"] = kubeconfig
+				}
+			}
+		} else {
+			obj["config_file"] = v
+		}
+	}
+
+	if v, ok := arg("audit-webhook-initial-backoff"); ok {
+		obj["initial_backoff"] = v
+	}
+
+	obj["mode"] = clusterServicesKubeAPIAuditLogWebhookModeBatch
+	if v, ok := arg("audit-webhook-mode"); ok {
+		obj["mode"] = v
+	}
+
+	if !found {
+		return nil
+	}
+	return []interface{}{obj}
+}
+
+// rkeClusterServicesKubeAPIInputBinds returns the extra_binds of the kube_api input
+func rkeClusterServicesKubeAPIInputBinds(in map[string]interface{}) []string {
+	if v, ok := in["extra_binds"].([]interface{}); ok {
+		return toArrayString(v)
+	}
+	return nil
+}
+
+func flattenRKEClusterServicesKubeAPI(in rancher.KubeAPIService, p []interface{}) ([]interface{}, error) {
 	obj := make(map[string]interface{})
+	var input map[string]interface{}
+	if len(p) > 0 && p[0] != nil {
+		input = p[0].(map[string]interface{})
+	}
+	// Binds added by the provider are kept if they were also set on extra_binds
+	inputBinds := rkeClusterServicesKubeAPIInputBinds(input)
+
+	// Args and bind added by audit_log.webhook are flattened back to it, unless the args were set on extra_args
+	var webhook []interface{}
+	if in.AuditLog != nil && !rkeClusterServicesKubeAPIAuditLogWebhookOnExtraArgs(input) {
+		webhook = flattenRKEClusterServicesKubeAPIAuditLogWebhook(in.ExtraArgs, rkeClusterServicesKubeAPIAuditLogWebhookInput(input))
+	}
+	if len(webhook) > 0 {
+		webhookArgs := expandRKEClusterServicesKubeAPIAuditLogWebhook(webhook)
+		extraArgs := make(map[string]string)
+		for k, v := range in.ExtraArgs {
+			if _, ok := webhookArgs[k]; !ok {
+				extraArgs[k] = v
+			}
+		}
+		in.ExtraArgs = extraArgs
+		if bind := rkeClusterServicesKubeAPIAuditLogWebhookBind(webhookArgs[clusterServicesKubeAPIAuditLogWebhookConfigArg]); !slices.Contains(inputBinds, bind) {
+			in.ExtraBinds = removeRKEClusterServicesBind(in.ExtraBinds, bind)
+		}
+	}
+
+	if in.AdmissionConfiguration != nil {
+		admissionConfig, err := flattenRKEClusterServicesKubeAPIAdmissionConfiguration(in.AdmissionConfiguration)
+		if err != nil {
+			return []interface{}{}, err
+		}
+		obj["admission_configuration"] = admissionConfig
+	}
 
 	obj["always_pull_images"] = in.AlwaysPullImages
 
@@ -117,6 +383,9 @@ func flattenRKEClusterServicesKubeAPI(in rancher.KubeAPIService) ([]interface{},
 		if err != nil {
 			return []interface{}{}, err
 		}
+		if len(webhook) > 0 && len(auditLog) > 0 {
+			auditLog[0].(map[string]interface{})["webhook"] = webhook
+		}
 		obj["audit_log"] = auditLog
 	}
 
@@ -128,10 +397,18 @@ func flattenRKEClusterServicesKubeAPI(in rancher.KubeAPIService) ([]interface{},
 		obj["event_rate_limit"] = eventRate
 	}
 
+	// Args, bind and env added for provider managed files aren't flattened
+	in = flattenRKEClusterServicesKubeAPIFiles(in)
+
 	if len(in.ExtraArgs) > 0 {
 		obj["extra_args"] = toMapInterface(in.ExtraArgs)
 	}
 
+	// Bind added by kms isn't flattened to avoid a diff with user extra_binds
+	if bind := rkeClusterServicesKubeAPISecretsEncryptionKMSBind(in.SecretsEncryptionConfig); !slices.Contains(inputBinds, bind) {
+		in.ExtraBinds = removeRKEClusterServicesBind(in.ExtraBinds, bind)
+	}
+
 	if len(in.ExtraBinds) > 0 {
 		obj["extra_binds"] = toArrayInterface(in.ExtraBinds)
 	}
@@ -169,6 +446,300 @@ func flattenRKEClusterServicesKubeAPI(in rancher.KubeAPIService) ([]interface{},
 
 // Expanders
 
+func newRKEClusterServicesKubeAPIAdmissionConfiguration(in map[string]interface{}) (*apiserverconfigv1.AdmissionConfiguration, error) {
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plugins := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		v, _ := in[name].(string)
+		configMap, err := ghodssyamlToMapInterface(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s plugin configuration must be in yaml or json format, error: %v", name, err)
+		}
+		if len(configMap) == 0 {
+			return nil, fmt.Errorf("%s plugin configuration can't be empty", name)
+		}
+		plugins = append(plugins, map[string]interface{}{
+			"name":          name,
+			"configuration": configMap,
+		})
+	}
+
+	configStr, err := mapInterfaceToJSON(map[string]interface{}{
+		clusterServicesKubeAPIApiVersionTag: clusterServicesKubeAPIAdmissionConfigurationAPIDefault,
+		clusterServicesKubeAPIKindTag:       clusterServicesKubeAPIAdmissionConfigurationKindDefault,
+		"plugins":                           plugins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling admission configuration json: %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	err = apiserverconfigv1.AddToScheme(scheme)
+	if err != nil {
+		return nil, fmt.Errorf("error adding to scheme: %v", err)
+	}
+	codecs := serializer.NewCodecFactory(scheme)
+	obj := &apiserverconfigv1.AdmissionConfiguration{}
+	err = runtime.DecodeInto(codecs.UniversalDecoder(apiserverconfigv1.SchemeGroupVersion), []byte(configStr), obj)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding admission configuration: %v", err)
+	}
+
+	return obj, nil
+}
+
+func expandRKEClusterServicesKubeAPIAdmissionConfiguration(p []interface{}) (*apiserverconfigv1.AdmissionConfiguration, error) {
+	if p == nil || len(p) == 0 || p[0] == nil {
+		return nil, nil
+	}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["plugins"].(map[string]interface{}); ok && len(v) > 0 {
+		return newRKEClusterServicesKubeAPIAdmissionConfiguration(v)
+	}
+
+	return nil, nil
+}
+
+func newRKEClusterServicesKubeAPIAdmissionPlugin(name string, config interface{}) (apiserverconfigv1.AdmissionPluginConfiguration, error) {
+	plugin := apiserverconfigv1.AdmissionPluginConfiguration{
+		Name: name,
+	}
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return plugin, fmt.Errorf("marshalling %s plugin configuration: %v", name, err)
+	}
+	plugin.Configuration = &runtime.Unknown{
+		ContentType: runtime.ContentTypeJSON,
+		Raw:         configBytes,
+	}
+	return plugin, nil
+}
+
+// newRKEClusterServicesKubeAPIPodSecurityConfig returns the PodSecurity plugin configuration RKE sets for level,
+// or nil if version doesn't support it
+func newRKEClusterServicesKubeAPIPodSecurityConfig(level, version string) (map[string]interface{}, error) {
+	v, err := util.StrToSemVer(version)
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubernetes version %s: %v", version, err)
+	}
+	apiVersion := clusterServicesKubeAPIPodSecurityConfigAPIV1
+	switch {
+	case v.Major == 1 && v.Minor < 23:
+		return nil, nil
+	case v.Major == 1 && v.Minor < 25:
+		apiVersion = clusterServicesKubeAPIPodSecurityConfigAPIV1beta1
+	}
+
+	obj := map[string]interface{}{
+		clusterServicesKubeAPIApiVersionTag: apiVersion,
+		clusterServicesKubeAPIKindTag:       clusterServicesKubeAPIPodSecurityConfigKindDefault,
+	}
+	if level == clusterServicesKubeAPIPodSecurityRestricted {
+		obj["defaults"] = map[string]interface{}{
+			"enforce":         level,
+			"enforce-version": "latest",
+			"audit":           level,
+			"audit-version":   "latest",
+			"warn":            level,
+			"warn-version":    "latest",
+		}
+		obj["exemptions"] = map[string]interface{}{
+			"namespaces": []interface{}{"ingress-nginx", "kube-system"},
+		}
+		return obj, nil
+	}
+	obj["defaults"] = map[string]interface{}{
+		"enforce":         "privileged",
+		"enforce-version": "latest",
+	}
+	return obj, nil
+}
+
+// expandRKEClusterServicesKubeAPIAdmissionConfigurationFile returns the admission configuration file for kube-api,
+// consolidated as RKE does: admission_configuration plugins, then EventRateLimit and PodSecurity from
+// event_rate_limit and pod_security_configuration if they aren't set there
+func expandRKEClusterServicesKubeAPIAdmissionConfigurationFile(in rancher.KubeAPIService, version string) (string, error) {
+	obj := &apiserverconfigv1.AdmissionConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterServicesKubeAPIAdmissionConfigurationKindDefault,
+			APIVersion: clusterServicesKubeAPIAdmissionConfigurationAPIDefault,
+		},
+		Plugins: []apiserverconfigv1.AdmissionPluginConfiguration{},
+	}
+	plugins := make(map[string]bool)
+	if in.AdmissionConfiguration != nil {
+		for _, plugin := range in.AdmissionConfiguration.Plugins {
+			obj.Plugins = append(obj.Plugins, *plugin.DeepCopy())
+			plugins[plugin.Name] = true
+		}
+	}
+
+	if !plugins[clusterServicesKubeAPIEventRateLimitPluginName] {
+		var config interface{} = map[string]interface{}{
+			clusterServicesKubeAPIApiVersionTag: clusterServicesKubeAPIEventRateLimitConfigAPIDefault,
+			clusterServicesKubeAPIKindTag:       clusterServicesKubeAPIEventRateLimitConfigKindDefault,
+			"limits": []interface{}{
+				map[string]interface{}{
+					"type":  "Server",
+					"qps":   5000,
+					"burst": 20000,
+				},
+			},
+		}
+		if in.EventRateLimit != nil && in.EventRateLimit.Enabled && in.EventRateLimit.Configuration != nil {
+			config = in.EventRateLimit.Configuration
+		}
+		plugin, err := newRKEClusterServicesKubeAPIAdmissionPlugin(clusterServicesKubeAPIEventRateLimitPluginName, config)
+		if err != nil {
+			return "", err
+		}
+		obj.Plugins = append(obj.Plugins, plugin)
+	}
+
+	if !plugins[clusterServicesKubeAPIPodSecurityPluginName] {
+		config, err := newRKEClusterServicesKubeAPIPodSecurityConfig(in.PodSecurityConfiguration, version)
+		if err != nil {
+			return "", err
+		}
+		if config != nil {
+			plugin, err := newRKEClusterServicesKubeAPIAdmissionPlugin(clusterServicesKubeAPIPodSecurityPluginName, config)
+			if err != nil {
+				return "", err
+			}
+			obj.Plugins = append(obj.Plugins, plugin)
+		}
+	}
+
+	configBytes, err := json.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("marshalling admission configuration: %v", err)
+	}
+	configYaml, err := ghodssyaml.JSONToYAML(configBytes)
+	if err != nil {
+		return "", fmt.Errorf("marshalling admission configuration yaml: %v", err)
+	}
+	return string(configYaml), nil
+}
+
+// rkeClusterServicesKubeAPIFilesBind returns the kube-api bind of the provider managed files dir
+func rkeClusterServicesKubeAPIFilesBind(prefixPath string) string {
+	return path.Join(prefixPath, clusterServicesKubeAPIFilesDir) + ":" + clusterServicesKubeAPIFilesDir + ":ro"
+}
+
+// rkeClusterServicesKubeAPIFilesChecksum returns the checksum of files content, set on kube-api env to restart it when they change
+func rkeClusterServicesKubeAPIFilesChecksum(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sum := sha256.New()
+	for _, name := range names {
+		sum.Write([]byte(name + "\x00" + files[name] + "\x00"))
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// expandRKEClusterServicesKubeAPIFiles sets kube-api to use the provider managed files, written to
+// clusterServicesKubeAPIFilesDir on control plane nodes
+func expandRKEClusterServicesKubeAPIFiles(in *rancher.KubeAPIService, prefixPath string, files map[string]string) {
+	if len(files) == 0 {
+		return
+	}
+	if in.ExtraArgs == nil {
+		in.ExtraArgs = make(map[string]string)
+	}
+	for file := range files {
+		if arg, ok := clusterServicesKubeAPIFilesArgs[file]; ok {
+			in.ExtraArgs[arg] = path.Join(clusterServicesKubeAPIFilesDir, file)
+		}
+	}
+	in.ExtraBinds = appendRKEClusterServicesBind(in.ExtraBinds, rkeClusterServicesKubeAPIFilesBind(prefixPath))
+	in.ExtraEnv = append(in.ExtraEnv, clusterServicesKubeAPIFilesChecksumEnv+"="+rkeClusterServicesKubeAPIFilesChecksum(files))
+}
+
+// expandRKEClusterServicesKubeAPIAuditLogPolicy decodes a yaml or json audit policy
+func expandRKEClusterServicesKubeAPIAuditLogPolicy(in string) (*auditv1.Policy, error) {
+	policyBytes := []byte(in)
+	scheme := runtime.NewScheme()
+	err := auditv1.AddToScheme(scheme)
+	if err != nil {
+		return nil, fmt.Errorf("error adding to scheme: %v", err)
+	}
+	codecs := serializer.NewCodecFactory(scheme)
+	p := auditv1.Policy{}
+	err = runtime.DecodeInto(codecs.UniversalDecoder(), policyBytes, &p)
+	if err != nil || p.Kind != clusterServicesKubeAPIAuditLogConfigPolicyKindDefault {
+		return nil, fmt.Errorf("error decoding audit policy %s\n: %v", string(policyBytes), err)
+	}
+
+	return &p, nil
+}
+
+func expandRKEClusterServicesKubeAPIAuditLogWebhook(p []interface{}) map[string]string {
+	if p == nil || len(p) == 0 || p[0] == nil {
+		return nil
+	}
+	in := p[0].(map[string]interface{})
+	obj := make(map[string]string)
+
+	if v, ok := in["batch_buffer_size"].(int); ok && v > 0 {
+		obj["audit-webhook-batch-buffer-size"] = strconv.Itoa(v)
+	}
+
+	if v, ok := in["batch_max_size"].(int); ok && v > 0 {
+		obj["audit-webhook-batch-max-size"] = strconv.Itoa(v)
+	}
+
+	if v, ok := in["batch_max_wait"].(string); ok && len(v) > 0 {
+		obj["audit-webhook-batch-max-wait"] = v
+	}
+
+	if v, ok := in["batch_throttle_burst"].(int); ok && v > 0 {
+		obj["audit-webhook-batch-throttle-burst"] = strconv.Itoa(v)
+	}
+
+	if v, ok := in["batch_throttle_enable"].(bool); ok {
+		obj["audit-webhook-batch-throttle-enable"] = strconv.FormatBool(v)
+	}
+
+	if v, ok := in["batch_throttle_qps"].(float64); ok && v > 0 {
+		obj["audit-webhook-batch-throttle-qps"] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	if v, ok := in["kubeconfig"].(string); ok && len(v) > 0 {
+		obj[clusterServicesKubeAPIAuditLogWebhookConfigArg] = path.Join(clusterServicesKubeAPIFilesDir, clusterServicesKubeAPIAuditLogWebhookConfigFile)
+	} else if v, ok := in["config_file"].(string); ok && len(v) > 0 {
+		obj[clusterServicesKubeAPIAuditLogWebhookConfigArg] = v
+	}
+
+	if v, ok := in["initial_backoff"].(string); ok && len(v) > 0 {
+		obj["audit-webhook-initial-backoff"] = v
+	}
+
+	if v, ok := in["mode"].(string); ok && len(v) > 0 {
+		obj["audit-webhook-mode"] = v
+	}
+
+	return obj
+}
+
+// rkeClusterServicesKubeAPIAuditLogWebhookBind returns the kube-api bind needed to read the webhook config file.
+// Files at /etc/kubernetes are already bound by RKE, and provider managed files by expandRKEClusterServicesKubeAPIFiles
+func rkeClusterServicesKubeAPIAuditLogWebhookBind(configFile string) string {
+	configDir := path.Dir(configFile)
+	if len(configFile) == 0 || configDir == clusterServicesKubeAPIFilesDir || configDir == clusterServicesKubeAPIAuditLogWebhookConfigDirDefault || strings.HasPrefix(configDir, clusterServicesKubeAPIAuditLogWebhookConfigDirDefault+"/") {
+		return ""
+	}
+	return configDir + ":" + configDir + ":ro"
+}
+
 func expandRKEClusterServicesKubeAPIAuditLogConfig(p []interface{}) (*rancher.AuditLogConfig, error) {
 	obj := &rancher.AuditLogConfig{}
 	if p == nil || len(p) == 0 || p[0] == nil {
@@ -197,20 +768,11 @@ func expandRKEClusterServicesKubeAPIAuditLogConfig(p []interface{}) (*rancher.Au
 	}
 
 	if v, ok := in["policy"].(string); ok && len(v) > 0 {
-		policyBytes := []byte(v)
-		scheme := runtime.NewScheme()
-		err := auditv1.AddToScheme(scheme)
+		policy, err := expandRKEClusterServicesKubeAPIAuditLogPolicy(v)
 		if err != nil {
-			return nil, fmt.Errorf("error adding to scheme: %v", err)
-		}
-		codecs := serializer.NewCodecFactory(scheme)
-		p := auditv1.Policy{}
-		err = runtime.DecodeInto(codecs.UniversalDecoder(), policyBytes, &p)
-		if err != nil || p.Kind != "Policy" {
-			return nil, fmt.Errorf("error decoding audit policy %s\n: %v", string(policyBytes), err)
+			return nil, err
 		}
-
-		obj.Policy = &p
+		obj.Policy = policy
 	}
 
 	return obj, nil
@@ -269,6 +831,89 @@ func expandRKEClusterServicesKubeAPIEventRateLimit(p []interface{}) (*rancher.Ev
 	return obj, nil
 }
 
+func expandRKEClusterServicesKubeAPISecretsEncryptionKMS(p []interface{}) (*apiserverconfigv1.EncryptionConfiguration, error) {
+	if p == nil || len(p) == 0 || p[0] == nil {
+		return nil, nil
+	}
+	in := p[0].(map[string]interface{})
+	kms := &apiserverconfigv1.KMSConfiguration{}
+
+	if v, ok := in["api_version"].(string); ok && len(v) > 0 {
+		kms.APIVersion = v
+	}
+
+	if v, ok := in["cache_size"].(int); ok && v > 0 {
+		cacheSize := int32(v)
+		kms.CacheSize = &cacheSize
+	}
+
+	if v, ok := in["endpoint"].(string); ok && len(v) > 0 {
+		kms.Endpoint = v
+	}
+
+	if v, ok := in["name"].(string); ok && len(v) > 0 {
+		kms.Name = v
+	}
+
+	if v, ok := in["timeout"].(string); ok && len(v) > 0 {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing kms timeout: %v", err)
+		}
+		kms.Timeout = &metav1.Duration{Duration: timeout}
+	}
+
+	obj := &apiserverconfigv1.EncryptionConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterServicesKubeAPISecretsEncryptionConfigKindDefault,
+			APIVersion: clusterServicesKubeAPISecretsEncryptionConfigAPIDefault,
+		},
+		Resources: []apiserverconfigv1.ResourceConfiguration{
+			{
+				Resources: []string{"secrets"},
+				Providers: []apiserverconfigv1.ProviderConfiguration{
+					{
+						KMS: kms,
+					},
+					{
+						Identity: &apiserverconfigv1.IdentityConfiguration{},
+					},
+				},
+			},
+		},
+	}
+
+	// Validating as kube-apiserver does, once defaults are set
+	defaulted := obj.DeepCopy()
+	apiserverconfigv1.SetObjectDefaults_EncryptionConfiguration(defaulted)
+	internal := &apiserverconfig.EncryptionConfiguration{}
+	if err := apiserverconfigv1.Convert_v1_EncryptionConfiguration_To_apiserver_EncryptionConfiguration(defaulted, internal, nil); err != nil {
+		return nil, fmt.Errorf("converting kms EncryptionConfiguration: %v", err)
+	}
+	if errs := apiservervalidation.ValidateEncryptionConfiguration(internal, false); len(errs) > 0 {
+		return nil, fmt.Errorf("validating kms: %v", errs.ToAggregate())
+	}
+
+	return obj, nil
+}
+
+// rkeClusterServicesKubeAPISecretsEncryptionKMSBind returns the kube-api bind needed to reach the kms plugin socket
+func rkeClusterServicesKubeAPISecretsEncryptionKMSBind(in *rancher.SecretsEncryptionConfig) string {
+	if in == nil || !in.Enabled {
+		return ""
+	}
+	kms := flattenRKEClusterServicesKubeAPISecretsEncryptionKMS(in.CustomConfig)
+	if len(kms) == 0 {
+		return ""
+	}
+	endpoint, err := url.Parse(kms[0].(map[string]interface{})["endpoint"].(string))
+	if err != nil || len(endpoint.Path) == 0 {
+		return ""
+	}
+	socketDir := path.Dir(endpoint.Path)
+	return socketDir + ":" + socketDir
+}
+
 func expandRKEClusterServicesKubeAPISecretsEncryptionConfig(p []interface{}) (*rancher.SecretsEncryptionConfig, error) {
 	obj := &rancher.SecretsEncryptionConfig{}
 	if p == nil || len(p) == 0 || p[0] == nil {
@@ -280,7 +925,13 @@ func expandRKEClusterServicesKubeAPISecretsEncryptionConfig(p []interface{}) (*r
 		obj.Enabled = v
 	}
 
-	if v, ok := in["custom_config"].(string); ok && len(v) > 0 {
+	if v, ok := in["kms"].([]interface{}); ok && len(v) > 0 {
+		kms, err := expandRKEClusterServicesKubeAPISecretsEncryptionKMS(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.CustomConfig = kms
+	} else if v, ok := in["custom_config"].(string); ok && len(v) > 0 {
 		configMap, err := ghodssyamlToMapInterface(v)
 		if err != nil {
 			return obj, fmt.Errorf("unmarshalling custom_config yaml: %v", err)
@@ -300,6 +951,62 @@ func expandRKEClusterServicesKubeAPISecretsEncryptionConfig(p []interface{}) (*r
 	return obj, nil
 }
 
+// rkeClusterServicesKubeAPIAuditLogWebhookOnExtraArgs returns true if audit webhook args are set on extra_args of the kube_api input
+func rkeClusterServicesKubeAPIAuditLogWebhookOnExtraArgs(in map[string]interface{}) bool {
+	extraArgs, _ := in["extra_args"].(map[string]interface{})
+	for k := range extraArgs {
+		if strings.HasPrefix(k, "audit-webhook-") {
+			return true
+		}
+	}
+	return false
+}
+
+// expandRKEClusterServicesKubeAPIInputFiles returns the provider managed kube-api files set on the services input
+func expandRKEClusterServicesKubeAPIInputFiles(p []interface{}) map[string]string {
+	files := make(map[string]string)
+	if len(p) == 0 || p[0] == nil {
+		return files
+	}
+	kubeAPI, ok := p[0].(map[string]interface{})["kube_api"].([]interface{})
+	if !ok || len(kubeAPI) == 0 || kubeAPI[0] == nil {
+		return files
+	}
+	webhook := rkeClusterServicesKubeAPIAuditLogWebhookInput(kubeAPI[0].(map[string]interface{}))
+	if len(webhook) == 0 {
+		return files
+	}
+	if v, ok := webhook[0].(map[string]interface{})["kubeconfig"].(string); ok && len(v) > 0 {
+		files[clusterServicesKubeAPIAuditLogWebhookConfigFile] = v
+	}
+	return files
+}
+
+// rkeClusterServicesKubeAPIAuditLogWebhookInput returns the audit_log.webhook block of the kube_api input, if any
+func rkeClusterServicesKubeAPIAuditLogWebhookInput(in map[string]interface{}) []interface{} {
+	auditLog, ok := in["audit_log"].([]interface{})
+	if !ok || len(auditLog) == 0 || auditLog[0] == nil {
+		return nil
+	}
+	webhook, ok := auditLog[0].(map[string]interface{})["webhook"].([]interface{})
+	if !ok || len(webhook) == 0 || webhook[0] == nil {
+		return nil
+	}
+	return webhook
+}
+
+func appendRKEClusterServicesBind(binds []string, bind string) []string {
+	if len(bind) == 0 {
+		return binds
+	}
+	for _, b := range binds {
+		if b == bind {
+			return binds
+		}
+	}
+	return append(binds, bind)
+}
+
 func expandRKEClusterServicesKubeAPI(p []interface{}) (rancher.KubeAPIService, error) {
 	obj := rancher.KubeAPIService{}
 	if p == nil || len(p) == 0 || p[0] == nil {
@@ -307,6 +1014,14 @@ func expandRKEClusterServicesKubeAPI(p []interface{}) (rancher.KubeAPIService, e
 	}
 	in := p[0].(map[string]interface{})
 
+	if v, ok := in["admission_configuration"].([]interface{}); ok && len(v) > 0 {
+		admissionConfig, err := expandRKEClusterServicesKubeAPIAdmissionConfiguration(v)
+		if err != nil {
+			return obj, err
+		}
+		obj.AdmissionConfiguration = admissionConfig
+	}
+
 	if v, ok := in["always_pull_images"].(bool); ok {
 		obj.AlwaysPullImages = v
 	}
@@ -333,6 +1048,38 @@ func expandRKEClusterServicesKubeAPI(p []interface{}) (rancher.KubeAPIService, e
 
 	if v, ok := in["extra_binds"].([]interface{}); ok && len(v) > 0 {
 		obj.ExtraBinds = toArrayString(v)
+		for _, bind := range obj.ExtraBinds {
+			if parts := strings.Split(bind, ":"); len(parts) > 1 && path.Clean(parts[1]) == clusterServicesKubeAPIFilesDir {
+				return obj, fmt.Errorf("extra_binds %s conflicts with provider managed dir %s", bind, clusterServicesKubeAPIFilesDir)
+			}
+		}
+	}
+
+	if obj.AdmissionConfiguration != nil {
+		if _, ok := obj.ExtraArgs[clusterServicesKubeAPIAdmissionConfigurationArg]; ok {
+			return obj, fmt.Errorf("extra_args %s conflicts with admission_configuration", clusterServicesKubeAPIAdmissionConfigurationArg)
+		}
+	}
+
+	if webhook := rkeClusterServicesKubeAPIAuditLogWebhookInput(in); len(webhook) > 0 {
+		if obj.AuditLog == nil || !obj.AuditLog.Enabled {
+			return obj, fmt.Errorf("audit_log.webhook requires audit_log to be enabled")
+		}
+		configFile, _ := webhook[0].(map[string]interface{})["config_file"].(string)
+		kubeconfig, _ := webhook[0].(map[string]interface{})["kubeconfig"].(string)
+		if (len(configFile) > 0) == (len(kubeconfig) > 0) {
+			return obj, fmt.Errorf("audit_log.webhook requires one of config_file or kubeconfig")
+		}
+		if obj.ExtraArgs == nil {
+			obj.ExtraArgs = make(map[string]string)
+		}
+		for k, v := range expandRKEClusterServicesKubeAPIAuditLogWebhook(webhook) {
+			if _, ok := obj.ExtraArgs[k]; ok {
+				return obj, fmt.Errorf("extra_args %s conflicts with audit_log.webhook", k)
+			}
+			obj.ExtraArgs[k] = v
+		}
+		obj.ExtraBinds = appendRKEClusterServicesBind(obj.ExtraBinds, rkeClusterServicesKubeAPIAuditLogWebhookBind(obj.ExtraArgs["audit-webhook-config-file"]))
 	}
 
 	if v, ok := in["extra_env"].([]interface{}); ok && len(v) > 0 {
@@ -353,6 +1100,7 @@ func expandRKEClusterServicesKubeAPI(p []interface{}) (rancher.KubeAPIService, e
 			return obj, err
 		}
 		obj.SecretsEncryptionConfig = secretEnc
+		obj.ExtraBinds = appendRKEClusterServicesBind(obj.ExtraBinds, rkeClusterServicesKubeAPISecretsEncryptionKMSBind(secretEnc))
 	}
 
 	if v, ok := in["service_cluster_ip_range"].(string); ok && len(v) > 0 {
@@ -365,3 +1113,15 @@ func expandRKEClusterServicesKubeAPI(p []interface{}) (rancher.KubeAPIService, e
 
 	return obj, nil
 }
+
+// Validators
+
+func validateRKEClusterRotateEncryptionKey(in rancher.KubeAPIService) error {
+	if in.SecretsEncryptionConfig == nil || !in.SecretsEncryptionConfig.Enabled {
+		return fmt.Errorf("rotate_encryption_key requires services.kube_api.secrets_encryption_config to be enabled")
+	}
+	if in.SecretsEncryptionConfig.CustomConfig != nil {
+		return fmt.Errorf("rotate_encryption_key is not supported with services.kube_api.secrets_encryption_config custom_config or kms")
+	}
+	return nil
+}
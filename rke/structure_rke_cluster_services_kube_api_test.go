@@ -3,15 +3,19 @@ package rke
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	rancher "github.com/rancher/rke/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/apiserver/v1"
 	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
 	eventratelimitapi "k8s.io/kubernetes/plugin/pkg/admission/eventratelimit/apis/eventratelimit"
 )
 
 var (
+	testRKEClusterServicesKubeAPIAdmissionConfigurationConf       *apiserverconfigv1.AdmissionConfiguration
+	testRKEClusterServicesKubeAPIAdmissionConfigurationInterface  []interface{}
 	testRKEClusterServicesKubeAPIAuditLogConfigConf               *rancher.AuditLogConfig
 	testRKEClusterServicesKubeAPIAuditLogConfigInterface          []interface{}
 	testRKEClusterServicesKubeAPIAuditLogConf                     *rancher.AuditLog
@@ -19,12 +23,36 @@ var (
 	testRKEClusterServicesKubeAPIEventRateLimitConf               *rancher.EventRateLimit
 	testRKEClusterServicesKubeAPIEventRateLimitInterface          []interface{}
 	testRKEClusterServicesKubeAPISecretsEncryptionConfigConf      *rancher.SecretsEncryptionConfig
+	testRKEClusterServicesKubeAPISecretsEncryptionKMSConf         *apiserverconfigv1.EncryptionConfiguration
+	testRKEClusterServicesKubeAPISecretsEncryptionKMSInterface    []interface{}
 	testRKEClusterServicesKubeAPISecretsEncryptionConfigInterface []interface{}
 	testRKEClusterServicesKubeAPIConf                             rancher.KubeAPIService
 	testRKEClusterServicesKubeAPIInterface                        []interface{}
 )
 
 func init() {
+	testRKEClusterServicesKubeAPIAdmissionConfigurationConf = &apiserverconfigv1.AdmissionConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterServicesKubeAPIAdmissionConfigurationKindDefault,
+			APIVersion: clusterServicesKubeAPIAdmissionConfigurationAPIDefault,
+		},
+		Plugins: []apiserverconfigv1.AdmissionPluginConfiguration{
+			{
+				Name: "ResourceQuota",
+				Configuration: &runtime.Unknown{
+					Raw:         []byte(`{"apiVersion":"apiserver.config.k8s.io/v1","kind":"ResourceQuotaConfiguration","limitedResources":[{"matchContains":["high"],"resource":"pods"}]}`),
+					ContentType: runtime.ContentTypeJSON,
+				},
+			},
+		},
+	}
+	testRKEClusterServicesKubeAPIAdmissionConfigurationInterface = []interface{}{
+		map[string]interface{}{
+			"plugins": map[string]interface{}{
+				"ResourceQuota": "apiVersion: apiserver.config.k8s.io/v1\nkind: ResourceQuotaConfiguration\nlimitedResources:\n- matchContains:\n  - high\n  resource: pods\n",
+			},
+		},
+	}
 	testRKEClusterServicesKubeAPIAuditLogConfigConf = &rancher.AuditLogConfig{
 		Format:    "format",
 		MaxAge:    5,
@@ -56,7 +84,7 @@ func init() {
 			"max_backup": 10,
 			"max_size":   100,
 			"path":       "path",
-			"policy":     `{"apiVersion":"` + clusterServicesKubeAPIAuditLogConfigPolicyAPIDefault + `","kind":"` + clusterServicesKubeAPIAuditLogConfigPolicyKindDefault + `","metadata":{"creationTimestamp":null},"rules":[{"level":"RequestResponse","resources":[{"group":"*","resources":["pods"]}]}]}`,
+			"policy":     "apiVersion: " + clusterServicesKubeAPIAuditLogConfigPolicyAPIDefault + "\nkind: " + clusterServicesKubeAPIAuditLogConfigPolicyKindDefault + "\nrules:\n- level: RequestResponse\n  resources:\n  - group: '*'\n    resources:\n    - pods\n",
 		},
 	}
 	testRKEClusterServicesKubeAPIAuditLogConf = &rancher.AuditLog{
@@ -124,7 +152,40 @@ func init() {
 			"custom_config": "apiVersion: " + clusterServicesKubeAPISecretsEncryptionConfigAPIDefault + "\nkind: " + clusterServicesKubeAPISecretsEncryptionConfigKindDefault + "\nresources:\n- resources:\n  - secrets\n  providers:\n  - aescbc:\n      keys:\n      - name: k-fw5hn\n        secret: RTczRjFDODMwQzAyMDVBREU4NDJBMUZFNDhCNzM5N0I=\n    identity: {}\n",
 		},
 	}
+	testRKEClusterServicesKubeAPISecretsEncryptionKMSConf = &apiserverconfigv1.EncryptionConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       clusterServicesKubeAPISecretsEncryptionConfigKindDefault,
+			APIVersion: clusterServicesKubeAPISecretsEncryptionConfigAPIDefault,
+		},
+		Resources: []apiserverconfigv1.ResourceConfiguration{
+			{
+				Resources: []string{"secrets"},
+				Providers: []apiserverconfigv1.ProviderConfiguration{
+					{
+						KMS: &apiserverconfigv1.KMSConfiguration{
+							APIVersion: "v2",
+							Name:       "vault",
+							Endpoint:   "unix:///var/run/kmsplugin/socket.sock",
+							Timeout:    &metav1.Duration{Duration: 5 * time.Second},
+						},
+					},
+					{
+						Identity: &apiserverconfigv1.IdentityConfiguration{},
+					},
+				},
+			},
+		},
+	}
+	testRKEClusterServicesKubeAPISecretsEncryptionKMSInterface = []interface{}{
+		map[string]interface{}{
+			"api_version": "v2",
+			"endpoint":    "unix:///var/run/kmsplugin/socket.sock",
+			"name":        "vault",
+			"timeout":     "5s",
+		},
+	}
 	testRKEClusterServicesKubeAPIConf = rancher.KubeAPIService{
+		AdmissionConfiguration:  testRKEClusterServicesKubeAPIAdmissionConfigurationConf,
 		AlwaysPullImages:        true,
 		AuditLog:                testRKEClusterServicesKubeAPIAuditLogConf,
 		EventRateLimit:          testRKEClusterServicesKubeAPIEventRateLimitConf,
@@ -141,9 +202,10 @@ func init() {
 	testRKEClusterServicesKubeAPIConf.Image = "image"
 	testRKEClusterServicesKubeAPIInterface = []interface{}{
 		map[string]interface{}{
-			"always_pull_images": true,
-			"audit_log":          testRKEClusterServicesKubeAPIAuditLogInterface,
-			"event_rate_limit":   testRKEClusterServicesKubeAPIEventRateLimitInterface,
+			"admission_configuration": testRKEClusterServicesKubeAPIAdmissionConfigurationInterface,
+			"always_pull_images":      true,
+			"audit_log":               testRKEClusterServicesKubeAPIAuditLogInterface,
+			"event_rate_limit":        testRKEClusterServicesKubeAPIEventRateLimitInterface,
 			"extra_args": map[string]interface{}{
 				"arg_one": "one",
 				"arg_two": "two",
@@ -159,6 +221,30 @@ func init() {
 	}
 }
 
+func TestFlattenRKEClusterServicesKubeAPIAdmissionConfiguration(t *testing.T) {
+
+	cases := []struct {
+		Input          *apiserverconfigv1.AdmissionConfiguration
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterServicesKubeAPIAdmissionConfigurationConf,
+			testRKEClusterServicesKubeAPIAdmissionConfigurationInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := flattenRKEClusterServicesKubeAPIAdmissionConfiguration(tc.Input)
+		if err != nil {
+			t.Fatalf("Error on flattenRKEClusterServicesKubeAPIAdmissionConfiguration: %#v", err)
+		}
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
 func TestFlattenRKEClusterServicesKubeAPIAuditLogConfig(t *testing.T) {
 
 	cases := []struct {
@@ -280,7 +366,7 @@ func TestFlattenRKEClusterServicesKubeAPI(t *testing.T) {
 	}
 
 	for _, tc := range cases {
-		output, err := flattenRKEClusterServicesKubeAPI(tc.Input)
+		output, err := flattenRKEClusterServicesKubeAPI(tc.Input, nil)
 		if err != nil {
 			t.Fatalf("[ERROR] on flattener: %#v", err)
 		}
@@ -297,6 +383,66 @@ func TestFlattenRKEClusterServicesKubeAPI(t *testing.T) {
 	}
 }
 
+func TestExpandRKEClusterServicesKubeAPIAdmissionConfiguration(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *apiserverconfigv1.AdmissionConfiguration
+		ExpectedError  bool
+	}{
+		{
+			testRKEClusterServicesKubeAPIAdmissionConfigurationInterface,
+			testRKEClusterServicesKubeAPIAdmissionConfigurationConf,
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"plugins": map[string]interface{}{
+						"ResourceQuota": `{"apiVersion":"apiserver.config.k8s.io/v1","kind":"ResourceQuotaConfiguration","limitedResources":[{"resource":"pods","matchContains":["high"]}]}`,
+					},
+				},
+			},
+			testRKEClusterServicesKubeAPIAdmissionConfigurationConf,
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"plugins": map[string]interface{}{
+						"ResourceQuota": "- pods",
+					},
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"plugins": map[string]interface{}{
+						"ResourceQuota": "",
+					},
+				},
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := expandRKEClusterServicesKubeAPIAdmissionConfiguration(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected error from expander on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
 func TestExpandRKEClusterServicesKubeAPIAuditLogConfig(t *testing.T) {
 
 	cases := []struct {
@@ -307,6 +453,19 @@ func TestExpandRKEClusterServicesKubeAPIAuditLogConfig(t *testing.T) {
 			testRKEClusterServicesKubeAPIAuditLogConfigInterface,
 			testRKEClusterServicesKubeAPIAuditLogConfigConf,
 		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"format":     "format",
+					"max_age":    5,
+					"max_backup": 10,
+					"max_size":   100,
+					"path":       "path",
+					"policy":     `{"apiVersion":"` + clusterServicesKubeAPIAuditLogConfigPolicyAPIDefault + `","kind":"` + clusterServicesKubeAPIAuditLogConfigPolicyKindDefault + `","metadata":{"creationTimestamp":null},"rules":[{"level":"RequestResponse","resources":[{"group":"*","resources":["pods"]}]}]}`,
+				},
+			},
+			testRKEClusterServicesKubeAPIAuditLogConfigConf,
+		},
 	}
 
 	for _, tc := range cases {
@@ -417,3 +576,597 @@ func TestExpandRKEClusterServicesKubeAPI(t *testing.T) {
 		}
 	}
 }
+
+func TestFlattenRKEClusterEncryptionKeyName(t *testing.T) {
+
+	cases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			testRKEClusterServicesKubeAPISecretsEncryptionConfigInterface[0].(map[string]interface{})["custom_config"].(string),
+			"k-fw5hn",
+		},
+		{
+			"apiVersion: apiserver.config.k8s.io/v1\nkind: EncryptionConfiguration\nresources:\n- resources:\n  - secrets\n  providers:\n  - identity: {}\n  - aescbc:\n      keys:\n      - name: k-fw5hn\n        secret: RTczRjFDODMwQzAyMDVBREU4NDJBMUZFNDhCNzM5N0I=\n",
+			"",
+		},
+		{
+			"",
+			"",
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterEncryptionKeyName(tc.Input)
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestValidateRKEClusterRotateEncryptionKey(t *testing.T) {
+
+	cases := []struct {
+		Input         rancher.KubeAPIService
+		ExpectedError bool
+	}{
+		{
+			rancher.KubeAPIService{
+				SecretsEncryptionConfig: &rancher.SecretsEncryptionConfig{Enabled: true},
+			},
+			false,
+		},
+		{
+			rancher.KubeAPIService{},
+			true,
+		},
+		{
+			rancher.KubeAPIService{
+				SecretsEncryptionConfig: &rancher.SecretsEncryptionConfig{Enabled: false},
+			},
+			true,
+		},
+		{
+			rancher.KubeAPIService{
+				SecretsEncryptionConfig: testRKEClusterServicesKubeAPISecretsEncryptionConfigConf,
+			},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterRotateEncryptionKey(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
+
+func TestFlattenRKEClusterServicesKubeAPISecretsEncryptionKMS(t *testing.T) {
+
+	cases := []struct {
+		Input          *apiserverconfigv1.EncryptionConfiguration
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterServicesKubeAPISecretsEncryptionKMSConf,
+			testRKEClusterServicesKubeAPISecretsEncryptionKMSInterface,
+		},
+		{
+			testRKEClusterServicesKubeAPISecretsEncryptionConfigConf.CustomConfig,
+			[]interface{}{},
+		},
+		{
+			nil,
+			[]interface{}{},
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterServicesKubeAPISecretsEncryptionKMS(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPISecretsEncryptionKMS(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *apiserverconfigv1.EncryptionConfiguration
+		ExpectedError  bool
+	}{
+		{
+			testRKEClusterServicesKubeAPISecretsEncryptionKMSInterface,
+			testRKEClusterServicesKubeAPISecretsEncryptionKMSConf,
+			false,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"api_version": "v2",
+					"cache_size":  1000,
+					"endpoint":    "unix:///var/run/kmsplugin/socket.sock",
+					"name":        "vault",
+					"timeout":     "5s",
+				},
+			},
+			nil,
+			true,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"api_version": "v2",
+					"endpoint":    "tcp://127.0.0.1:8080",
+					"name":        "vault",
+					"timeout":     "5s",
+				},
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := expandRKEClusterServicesKubeAPISecretsEncryptionKMS(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected error from expander on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPISecretsEncryptionKMSBind(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{
+			"extra_binds": []interface{}{"/opt/kms:/opt/kms"},
+			"secrets_encryption_config": []interface{}{
+				map[string]interface{}{
+					"enabled": true,
+					"kms":     testRKEClusterServicesKubeAPISecretsEncryptionKMSInterface,
+				},
+			},
+		},
+	}
+	expectedBinds := []string{"/opt/kms:/opt/kms", "/var/run/kmsplugin:/var/run/kmsplugin"}
+
+	output, err := expandRKEClusterServicesKubeAPI(input)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if !reflect.DeepEqual(output.ExtraBinds, expectedBinds) {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+			expectedBinds, output.ExtraBinds)
+	}
+
+	flattened, err := flattenRKEClusterServicesKubeAPI(output, nil)
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	flattenedBinds := flattened[0].(map[string]interface{})["extra_binds"]
+	if !reflect.DeepEqual(flattenedBinds, input[0].(map[string]interface{})["extra_binds"]) {
+		t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+			input[0].(map[string]interface{})["extra_binds"], flattenedBinds)
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPIAuditLogWebhook(t *testing.T) {
+	webhook := []interface{}{
+		map[string]interface{}{
+			"batch_max_size":        100,
+			"batch_max_wait":        "5s",
+			"batch_throttle_enable": true,
+			"batch_throttle_qps":    2.5,
+			"config_file":           "/opt/audit/webhook.yaml",
+			"mode":                  "batch",
+		},
+	}
+	input := []interface{}{
+		map[string]interface{}{
+			"audit_log": []interface{}{
+				map[string]interface{}{
+					"enabled": true,
+					"webhook": webhook,
+				},
+			},
+			"extra_args": map[string]interface{}{
+				"arg_one": "one",
+			},
+		},
+	}
+	expectedArgs := map[string]string{
+		"arg_one":                             "one",
+		"audit-webhook-batch-max-size":        "100",
+		"audit-webhook-batch-max-wait":        "5s",
+		"audit-webhook-batch-throttle-enable": "true",
+		"audit-webhook-batch-throttle-qps":    "2.5",
+		"audit-webhook-config-file":           "/opt/audit/webhook.yaml",
+		"audit-webhook-mode":                  "batch",
+	}
+	expectedBinds := []string{"/opt/audit:/opt/audit:ro"}
+
+	output, err := expandRKEClusterServicesKubeAPI(input)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if !reflect.DeepEqual(output.ExtraArgs, expectedArgs) || !reflect.DeepEqual(output.ExtraBinds, expectedBinds) {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v %#v\nGiven:    %#v %#v",
+			expectedArgs, expectedBinds, output.ExtraArgs, output.ExtraBinds)
+	}
+
+	flattened, err := flattenRKEClusterServicesKubeAPI(output, input)
+	if err != nil {
+		t.Fatalf("[ERROR] on flattener: %#v", err)
+	}
+	flattenedObj := flattened[0].(map[string]interface{})
+	if !reflect.DeepEqual(flattenedObj["extra_args"], input[0].(map[string]interface{})["extra_args"]) {
+		t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+			input[0].(map[string]interface{})["extra_args"], flattenedObj["extra_args"])
+	}
+	if _, ok := flattenedObj["extra_binds"]; ok {
+		t.Fatalf("Unexpected output from flattener.\nExpected: no extra_binds\nGiven:    %#v", flattenedObj["extra_binds"])
+	}
+	flattenedWebhook := flattenedObj["audit_log"].([]interface{})[0].(map[string]interface{})["webhook"]
+	if !reflect.DeepEqual(flattenedWebhook, webhook) {
+		t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v", webhook, flattenedWebhook)
+	}
+
+	input[0].(map[string]interface{})["extra_args"] = map[string]interface{}{
+		"audit-webhook-mode": "blocking",
+	}
+	if _, err := expandRKEClusterServicesKubeAPI(input); err == nil {
+		t.Fatalf("Unexpected output from expander.\nExpected error on conflicting extra_args")
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPIAdmissionConfigurationFile(t *testing.T) {
+	userPlugins, err := newRKEClusterServicesKubeAPIAdmissionConfiguration(map[string]interface{}{
+		"ImagePolicyWebhook": "imagePolicy:\n  kubeConfigFile: /etc/kubernetes/image-policy.yaml\n  defaultAllow: false\n",
+	})
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	userPodSecurity, err := newRKEClusterServicesKubeAPIAdmissionConfiguration(map[string]interface{}{
+		"PodSecurity": "apiVersion: pod-security.admission.config.k8s.io/v1\nkind: PodSecurityConfiguration\ndefaults:\n  enforce: baseline\n",
+	})
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+
+	cases := []struct {
+		Input          rancher.KubeAPIService
+		Version        string
+		ExpectedOutput string
+	}{
+		{
+			rancher.KubeAPIService{
+				AdmissionConfiguration:   userPlugins,
+				PodSecurityConfiguration: "restricted",
+			},
+			"v1.30.4-rancher1-1",
+			`apiVersion: apiserver.config.k8s.io/v1
+kind: AdmissionConfiguration
+plugins:
+- configuration:
+    imagePolicy:
+      defaultAllow: false
+      kubeConfigFile: /etc/kubernetes/image-policy.yaml
+  name: ImagePolicyWebhook
+  path: ""
+- configuration:
+    apiVersion: eventratelimit.admission.k8s.io/v1alpha1
+    kind: Configuration
+    limits:
+    - burst: 20000
+      qps: 5000
+      type: Server
+  name: EventRateLimit
+  path: ""
+- configuration:
+    apiVersion: pod-security.admission.config.k8s.io/v1
+    defaults:
+      audit: restricted
+      audit-version: latest
+      enforce: restricted
+      enforce-version: latest
+      warn: restricted
+      warn-version: latest
+    exemptions:
+      namespaces:
+      - ingress-nginx
+      - kube-system
+    kind: PodSecurityConfiguration
+  name: PodSecurity
+  path: ""
+`,
+		},
+		{
+			rancher.KubeAPIService{
+				AdmissionConfiguration: userPodSecurity,
+			},
+			"v1.24.17-rancher1-1",
+			`apiVersion: apiserver.config.k8s.io/v1
+kind: AdmissionConfiguration
+plugins:
+- configuration:
+    apiVersion: pod-security.admission.config.k8s.io/v1
+    defaults:
+      enforce: baseline
+    kind: PodSecurityConfiguration
+  name: PodSecurity
+  path: ""
+- configuration:
+    apiVersion: eventratelimit.admission.k8s.io/v1alpha1
+    kind: Configuration
+    limits:
+    - burst: 20000
+      qps: 5000
+      type: Server
+  name: EventRateLimit
+  path: ""
+`,
+		},
+		{
+			rancher.KubeAPIService{
+				AdmissionConfiguration: userPlugins,
+			},
+			"v1.22.17-rancher1-2",
+			`apiVersion: apiserver.config.k8s.io/v1
+kind: AdmissionConfiguration
+plugins:
+- configuration:
+    imagePolicy:
+      defaultAllow: false
+      kubeConfigFile: /etc/kubernetes/image-policy.yaml
+  name: ImagePolicyWebhook
+  path: ""
+- configuration:
+    apiVersion: eventratelimit.admission.k8s.io/v1alpha1
+    kind: Configuration
+    limits:
+    - burst: 20000
+      qps: 5000
+      type: Server
+  name: EventRateLimit
+  path: ""
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := expandRKEClusterServicesKubeAPIAdmissionConfigurationFile(tc.Input, tc.Version)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander: %#v", err)
+		}
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output from expander.\nExpected: %s\nGiven:    %s",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPIFiles(t *testing.T) {
+	input := rancher.KubeAPIService{
+		BaseService: rancher.BaseService{
+			ExtraArgs:  map[string]string{"arg_one": "one"},
+			ExtraBinds: []string{"/opt/one:/opt/one"},
+			ExtraEnv:   []string{"ENV_ONE=one"},
+		},
+	}
+	files := map[string]string{
+		clusterServicesKubeAPIAdmissionConfigurationFile: "apiVersion: apiserver.config.k8s.io/v1\nkind: AdmissionConfiguration\n",
+	}
+	expectedArgs := map[string]string{
+		"arg_one":                       "one",
+		"admission-control-config-file": "/etc/rke-provider/kube-api/admission.yaml",
+	}
+	expectedBinds := []string{"/opt/one:/opt/one", "/opt/rke/etc/rke-provider/kube-api:/etc/rke-provider/kube-api:ro"}
+
+	output := *input.DeepCopy()
+	expandRKEClusterServicesKubeAPIFiles(&output, "/opt/rke", files)
+	if !reflect.DeepEqual(output.ExtraArgs, expectedArgs) || !reflect.DeepEqual(output.ExtraBinds, expectedBinds) || len(output.ExtraEnv) != 2 {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v %#v\nGiven:    %#v %#v %#v",
+			expectedArgs, expectedBinds, output.ExtraArgs, output.ExtraBinds, output.ExtraEnv)
+	}
+
+	flattened := flattenRKEClusterServicesKubeAPIFiles(output)
+	if !reflect.DeepEqual(flattened, input) {
+		t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v", input, flattened)
+	}
+
+	if _, err := expandRKEClusterServicesKubeAPI([]interface{}{
+		map[string]interface{}{
+			"extra_binds": []interface{}{"/opt/files:/etc/rke-provider/kube-api"},
+		},
+	}); err == nil {
+		t.Fatalf("Unexpected output from expander.\nExpected error on conflicting extra_binds")
+	}
+}
+
+func TestFlattenRKEClusterServicesKubeAPIAuditLogWebhook(t *testing.T) {
+	cases := []struct {
+		Input          rancher.KubeAPIService
+		Prior          []interface{}
+		ExpectedOutput map[string]interface{}
+	}{
+		// Imported or changed args are flattened from RKE state
+		{
+			rancher.KubeAPIService{
+				AuditLog: &rancher.AuditLog{Enabled: true},
+				BaseService: rancher.BaseService{
+					ExtraArgs: map[string]string{
+						"arg_one":                      "one",
+						"audit-webhook-batch-max-size": "200",
+						"audit-webhook-config-file":    "/opt/audit/webhook.yaml",
+						"audit-webhook-mode":           "blocking",
+					},
+					ExtraBinds: []string{"/opt/audit:/opt/audit:ro"},
+				},
+			},
+			nil,
+			map[string]interface{}{
+				"audit_log": []interface{}{
+					map[string]interface{}{
+						"configuration": []interface{}{},
+						"enabled":       true,
+						"webhook": []interface{}{
+							map[string]interface{}{
+								"batch_max_size":        200,
+								"batch_throttle_enable": true,
+								"config_file":           "/opt/audit/webhook.yaml",
+								"mode":                  "blocking",
+							},
+						},
+					},
+				},
+				"extra_args": map[string]interface{}{
+					"arg_one": "one",
+				},
+			},
+		},
+		// Bind also set on extra_binds is kept, and kubeconfig is taken from prior state
+		{
+			rancher.KubeAPIService{
+				AuditLog: &rancher.AuditLog{Enabled: true},
+				BaseService: rancher.BaseService{
+					ExtraArgs: map[string]string{
+						"audit-webhook-config-file": "/etc/rke-provider/kube-api/audit-webhook-config.yaml",
+					},
+					ExtraBinds: []string{"/opt/audit:/opt/audit:ro"},
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"audit_log": []interface{}{
+						map[string]interface{}{
+							"webhook": []interface{}{
+								map[string]interface{}{
+									"kubeconfig": "apiVersion: v1\nkind: Config\n",
+								},
+							},
+						},
+					},
+					"extra_binds": []interface{}{"/opt/audit:/opt/audit:ro"},
+				},
+			},
+			map[string]interface{}{
+				"audit_log": []interface{}{
+					map[string]interface{}{
+						"configuration": []interface{}{},
+						"enabled":       true,
+						"webhook": []interface{}{
+							map[string]interface{}{
+								"batch_throttle_enable": true,
+								"kubeconfig":            "apiVersion: v1\nkind: Config\n",
+								"mode":                  "batch",
+							},
+						},
+					},
+				},
+				"extra_binds": []interface{}{"/opt/audit:/opt/audit:ro"},
+			},
+		},
+		// Args set on extra_args are kept there
+		{
+			rancher.KubeAPIService{
+				AuditLog: &rancher.AuditLog{Enabled: true},
+				BaseService: rancher.BaseService{
+					ExtraArgs: map[string]string{
+						"audit-webhook-config-file": "/etc/kubernetes/webhook.yaml",
+					},
+				},
+			},
+			[]interface{}{
+				map[string]interface{}{
+					"extra_args": map[string]interface{}{
+						"audit-webhook-config-file": "/etc/kubernetes/webhook.yaml",
+					},
+				},
+			},
+			map[string]interface{}{
+				"audit_log": []interface{}{
+					map[string]interface{}{
+						"configuration": []interface{}{},
+						"enabled":       true,
+					},
+				},
+				"extra_args": map[string]interface{}{
+					"audit-webhook-config-file": "/etc/kubernetes/webhook.yaml",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output, err := flattenRKEClusterServicesKubeAPI(tc.Input, tc.Prior)
+		if err != nil {
+			t.Fatalf("[ERROR] on flattener: %#v", err)
+		}
+		obj := output[0].(map[string]interface{})
+		delete(obj, "always_pull_images")
+		if !reflect.DeepEqual(obj, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, obj)
+		}
+	}
+}
+
+func TestExpandRKEClusterServicesKubeAPIInputFiles(t *testing.T) {
+	input := []interface{}{
+		map[string]interface{}{
+			"kube_api": []interface{}{
+				map[string]interface{}{
+					"audit_log": []interface{}{
+						map[string]interface{}{
+							"enabled": true,
+							"webhook": []interface{}{
+								map[string]interface{}{
+									"batch_throttle_enable": true,
+									"kubeconfig":            "apiVersion: v1\nkind: Config\n",
+									"mode":                  "batch",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	expectedFiles := map[string]string{
+		"audit-webhook-config.yaml": "apiVersion: v1\nkind: Config\n",
+	}
+	expectedArgs := map[string]string{
+		"audit-webhook-batch-throttle-enable": "true",
+		"audit-webhook-config-file":           "/etc/rke-provider/kube-api/audit-webhook-config.yaml",
+		"audit-webhook-mode":                  "batch",
+	}
+
+	files := expandRKEClusterServicesKubeAPIInputFiles(input)
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v", expectedFiles, files)
+	}
+	kubeAPIInput := input[0].(map[string]interface{})["kube_api"].([]interface{})
+	output, err := expandRKEClusterServicesKubeAPI(kubeAPIInput)
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if !reflect.DeepEqual(output.ExtraArgs, expectedArgs) || len(output.ExtraBinds) > 0 {
+		t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v %#v", expectedArgs, output.ExtraArgs, output.ExtraBinds)
+	}
+
+	webhook := rkeClusterServicesKubeAPIAuditLogWebhookInput(kubeAPIInput[0].(map[string]interface{}))
+	webhook[0].(map[string]interface{})["config_file"] = "/opt/audit/webhook.yaml"
+	if _, err := expandRKEClusterServicesKubeAPI(kubeAPIInput); err == nil {
+		t.Fatalf("Unexpected output from expander.\nExpected error on config_file and kubeconfig")
+	}
+}
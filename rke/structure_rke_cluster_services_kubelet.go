@@ -1,11 +1,33 @@
 package rke
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+
 	rancher "github.com/rancher/rke/types"
 )
 
 // Flatteners
 
+func flattenRKEClusterServicesExtraArgsArray(in map[string][]string) []interface{} {
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]interface{}, len(names))
+	for i, name := range names {
+		out[i] = map[string]interface{}{
+			"name":  name,
+			"value": toArrayInterface(in[name]),
+		}
+	}
+
+	return out
+}
+
 func flattenRKEClusterServicesKubelet(in rancher.KubeletService) []interface{} {
 	obj := make(map[string]interface{})
 
@@ -21,6 +43,10 @@ func flattenRKEClusterServicesKubelet(in rancher.KubeletService) []interface{} {
 		obj["extra_args"] = toMapInterface(in.ExtraArgs)
 	}
 
+	if len(in.ExtraArgsArray) > 0 {
+		obj["extra_args_array"] = flattenRKEClusterServicesExtraArgsArray(in.ExtraArgsArray)
+	}
+
 	if len(in.ExtraBinds) > 0 {
 		obj["extra_binds"] = toArrayInterface(in.ExtraBinds)
 	}
@@ -40,11 +66,47 @@ func flattenRKEClusterServicesKubelet(in rancher.KubeletService) []interface{} {
 		obj["infra_container_image"] = in.InfraContainerImage
 	}
 
+	if len(in.WindowsExtraArgs) > 0 {
+		obj["win_extra_args"] = toMapInterface(in.WindowsExtraArgs)
+	}
+
+	if len(in.WindowsExtraArgsArray) > 0 {
+		obj["win_extra_args_array"] = flattenRKEClusterServicesExtraArgsArray(in.WindowsExtraArgsArray)
+	}
+
+	if len(in.WindowsExtraBinds) > 0 {
+		obj["win_extra_binds"] = toArrayInterface(in.WindowsExtraBinds)
+	}
+
+	if len(in.WindowsExtraEnv) > 0 {
+		obj["win_extra_env"] = toArrayInterface(in.WindowsExtraEnv)
+	}
+
 	return []interface{}{obj}
 }
 
 // Expanders
 
+func expandRKEClusterServicesExtraArgsArray(p []interface{}) map[string][]string {
+	obj := make(map[string][]string, len(p))
+
+	for i := range p {
+		in, ok := p[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, ok := in["name"].(string)
+		if !ok || len(name) == 0 {
+			continue
+		}
+		if v, ok := in["value"].([]interface{}); ok {
+			obj[name] = append(obj[name], toArrayString(v)...)
+		}
+	}
+
+	return obj
+}
+
 func expandRKEClusterServicesKubelet(p []interface{}) rancher.KubeletService {
 	obj := rancher.KubeletService{}
 	if len(p) == 0 || p[0] == nil {
@@ -64,6 +126,10 @@ func expandRKEClusterServicesKubelet(p []interface{}) rancher.KubeletService {
 		obj.ExtraArgs = toMapString(v)
 	}
 
+	if v, ok := in["extra_args_array"].([]interface{}); ok && len(v) > 0 {
+		obj.ExtraArgsArray = expandRKEClusterServicesExtraArgsArray(v)
+	}
+
 	if v, ok := in["extra_binds"].([]interface{}); ok && len(v) > 0 {
 		obj.ExtraBinds = toArrayString(v)
 	}
@@ -88,5 +154,57 @@ func expandRKEClusterServicesKubelet(p []interface{}) rancher.KubeletService {
 		obj.InfraContainerImage = v
 	}
 
+	if v, ok := in["win_extra_args"].(map[string]interface{}); ok && len(v) > 0 {
+		obj.WindowsExtraArgs = toMapString(v)
+	}
+
+	if v, ok := in["win_extra_args_array"].([]interface{}); ok && len(v) > 0 {
+		obj.WindowsExtraArgsArray = expandRKEClusterServicesExtraArgsArray(v)
+	}
+
+	if v, ok := in["win_extra_binds"].([]interface{}); ok && len(v) > 0 {
+		obj.WindowsExtraBinds = toArrayString(v)
+	}
+
+	if v, ok := in["win_extra_env"].([]interface{}); ok && len(v) > 0 {
+		obj.WindowsExtraEnv = toArrayString(v)
+	}
+
 	return obj
 }
+
+// Validators
+
+// validateRKEClusterServicesKubelet validates extra args don't override kubelet typed arguments set on config with a different value.
+// Typed arguments holding a default or a state value aren't checked
+func validateRKEClusterServicesKubelet(in rancher.KubeletService, configured func(field string) bool) error {
+	typedArgs := []struct {
+		arg   string
+		field string
+		value string
+	}{
+		{"cluster-dns", "cluster_dns_server", in.ClusterDNSServer},
+		{"cluster-domain", "cluster_domain", in.ClusterDomain},
+		{"fail-swap-on", "fail_swap_on", strconv.FormatBool(in.FailSwapOn)},
+		{"pod-infra-container-image", "infra_container_image", in.InfraContainerImage},
+	}
+
+	for _, extraArgs := range []map[string]string{in.ExtraArgs, in.WindowsExtraArgs} {
+		for _, typed := range typedArgs {
+			v, ok := extraArgs[typed.arg]
+			if !ok || len(typed.value) == 0 || v == typed.value || !configured(typed.field) {
+				continue
+			}
+			return fmt.Errorf("Kubelet extra arg %s=%s conflicts with %s=%s. Use %s instead", typed.arg, v, typed.field, typed.value, typed.field)
+		}
+		if in.GenerateServingCertificate {
+			for _, arg := range []string{"tls-cert-file", "tls-private-key-file"} {
+				if _, ok := extraArgs[arg]; ok {
+					return fmt.Errorf("Kubelet extra arg %s conflicts with generate_serving_certificate", arg)
+				}
+			}
+		}
+	}
+
+	return nil
+}
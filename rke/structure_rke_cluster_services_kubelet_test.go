@@ -2,6 +2,7 @@ package rke
 
 import (
 	"reflect"
+	"slices"
 	"testing"
 
 	rancher "github.com/rancher/rke/types"
@@ -24,9 +25,20 @@ func init() {
 		"arg_one": "one",
 		"arg_two": "two",
 	}
+	testRKEClusterServicesKubeletConf.ExtraArgsArray = map[string][]string{
+		"array_one": {"one", "two"},
+	}
 	testRKEClusterServicesKubeletConf.ExtraBinds = []string{"bind_one", "bind_two"}
 	testRKEClusterServicesKubeletConf.ExtraEnv = []string{"env_one", "env_two"}
 	testRKEClusterServicesKubeletConf.Image = "image"
+	testRKEClusterServicesKubeletConf.WindowsExtraArgs = map[string]string{
+		"win_arg_one": "one",
+	}
+	testRKEClusterServicesKubeletConf.WindowsExtraArgsArray = map[string][]string{
+		"win_array_one": {"one"},
+	}
+	testRKEClusterServicesKubeletConf.WindowsExtraBinds = []string{"win_bind_one"}
+	testRKEClusterServicesKubeletConf.WindowsExtraEnv = []string{"win_env_one"}
 	testRKEClusterServicesKubeletInterface = []interface{}{
 		map[string]interface{}{
 			"cluster_dns_server": "dns.hostname.test",
@@ -35,12 +47,29 @@ func init() {
 				"arg_one": "one",
 				"arg_two": "two",
 			},
+			"extra_args_array": []interface{}{
+				map[string]interface{}{
+					"name":  "array_one",
+					"value": []interface{}{"one", "two"},
+				},
+			},
 			"extra_binds":                  []interface{}{"bind_one", "bind_two"},
 			"extra_env":                    []interface{}{"env_one", "env_two"},
 			"fail_swap_on":                 true,
 			"generate_serving_certificate": true,
 			"image":                        "image",
 			"infra_container_image":        "infra_image",
+			"win_extra_args": map[string]interface{}{
+				"win_arg_one": "one",
+			},
+			"win_extra_args_array": []interface{}{
+				map[string]interface{}{
+					"name":  "win_array_one",
+					"value": []interface{}{"one"},
+				},
+			},
+			"win_extra_binds": []interface{}{"win_bind_one"},
+			"win_extra_env":   []interface{}{"win_env_one"},
 		},
 	}
 }
@@ -86,3 +115,66 @@ func TestExpandRKEClusterServicesKubelet(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateRKEClusterServicesBind(t *testing.T) {
+
+	cases := []struct {
+		Input         string
+		ExpectedError bool
+	}{
+		{"/var/lib/kubelet:/var/lib/kubelet", false},
+		{"/opt/cni:/opt/cni:ro,z", false},
+		{"volume_name:/data:rshared", false},
+		{"/only/source", true},
+		{"relative/path:/data", true},
+		{"/src:relative", true},
+		{"/src:/dst:readonly", true},
+		{"/src:/dst:ro:z", true},
+	}
+
+	for _, tc := range cases {
+		_, errs := validateRKEClusterServicesBind(tc.Input, "extra_binds")
+		if (len(errs) > 0) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %s\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, errs)
+		}
+	}
+}
+
+func TestValidateRKEClusterServicesKubelet(t *testing.T) {
+	kubelet := rancher.KubeletService{
+		ClusterDNSServer:           "10.43.0.10",
+		ClusterDomain:              "cluster.local",
+		GenerateServingCertificate: true,
+	}
+
+	allConfigured := []string{"cluster_dns_server", "cluster_domain", "fail_swap_on", "infra_container_image"}
+
+	cases := []struct {
+		ExtraArgs     map[string]string
+		WinExtraArgs  map[string]string
+		Configured    []string
+		ExpectedError bool
+	}{
+		{map[string]string{"max-pods": "200"}, nil, allConfigured, false},
+		{map[string]string{"cluster-domain": "cluster.local", "fail-swap-on": "false"}, nil, allConfigured, false},
+		{map[string]string{"cluster-domain": "terraform.test"}, nil, allConfigured, true},
+		{map[string]string{"fail-swap-on": "true"}, nil, allConfigured, true},
+		{nil, map[string]string{"cluster-dns": "10.43.0.11"}, allConfigured, true},
+		{map[string]string{"tls-cert-file": "/etc/kubernetes/ssl/kubelet.pem"}, nil, allConfigured, true},
+		{map[string]string{"cluster-dns": "169.254.20.10", "cluster-domain": "terraform.test", "fail-swap-on": "true"}, nil, nil, false},
+		{map[string]string{"cluster-dns": "169.254.20.10", "fail-swap-on": "true"}, nil, []string{"fail_swap_on"}, true},
+	}
+
+	for _, tc := range cases {
+		kubelet.ExtraArgs = tc.ExtraArgs
+		kubelet.WindowsExtraArgs = tc.WinExtraArgs
+		err := validateRKEClusterServicesKubelet(kubelet, func(field string) bool {
+			return slices.Contains(tc.Configured, field)
+		})
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				kubelet, tc.ExpectedError, err)
+		}
+	}
+}
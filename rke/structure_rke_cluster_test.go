@@ -1,6 +1,41 @@
 package rke
 
-import "testing"
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rancher/rke/cluster"
+	rancher "github.com/rancher/rke/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiserverconfigv1 "k8s.io/apiserver/pkg/apis/apiserver/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestPatchRKEClusterYaml(t *testing.T) {
+	in := &rancher.RancherKubernetesEngineConfig{}
+	in.Services.KubeAPI = rancher.KubeAPIService{
+		AdmissionConfiguration: testRKEClusterServicesKubeAPIAdmissionConfigurationConf,
+		AuditLog:               testRKEClusterServicesKubeAPIAuditLogConf,
+	}
+
+	outYaml, err := patchRKEClusterYaml(in)
+	if err != nil {
+		t.Fatalf("[ERROR] on patchRKEClusterYaml: %#v", err)
+	}
+	out, err := cluster.ParseConfig(outYaml)
+	if err != nil {
+		t.Fatalf("[ERROR] parsing patched yaml: %#v\n%s", err, outYaml)
+	}
+	if !reflect.DeepEqual(out.Services.KubeAPI.AuditLog, in.Services.KubeAPI.AuditLog) {
+		t.Fatalf("Unexpected audit_log round trip.\nExpected: %#v\nGiven:    %#v",
+			in.Services.KubeAPI.AuditLog.Configuration.Policy, out.Services.KubeAPI.AuditLog.Configuration.Policy)
+	}
+	if !reflect.DeepEqual(out.Services.KubeAPI.AdmissionConfiguration, in.Services.KubeAPI.AdmissionConfiguration) {
+		t.Fatalf("Unexpected admission_configuration round trip.\nExpected: %#v\nGiven:    %#v",
+			in.Services.KubeAPI.AdmissionConfiguration, out.Services.KubeAPI.AdmissionConfiguration)
+	}
+}
 
 func Test_k8sVersionRequiresCri(t *testing.T) {
 	type args struct {
@@ -83,3 +118,480 @@ func Test_k8sVersionRequiresCri(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRKEClusterCIDRs(t *testing.T) {
+
+	newConfig := func(serviceRange, controllerServiceRange, clusterCIDR, dnsServer string, addresses ...string) *rancher.RancherKubernetesEngineConfig {
+		obj := &rancher.RancherKubernetesEngineConfig{}
+		obj.Services.KubeAPI.ServiceClusterIPRange = serviceRange
+		obj.Services.KubeController.ServiceClusterIPRange = controllerServiceRange
+		obj.Services.KubeController.ClusterCIDR = clusterCIDR
+		obj.Services.Kubelet.ClusterDNSServer = dnsServer
+		for _, address := range addresses {
+			obj.Nodes = append(obj.Nodes, rancher.RKEConfigNode{Address: address, InternalAddress: address})
+		}
+		return obj
+	}
+
+	newDualStackConfig := func(plugin, clusterCIDR string, extraArgs map[string]string) *rancher.RancherKubernetesEngineConfig {
+		obj := newConfig("10.43.0.0/16,fd98::/108", "10.43.0.0/16,fd98::/108", clusterCIDR, "10.43.0.10")
+		obj.Network.Plugin = plugin
+		obj.Services.KubeController.ExtraArgs = extraArgs
+		return obj
+	}
+
+	cases := []struct {
+		Input         *rancher.RancherKubernetesEngineConfig
+		ExpectedError bool
+	}{
+		{
+			newConfig("", "", "", "", "1.2.3.4", "node.example.com"),
+			false,
+		},
+		{
+			newConfig("10.100.0.0/16", "10.100.0.0/16", "10.200.0.0/16", "10.100.0.10"),
+			false,
+		},
+		{
+			newDualStackConfig("calico", "10.42.0.0/16,fd01::/56", nil),
+			false,
+		},
+		{
+			newDualStackConfig("canal", "10.42.0.0/16,fd01::/56", nil),
+			true,
+		},
+		{
+			newDualStackConfig("calico", "10.42.0.0/16,fd01::/32", nil),
+			true,
+		},
+		{
+			newDualStackConfig("calico", "10.42.0.0/16,fd01::/32", map[string]string{"node-cidr-mask-size-ipv6": "48"}),
+			false,
+		},
+		{
+			newDualStackConfig("calico", "10.42.0.0/16,fd01::/56", map[string]string{"node-cidr-mask-size": "48"}),
+			false,
+		},
+		{
+			newConfig("10.43.0.0/16", "10.43.0.0/16", "10.42.0.0/25", "10.43.0.10"),
+			true,
+		},
+		{
+			newConfig("10.0.0.0/8", "10.0.0.0/8", "172.16.0.0/16", "10.0.0.10"),
+			true,
+		},
+		{
+			newConfig("10.100.0.0/16", "", "", "10.100.0.10"),
+			true,
+		},
+		{
+			newConfig("10.100.0.0/16", "10.100.0.0/16", "", ""),
+			true,
+		},
+		{
+			newConfig("10.42.0.0/24", "10.42.0.0/24", "", "10.42.0.10"),
+			true,
+		},
+		{
+			newConfig("10.43.0.0/16,10.44.0.0/16", "10.43.0.0/16,10.44.0.0/16", "", ""),
+			true,
+		},
+		{
+			newConfig("10.43.0.0/16,fd98::/108", "10.43.0.0/16,fd98::/108", "", ""),
+			true,
+		},
+		{
+			newConfig("", "", "", "", "10.42.0.5"),
+			true,
+		},
+		{
+			newConfig("", "", "10.42.0.0/16", "not-an-ip"),
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterCIDRs(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input.Services.KubeController, tc.ExpectedError, err)
+		}
+	}
+}
+
+func TestRKEClusterAPIServerURL(t *testing.T) {
+
+	cases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			"1.2.3.4",
+			"https://1.2.3.4:6443",
+		},
+		{
+			"node.example.com",
+			"https://node.example.com:6443",
+		},
+		{
+			"fd00::10",
+			"https://[fd00::10]:6443",
+		},
+	}
+
+	for _, tc := range cases {
+		output := rkeClusterAPIServerURL(tc.Input)
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output on input %#v\nExpected: %#v\nGiven:    %#v", tc.Input, tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestSplitRKEClusterCIDRsByFamily(t *testing.T) {
+
+	cases := []struct {
+		Input        string
+		ExpectedIPv4 string
+		ExpectedIPv6 string
+	}{
+		{
+			"10.42.0.0/16",
+			"10.42.0.0/16",
+			"",
+		},
+		{
+			"10.42.0.0/16, fd01::/56",
+			"10.42.0.0/16",
+			"fd01::/56",
+		},
+		{
+			"fd01::/56",
+			"",
+			"fd01::/56",
+		},
+	}
+
+	for _, tc := range cases {
+		ipv4, ipv6 := splitRKEClusterCIDRsByFamily(tc.Input)
+		if ipv4 != tc.ExpectedIPv4 || ipv6 != tc.ExpectedIPv6 {
+			t.Fatalf("Unexpected output on input %#v\nExpected: %#v %#v\nGiven:    %#v %#v", tc.Input, tc.ExpectedIPv4, tc.ExpectedIPv6, ipv4, ipv6)
+		}
+	}
+}
+
+func TestRKEClusterAPIServerEndpointURL(t *testing.T) {
+
+	cases := []struct {
+		Input          string
+		ExpectedOutput string
+	}{
+		{
+			"lb.example.com",
+			"https://lb.example.com:6443",
+		},
+		{
+			"lb.example.com:443",
+			"https://lb.example.com:443",
+		},
+		{
+			"fd00::10",
+			"https://[fd00::10]:6443",
+		},
+		{
+			"[fd00::10]:443",
+			"https://[fd00::10]:443",
+		},
+	}
+
+	for _, tc := range cases {
+		output := rkeClusterAPIServerEndpointURL(tc.Input)
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output on input %#v\nExpected: %#v\nGiven:    %#v", tc.Input, tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestValidateRKEClusterAPIServerEndpoint(t *testing.T) {
+
+	cases := []struct {
+		Input         string
+		ExpectedError bool
+	}{
+		{"lb.example.com", false},
+		{"lb.example.com:443", false},
+		{"10.0.0.10", false},
+		{"fd00::10", false},
+		{"[fd00::10]:443", false},
+		{"https://lb.example.com", true},
+		{"lb.example.com:0", true},
+		{"lb.example.com:https", true},
+		{":443", true},
+	}
+
+	for _, tc := range cases {
+		_, errs := validateRKEClusterAPIServerEndpoint(tc.Input, "api_server_endpoint")
+		if (len(errs) > 0) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v", tc.Input, tc.ExpectedError, errs)
+		}
+	}
+}
+
+func TestRKEClusterKubeConfigWithServer(t *testing.T) {
+	kubeConfig := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: "https://10.0.0.1:6443"
+  name: "local"
+contexts:
+- context:
+    cluster: "local"
+    user: "kube-admin-local"
+  name: "local"
+current-context: "local"
+users:
+- name: "kube-admin-local"
+  user:
+    token: "token"
+`
+	output, err := rkeClusterKubeConfigWithServer(kubeConfig, "https://lb.example.com:6443")
+	if err != nil {
+		t.Fatalf("[ERROR] on kube config: %#v", err)
+	}
+	config, err := clientcmd.Load([]byte(output))
+	if err != nil {
+		t.Fatalf("[ERROR] on kube config: %#v", err)
+	}
+	if config.Clusters["local"].Server != "https://lb.example.com:6443" || config.AuthInfos["kube-admin-local"].Token != "token" || config.CurrentContext != "local" {
+		t.Fatalf("Unexpected output kube config:\n%s", output)
+	}
+	if _, err := rkeClusterKubeConfigWithServer("clusters: [", "https://lb.example.com:6443"); err == nil {
+		t.Fatalf("Expected error on invalid kube config")
+	}
+}
+
+func TestExpandRKEClusterYamlRedacted(t *testing.T) {
+	key := []byte("key")
+	redacted := func(v string) string {
+		return redactedWithKey(v, key)
+	}
+	customConfig := func(secret string) *apiserverconfigv1.EncryptionConfiguration {
+		return &apiserverconfigv1.EncryptionConfiguration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "apiserver.config.k8s.io/v1",
+				Kind:       "EncryptionConfiguration",
+			},
+			Resources: []apiserverconfigv1.ResourceConfiguration{
+				{
+					Resources: []string{"secrets"},
+					Providers: []apiserverconfigv1.ProviderConfiguration{
+						{
+							AESCBC: &apiserverconfigv1.AESConfiguration{
+								Keys: []apiserverconfigv1.Key{{Name: "key1", Secret: secret}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	cases := []struct {
+		Input          *rancher.RancherKubernetesEngineConfig
+		ExpectedOutput *rancher.RancherKubernetesEngineConfig
+	}{
+		{
+			&rancher.RancherKubernetesEngineConfig{
+				Nodes: []rancher.RKEConfigNode{
+					{
+						Address: "1.1.1.1",
+						User:    "ubuntu",
+						SSHKey:  "ssh_key",
+						Role:    []string{"controlplane", "etcd", "worker"},
+						Taints:  []rancher.RKETaint{{Key: "key", Value: "value", Effect: "NoSchedule"}},
+					},
+				},
+				PrivateRegistries: []rancher.PrivateRegistry{
+					{
+						URL:      "registry.example.com",
+						User:     "user",
+						Password: "password",
+					},
+				},
+				Services: rancher.RKEConfigServices{
+					Etcd: rancher.ETCDService{
+						ExternalURLs: []string{"https://etcd.example.com:2379"},
+						Key:          "key",
+						BackupConfig: &rancher.BackupConfig{
+							S3BackupConfig: &rancher.S3BackupConfig{
+								AccessKey: "access_key",
+								SecretKey: "secret_key",
+								Region:    "region",
+							},
+						},
+					},
+				},
+			},
+			&rancher.RancherKubernetesEngineConfig{
+				Nodes: []rancher.RKEConfigNode{
+					{
+						Address: "1.1.1.1",
+						User:    redacted("ubuntu"),
+						SSHKey:  redacted("ssh_key"),
+						Role:    []string{"controlplane", "etcd", "worker"},
+						Taints:  []rancher.RKETaint{{Key: "key", Value: "value", Effect: "NoSchedule"}},
+					},
+				},
+				PrivateRegistries: []rancher.PrivateRegistry{
+					{
+						URL:      "registry.example.com",
+						User:     redacted("user"),
+						Password: redacted("password"),
+					},
+				},
+				Services: rancher.RKEConfigServices{
+					Etcd: rancher.ETCDService{
+						ExternalURLs: []string{"https://etcd.example.com:2379"},
+						Key:          redacted("key"),
+						BackupConfig: &rancher.BackupConfig{
+							S3BackupConfig: &rancher.S3BackupConfig{
+								AccessKey: redacted("access_key"),
+								SecretKey: redacted("secret_key"),
+								Region:    "region",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			&rancher.RancherKubernetesEngineConfig{
+				CloudProvider: rancher.CloudProvider{
+					Name: "vsphere",
+					VsphereCloudProvider: &rancher.VsphereCloudProvider{
+						Global: rancher.GlobalVsphereOpts{
+							User:     "user",
+							Password: "password",
+						},
+						VirtualCenter: map[string]rancher.VirtualCenterConfig{
+							"vc.example.com": {
+								User:        "vc_user",
+								Password:    "vc_password",
+								Datacenters: "dc",
+							},
+						},
+					},
+				},
+			},
+			&rancher.RancherKubernetesEngineConfig{
+				CloudProvider: rancher.CloudProvider{
+					Name: "vsphere",
+					VsphereCloudProvider: &rancher.VsphereCloudProvider{
+						Global: rancher.GlobalVsphereOpts{
+							User:     redacted("user"),
+							Password: redacted("password"),
+						},
+						VirtualCenter: map[string]rancher.VirtualCenterConfig{
+							"vc.example.com": {
+								User:        redacted("vc_user"),
+								Password:    redacted("vc_password"),
+								Datacenters: "dc",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			&rancher.RancherKubernetesEngineConfig{
+				Authentication: rancher.AuthnConfig{
+					Strategy: "x509|webhook",
+					Webhook:  &rancher.AuthWebhookConfig{ConfigFile: "webhook_kubeconfig", CacheTimeout: "5s"},
+				},
+				Services: rancher.RKEConfigServices{
+					KubeAPI: rancher.KubeAPIService{
+						SecretsEncryptionConfig: &rancher.SecretsEncryptionConfig{
+							Enabled:      true,
+							CustomConfig: customConfig("c2VjcmV0"),
+						},
+					},
+				},
+			},
+			&rancher.RancherKubernetesEngineConfig{
+				Authentication: rancher.AuthnConfig{
+					Strategy: "x509|webhook",
+					Webhook:  &rancher.AuthWebhookConfig{ConfigFile: redacted("webhook_kubeconfig"), CacheTimeout: "5s"},
+				},
+				Services: rancher.RKEConfigServices{
+					KubeAPI: rancher.KubeAPIService{
+						SecretsEncryptionConfig: &rancher.SecretsEncryptionConfig{
+							Enabled:      true,
+							CustomConfig: customConfig(redacted("c2VjcmV0")),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		inputYaml, err := patchRKEClusterYaml(tc.Input)
+		if err != nil {
+			t.Fatalf("[ERROR] on patchRKEClusterYaml: %#v", err)
+		}
+		expectedOutput, err := patchRKEClusterYaml(tc.ExpectedOutput)
+		if err != nil {
+			t.Fatalf("[ERROR] on patchRKEClusterYaml: %#v", err)
+		}
+		output, err := expandRKEClusterYamlRedacted(inputYaml, nil, key)
+		if err != nil {
+			t.Fatalf("[ERROR] on expander: %#v", err)
+		}
+		if output != expectedOutput {
+			t.Fatalf("Unexpected output from expander.\nExpected: %s\nGiven:    %s", expectedOutput, output)
+		}
+	}
+
+	if redacted("ubuntu") == redactedWithKey("ubuntu", []byte("other")) {
+		t.Fatalf("Unexpected output from expander, redacted value doesn't depend on key")
+	}
+}
+
+func redactedWithKey(v string, key []byte) string {
+	obj := &rancher.BastionHost{SSHKey: v}
+	redactRKEClusterSensitiveValues(reflect.ValueOf(obj), "", nil, key)
+	return obj.SSHKey
+}
+
+func TestExpandRKEClusterYamlRedactedKey(t *testing.T) {
+	state := `{"currentState":{"certificatesBundle":{"kube-ca":{"keyPEM":"ca_key"}}}}`
+
+	key := expandRKEClusterYamlRedactedKey(state)
+	if !reflect.DeepEqual(key, expandRKEClusterYamlRedactedKey(state)) {
+		t.Fatalf("Unexpected output from expander, key isn't stable for the same RKE state")
+	}
+	if strings.Contains(string(key), "ca_key") {
+		t.Fatalf("Unexpected output from expander, key holds the CA key")
+	}
+	if reflect.DeepEqual(expandRKEClusterYamlRedactedKey(""), expandRKEClusterYamlRedactedKey("")) {
+		t.Fatalf("Unexpected output from expander, key without RKE state isn't random")
+	}
+}
+
+func TestExpandRKEClusterYamlRedactedExternalCloudProvider(t *testing.T) {
+	addons, err := expandRKEClusterCloudProviderExternalAddons(testRKEClusterCloudProviderExternalInterface())
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	inputYaml, err := patchRKEClusterYaml(&rancher.RancherKubernetesEngineConfig{Addons: addons})
+	if err != nil {
+		t.Fatalf("[ERROR] on patchRKEClusterYaml: %#v", err)
+	}
+
+	output, err := expandRKEClusterYamlRedacted(inputYaml, testRKEClusterCloudProviderExternalInterface(), []byte("key"))
+	if err != nil {
+		t.Fatalf("[ERROR] on expander: %#v", err)
+	}
+	if strings.Contains(output, "XXXXXXXX") || !strings.Contains(output, rkeClusterYamlRedactedPrefix) {
+		t.Fatalf("Unexpected output from expander, cloud controller manager secret isn't redacted:\n%s", output)
+	}
+}
@@ -0,0 +1,99 @@
+package rke
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Flatteners
+
+func flattenRKEClusterTolerations(p []v1.Toleration) []interface{} {
+	if len(p) == 0 {
+		return []interface{}{}
+	}
+
+	out := make([]interface{}, len(p))
+	for i, in := range p {
+		obj := make(map[string]interface{})
+
+		if len(in.Key) > 0 {
+			obj["key"] = in.Key
+		}
+
+		if len(in.Effect) > 0 {
+			obj["effect"] = string(in.Effect)
+		}
+
+		if len(in.Operator) > 0 {
+			obj["operator"] = string(in.Operator)
+		}
+
+		if in.TolerationSeconds != nil {
+			obj["seconds"] = int(*in.TolerationSeconds)
+		}
+
+		if len(in.Value) > 0 {
+			obj["value"] = in.Value
+		}
+
+		out[i] = obj
+	}
+
+	return out
+}
+
+// Expanders
+
+func expandRKEClusterTolerations(p []interface{}) []v1.Toleration {
+	if len(p) == 0 || p[0] == nil {
+		return []v1.Toleration{}
+	}
+
+	obj := make([]v1.Toleration, len(p))
+
+	for i := range p {
+		in := p[i].(map[string]interface{})
+
+		if v, ok := in["key"].(string); ok && len(v) > 0 {
+			obj[i].Key = v
+		}
+
+		if v, ok := in["effect"].(string); ok && len(v) > 0 {
+			obj[i].Effect = v1.TaintEffect(v)
+		}
+
+		if v, ok := in["operator"].(string); ok && len(v) > 0 {
+			obj[i].Operator = v1.TolerationOperator(v)
+		}
+
+		if v, ok := in["seconds"].(int); ok && v > 0 {
+			seconds := int64(v)
+			obj[i].TolerationSeconds = &seconds
+		}
+
+		if v, ok := in["value"].(string); ok && len(v) > 0 {
+			obj[i].Value = v
+		}
+	}
+
+	return obj
+}
+
+// Validators
+
+// validateRKEClusterTolerations checks tolerations the k8s api would reject when RKE deploys the addon
+func validateRKEClusterTolerations(in []v1.Toleration) error {
+	for _, toleration := range in {
+		if len(toleration.Key) == 0 && toleration.Operator != v1.TolerationOpExists {
+			return fmt.Errorf("toleration with empty key requires operator %s", v1.TolerationOpExists)
+		}
+		if toleration.Operator == v1.TolerationOpExists && len(toleration.Value) > 0 {
+			return fmt.Errorf("toleration %s with operator %s can't set value %q", toleration.Key, v1.TolerationOpExists, toleration.Value)
+		}
+		if toleration.TolerationSeconds != nil && toleration.Effect != v1.TaintEffectNoExecute {
+			return fmt.Errorf("toleration %s seconds requires effect %s", toleration.Key, v1.TaintEffectNoExecute)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package rke
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Tolerations fixtures are shared by the addon tests, initialized before any init()
+var (
+	testRKEClusterTolerationSeconds = int64(10)
+	testRKEClusterTolerationsConf   = []v1.Toleration{
+		{
+			Key:      "key",
+			Value:    "value",
+			Effect:   v1.TaintEffectNoSchedule,
+			Operator: v1.TolerationOpEqual,
+		},
+		{
+			Key:               "key2",
+			Effect:            v1.TaintEffectNoExecute,
+			Operator:          v1.TolerationOpExists,
+			TolerationSeconds: &testRKEClusterTolerationSeconds,
+		},
+	}
+	testRKEClusterTolerationsInterface = []interface{}{
+		map[string]interface{}{
+			"key":      "key",
+			"value":    "value",
+			"effect":   "NoSchedule",
+			"operator": "Equal",
+		},
+		map[string]interface{}{
+			"key":      "key2",
+			"effect":   "NoExecute",
+			"operator": "Exists",
+			"seconds":  10,
+		},
+	}
+)
+
+func TestFlattenRKEClusterTolerations(t *testing.T) {
+
+	cases := []struct {
+		Input          []v1.Toleration
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterTolerationsConf,
+			testRKEClusterTolerationsInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterTolerations(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterTolerations(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput []v1.Toleration
+	}{
+		{
+			testRKEClusterTolerationsInterface,
+			testRKEClusterTolerationsConf,
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterTolerations(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestValidateRKEClusterTolerations(t *testing.T) {
+	seconds := int64(10)
+
+	cases := []struct {
+		Input         []v1.Toleration
+		ExpectedError bool
+	}{
+		{
+			testRKEClusterTolerationsConf,
+			false,
+		},
+		{
+			[]v1.Toleration{{Operator: v1.TolerationOpExists}},
+			false,
+		},
+		{
+			[]v1.Toleration{{Key: "key", Operator: v1.TolerationOpExists, Value: "value"}},
+			true,
+		},
+		{
+			[]v1.Toleration{{Operator: v1.TolerationOpEqual, Value: "value"}},
+			true,
+		},
+		{
+			[]v1.Toleration{{Key: "key", Operator: v1.TolerationOpEqual, Effect: v1.TaintEffectNoSchedule, TolerationSeconds: &seconds}},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := validateRKEClusterTolerations(tc.Input)
+		if (err != nil) != tc.ExpectedError {
+			t.Fatalf("Unexpected output from validator on input %#v\nExpected error: %t\nGiven:    %v",
+				tc.Input, tc.ExpectedError, err)
+		}
+	}
+}
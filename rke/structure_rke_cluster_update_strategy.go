@@ -0,0 +1,118 @@
+package rke
+
+import (
+	rancher "github.com/rancher/rke/types"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Flatteners
+
+func flattenRKEClusterDaemonSetUpdateStrategy(in *rancher.DaemonSetUpdateStrategy) []interface{} {
+	if in == nil {
+		return []interface{}{}
+	}
+	obj := make(map[string]interface{})
+
+	if in.RollingUpdate != nil {
+		rollingUpdate := make(map[string]interface{})
+		if in.RollingUpdate.MaxUnavailable != nil {
+			rollingUpdate["max_unavailable"] = in.RollingUpdate.MaxUnavailable.String()
+		}
+		obj["rolling_update"] = []interface{}{rollingUpdate}
+	}
+
+	if len(in.Strategy) > 0 {
+		obj["strategy"] = string(in.Strategy)
+	}
+
+	return []interface{}{obj}
+}
+
+func flattenRKEClusterDeploymentUpdateStrategy(in *rancher.DeploymentStrategy) []interface{} {
+	if in == nil {
+		return []interface{}{}
+	}
+	obj := make(map[string]interface{})
+
+	if in.RollingUpdate != nil {
+		rollingUpdate := make(map[string]interface{})
+		if in.RollingUpdate.MaxSurge != nil {
+			rollingUpdate["max_surge"] = in.RollingUpdate.MaxSurge.String()
+		}
+		if in.RollingUpdate.MaxUnavailable != nil {
+			rollingUpdate["max_unavailable"] = in.RollingUpdate.MaxUnavailable.String()
+		}
+		obj["rolling_update"] = []interface{}{rollingUpdate}
+	}
+
+	if len(in.Strategy) > 0 {
+		obj["strategy"] = string(in.Strategy)
+	}
+
+	return []interface{}{obj}
+}
+
+// Expanders
+
+func expandRKEClusterDaemonSetUpdateStrategy(p []interface{}) *rancher.DaemonSetUpdateStrategy {
+	if len(p) == 0 || p[0] == nil {
+		return nil
+	}
+	obj := &rancher.DaemonSetUpdateStrategy{}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["strategy"].(string); ok && len(v) > 0 {
+		obj.Strategy = appsv1.DaemonSetUpdateStrategyType(v)
+	}
+
+	// rolling_update is only allowed on RollingUpdate strategy, being the default one
+	if len(obj.Strategy) > 0 && obj.Strategy != appsv1.RollingUpdateDaemonSetStrategyType {
+		return obj
+	}
+
+	if v, ok := in["rolling_update"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		obj.Strategy = appsv1.RollingUpdateDaemonSetStrategyType
+		rollingUpdate := v[0].(map[string]interface{})
+		obj.RollingUpdate = &appsv1.RollingUpdateDaemonSet{}
+		if v, ok := rollingUpdate["max_unavailable"].(string); ok && len(v) > 0 {
+			maxUnavailable := intstr.Parse(v)
+			obj.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+	}
+
+	return obj
+}
+
+func expandRKEClusterDeploymentUpdateStrategy(p []interface{}) *rancher.DeploymentStrategy {
+	if len(p) == 0 || p[0] == nil {
+		return nil
+	}
+	obj := &rancher.DeploymentStrategy{}
+	in := p[0].(map[string]interface{})
+
+	if v, ok := in["strategy"].(string); ok && len(v) > 0 {
+		obj.Strategy = appsv1.DeploymentStrategyType(v)
+	}
+
+	// rolling_update is only allowed on RollingUpdate strategy, being the default one
+	if len(obj.Strategy) > 0 && obj.Strategy != appsv1.RollingUpdateDeploymentStrategyType {
+		return obj
+	}
+
+	if v, ok := in["rolling_update"].([]interface{}); ok && len(v) > 0 && v[0] != nil {
+		obj.Strategy = appsv1.RollingUpdateDeploymentStrategyType
+		rollingUpdate := v[0].(map[string]interface{})
+		obj.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+		if v, ok := rollingUpdate["max_surge"].(string); ok && len(v) > 0 {
+			maxSurge := intstr.Parse(v)
+			obj.RollingUpdate.MaxSurge = &maxSurge
+		}
+		if v, ok := rollingUpdate["max_unavailable"].(string); ok && len(v) > 0 {
+			maxUnavailable := intstr.Parse(v)
+			obj.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+	}
+
+	return obj
+}
@@ -0,0 +1,164 @@
+package rke
+
+import (
+	"reflect"
+	"testing"
+
+	rancher "github.com/rancher/rke/types"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Update strategy fixtures are shared by the addon tests, initialized before any init()
+var (
+	testRKEClusterUpdateStrategyMaxUnavailable = intstr.FromInt(1)
+	testRKEClusterUpdateStrategyMaxSurge       = intstr.FromString("25%")
+	testRKEClusterDaemonSetUpdateStrategyConf  = &rancher.DaemonSetUpdateStrategy{
+		Strategy: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &testRKEClusterUpdateStrategyMaxUnavailable,
+		},
+	}
+	testRKEClusterDaemonSetUpdateStrategyInterface = []interface{}{
+		map[string]interface{}{
+			"strategy": "RollingUpdate",
+			"rolling_update": []interface{}{
+				map[string]interface{}{
+					"max_unavailable": "1",
+				},
+			},
+		},
+	}
+	testRKEClusterDeploymentUpdateStrategyConf = &rancher.DeploymentStrategy{
+		Strategy: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: &testRKEClusterUpdateStrategyMaxUnavailable,
+			MaxSurge:       &testRKEClusterUpdateStrategyMaxSurge,
+		},
+	}
+	testRKEClusterDeploymentUpdateStrategyInterface = []interface{}{
+		map[string]interface{}{
+			"strategy": "RollingUpdate",
+			"rolling_update": []interface{}{
+				map[string]interface{}{
+					"max_surge":       "25%",
+					"max_unavailable": "1",
+				},
+			},
+		},
+	}
+)
+
+func TestFlattenRKEClusterDaemonSetUpdateStrategy(t *testing.T) {
+
+	cases := []struct {
+		Input          *rancher.DaemonSetUpdateStrategy
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterDaemonSetUpdateStrategyConf,
+			testRKEClusterDaemonSetUpdateStrategyInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterDaemonSetUpdateStrategy(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestFlattenRKEClusterDeploymentUpdateStrategy(t *testing.T) {
+
+	cases := []struct {
+		Input          *rancher.DeploymentStrategy
+		ExpectedOutput []interface{}
+	}{
+		{
+			testRKEClusterDeploymentUpdateStrategyConf,
+			testRKEClusterDeploymentUpdateStrategyInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEClusterDeploymentUpdateStrategy(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterDaemonSetUpdateStrategy(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *rancher.DaemonSetUpdateStrategy
+	}{
+		{
+			testRKEClusterDaemonSetUpdateStrategyInterface,
+			testRKEClusterDaemonSetUpdateStrategyConf,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"strategy":       "OnDelete",
+					"rolling_update": testRKEClusterDaemonSetUpdateStrategyInterface[0].(map[string]interface{})["rolling_update"],
+				},
+			},
+			&rancher.DaemonSetUpdateStrategy{
+				Strategy: appsv1.OnDeleteDaemonSetStrategyType,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterDaemonSetUpdateStrategy(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestExpandRKEClusterDeploymentUpdateStrategy(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *rancher.DeploymentStrategy
+	}{
+		{
+			testRKEClusterDeploymentUpdateStrategyInterface,
+			testRKEClusterDeploymentUpdateStrategyConf,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"rolling_update": testRKEClusterDeploymentUpdateStrategyInterface[0].(map[string]interface{})["rolling_update"],
+				},
+			},
+			testRKEClusterDeploymentUpdateStrategyConf,
+		},
+		{
+			[]interface{}{
+				map[string]interface{}{
+					"strategy":       "Recreate",
+					"rolling_update": testRKEClusterDeploymentUpdateStrategyInterface[0].(map[string]interface{})["rolling_update"],
+				},
+			},
+			&rancher.DeploymentStrategy{
+				Strategy: appsv1.RecreateDeploymentStrategyType,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterDeploymentUpdateStrategy(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package rke
+
+import (
+	"time"
+)
+
+// rkeClusterWaitFor type of readiness gates to wait for after apply
+type rkeClusterWaitFor struct {
+	APIReadyz       bool
+	DaemonSets      []string
+	Deployments     []string
+	NodesReady      bool
+	SystemPodsReady bool
+	Timeout         time.Duration
+}
+
+// Expanders
+
+func expandRKEClusterWaitFor(p []interface{}) *rkeClusterWaitFor {
+	if len(p) == 0 || p[0] == nil {
+		return nil
+	}
+	in := p[0].(map[string]interface{})
+	obj := &rkeClusterWaitFor{
+		Timeout: rkeClusterWaitForTimeoutDefault * time.Second,
+	}
+
+	if v, ok := in["api_readyz"].(bool); ok {
+		obj.APIReadyz = v
+	}
+
+	if v, ok := in["daemonsets"].([]interface{}); ok && len(v) > 0 {
+		obj.DaemonSets = toArrayString(v)
+	}
+
+	if v, ok := in["deployments"].([]interface{}); ok && len(v) > 0 {
+		obj.Deployments = toArrayString(v)
+	}
+
+	if v, ok := in["nodes_ready"].(bool); ok {
+		obj.NodesReady = v
+	}
+
+	if v, ok := in["system_pods_ready"].(bool); ok {
+		obj.SystemPodsReady = v
+	}
+
+	if v, ok := in["timeout"].(int); ok && v > 0 {
+		obj.Timeout = time.Duration(v) * time.Second
+	}
+
+	return obj
+}
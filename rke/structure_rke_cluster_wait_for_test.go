@@ -0,0 +1,58 @@
+package rke
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	testRKEClusterWaitForConf      *rkeClusterWaitFor
+	testRKEClusterWaitForInterface []interface{}
+)
+
+func init() {
+	testRKEClusterWaitForConf = &rkeClusterWaitFor{
+		APIReadyz:       true,
+		DaemonSets:      []string{"kube-system/canal"},
+		Deployments:     []string{"kube-system/coredns", "ingress-nginx/default-http-backend"},
+		NodesReady:      true,
+		SystemPodsReady: false,
+		Timeout:         300 * time.Second,
+	}
+	testRKEClusterWaitForInterface = []interface{}{
+		map[string]interface{}{
+			"api_readyz":        true,
+			"daemonsets":        []interface{}{"kube-system/canal"},
+			"deployments":       []interface{}{"kube-system/coredns", "ingress-nginx/default-http-backend"},
+			"nodes_ready":       true,
+			"system_pods_ready": false,
+			"timeout":           300,
+		},
+	}
+}
+
+func TestExpandRKEClusterWaitFor(t *testing.T) {
+
+	cases := []struct {
+		Input          []interface{}
+		ExpectedOutput *rkeClusterWaitFor
+	}{
+		{
+			testRKEClusterWaitForInterface,
+			testRKEClusterWaitForConf,
+		},
+		{
+			[]interface{}{},
+			nil,
+		},
+	}
+
+	for _, tc := range cases {
+		output := expandRKEClusterWaitFor(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from expander.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
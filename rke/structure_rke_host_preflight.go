@@ -0,0 +1,150 @@
+package rke
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/rke/cluster"
+)
+
+// rkeHostPreflightResult type of preflight checks result for a node
+type rkeHostPreflightResult struct {
+	Address                string
+	BrNetfilterLoaded      bool
+	BridgeNfCallIptables   bool
+	ClockSkew              float64
+	DiskFreeMB             int
+	DockerVersion          string
+	DockerVersionSupported bool
+	Errors                 []string
+	IPForward              bool
+	ListeningPorts         map[string]bool
+	OccupiedPorts          []string
+	PrefixPath             string
+	SwapEnabled            bool
+}
+
+// rkeHostPreflightRequiredPorts returns the ports that RKE needs free on a node with roles. UDP ports and ranges are suffixed by /udp
+func rkeHostPreflightRequiredPorts(roles []string) []string {
+	ports := map[string]bool{
+		cluster.KubeletPort: true,
+		fmt.Sprintf("%d%s", cluster.FlannelVxLanPort, rkeHostPreflightUDPSuffix): true,
+	}
+	for _, role := range roles {
+		var list []string
+		switch role {
+		case "etcd":
+			list = cluster.EtcdPortList
+		case "controlplane":
+			list = cluster.ControlPlanePortList
+		case "worker":
+			list = append([]string{rkeHostPreflightNodePortRange + rkeHostPreflightUDPSuffix}, cluster.WorkerPortList...)
+		}
+		for _, port := range list {
+			ports[port] = true
+		}
+	}
+
+	out := make([]string, 0, len(ports))
+	for port := range ports {
+		out = append(out, port)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// rkeHostPreflightOccupiedPorts returns the required ports, or the ports in required ranges, that are listening
+func rkeHostPreflightOccupiedPorts(required []string, listening map[string]bool) []string {
+	out := []string{}
+	for _, port := range required {
+		from, to, isRange := strings.Cut(strings.TrimSuffix(port, rkeHostPreflightUDPSuffix), "-")
+		if !isRange {
+			if listening[port] {
+				out = append(out, port)
+			}
+			continue
+		}
+		first, _ := strconv.Atoi(from)
+		last, _ := strconv.Atoi(to)
+		suffix := strings.TrimPrefix(port, from+"-"+to)
+		for listen := range listening {
+			v, err := strconv.Atoi(strings.TrimSuffix(listen, suffix))
+			if err == nil && strings.HasSuffix(listen, suffix) && v >= first && v <= last {
+				out = append(out, listen)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// parseRKEHostPreflightProbe parses the key=value lines written by the preflight probe container
+func parseRKEHostPreflightProbe(in string, result *rkeHostPreflightResult) {
+	if result.ListeningPorts == nil {
+		result.ListeningPorts = map[string]bool{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(in))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "br_netfilter":
+			result.BrNetfilterLoaded = value == "1"
+		case "bridge_nf_call_iptables":
+			result.BridgeNfCallIptables = value == "1"
+		case "ip_forward":
+			result.IPForward = value == "1"
+		case "swaps":
+			if v, err := strconv.Atoi(value); err == nil {
+				result.SwapEnabled = v > 0
+			}
+		case "disk_free_kb":
+			if v, err := strconv.Atoi(value); err == nil {
+				result.DiskFreeMB = v / 1024
+			}
+		case "listen":
+			// Ports are hex encoded at /proc/net/tcp
+			if v, err := strconv.ParseUint(value, 16, 16); err == nil {
+				result.ListeningPorts[strconv.FormatUint(v, 10)] = true
+			}
+		case "listen_udp":
+			// Ports are hex encoded at /proc/net/udp
+			if v, err := strconv.ParseUint(value, 16, 16); err == nil {
+				result.ListeningPorts[strconv.FormatUint(v, 10)+rkeHostPreflightUDPSuffix] = true
+			}
+		}
+	}
+}
+
+// Flatteners
+
+func flattenRKEHostPreflightResults(in []*rkeHostPreflightResult) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		obj := make(map[string]interface{})
+
+		obj["address"] = v.Address
+		obj["br_netfilter_loaded"] = v.BrNetfilterLoaded
+		obj["bridge_nf_call_iptables"] = v.BridgeNfCallIptables
+		obj["clock_skew"] = v.ClockSkew
+		obj["disk_free_mb"] = v.DiskFreeMB
+		obj["docker_version"] = v.DockerVersion
+		obj["docker_version_supported"] = v.DockerVersionSupported
+		obj["errors"] = toArrayInterface(v.Errors)
+		obj["ip_forward"] = v.IPForward
+		obj["occupied_ports"] = toArrayInterface(v.OccupiedPorts)
+		obj["passed"] = len(v.Errors) == 0
+		obj["prefix_path"] = v.PrefixPath
+		obj["swap_enabled"] = v.SwapEnabled
+
+		out[i] = obj
+	}
+
+	return out
+}
@@ -0,0 +1,152 @@
+package rke
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	testRKEHostPreflightProbeOutput      string
+	testRKEHostPreflightResultConf       *rkeHostPreflightResult
+	testRKEHostPreflightResultsInterface []interface{}
+)
+
+func init() {
+	testRKEHostPreflightProbeOutput = `br_netfilter=1
+bridge_nf_call_iptables=1
+ip_forward=0
+swaps=1
+disk_free_kb=20971520
+listen=0016
+listen=2AF8
+listen=0CEA
+listen_udp=2118
+listen_udp=0035
+unknown
+`
+	testRKEHostPreflightResultConf = &rkeHostPreflightResult{
+		Address:                "node1.terraform.test",
+		BrNetfilterLoaded:      true,
+		BridgeNfCallIptables:   true,
+		ClockSkew:              0.5,
+		DiskFreeMB:             20480,
+		DockerVersion:          "20.10.24",
+		DockerVersionSupported: true,
+		Errors:                 []string{"Swap is enabled"},
+		ListeningPorts: map[string]bool{
+			"22":       true,
+			"3306":     true,
+			"11000":    true,
+			"8472/udp": true,
+			"53/udp":   true,
+		},
+		OccupiedPorts: []string{},
+		PrefixPath:    "/",
+		SwapEnabled:   true,
+	}
+	testRKEHostPreflightResultsInterface = []interface{}{
+		map[string]interface{}{
+			"address":                  "node1.terraform.test",
+			"br_netfilter_loaded":      true,
+			"bridge_nf_call_iptables":  true,
+			"clock_skew":               0.5,
+			"disk_free_mb":             20480,
+			"docker_version":           "20.10.24",
+			"docker_version_supported": true,
+			"errors":                   []interface{}{"Swap is enabled"},
+			"ip_forward":               false,
+			"occupied_ports":           []interface{}{},
+			"passed":                   false,
+			"prefix_path":              "/",
+			"swap_enabled":             true,
+		},
+	}
+}
+
+func TestRKEHostPreflightRequiredPorts(t *testing.T) {
+
+	cases := []struct {
+		Input          []string
+		ExpectedOutput []string
+	}{
+		{
+			[]string{"controlplane", "etcd", "worker"},
+			[]string{"10250", "2379", "2380", "30000-32767/udp", "6443", "8472/udp"},
+		},
+		{
+			[]string{"etcd"},
+			[]string{"10250", "2379", "2380", "8472/udp"},
+		},
+	}
+
+	for _, tc := range cases {
+		output := rkeHostPreflightRequiredPorts(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from required ports.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestRKEHostPreflightOccupiedPorts(t *testing.T) {
+
+	cases := []struct {
+		Input          map[string]bool
+		ExpectedOutput []string
+	}{
+		{
+			map[string]bool{"22": true, "53/udp": true},
+			[]string{},
+		},
+		{
+			map[string]bool{"6443": true, "8472/udp": true, "30001/udp": true, "30002": true, "32768/udp": true},
+			[]string{"30001/udp", "6443", "8472/udp"},
+		},
+	}
+
+	for _, tc := range cases {
+		output := rkeHostPreflightOccupiedPorts(rkeHostPreflightRequiredPorts([]string{"controlplane", "worker"}), tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from occupied ports.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
+
+func TestParseRKEHostPreflightProbe(t *testing.T) {
+	output := &rkeHostPreflightResult{}
+	parseRKEHostPreflightProbe(testRKEHostPreflightProbeOutput, output)
+
+	expected := &rkeHostPreflightResult{
+		BrNetfilterLoaded:    true,
+		BridgeNfCallIptables: true,
+		DiskFreeMB:           20480,
+		ListeningPorts:       testRKEHostPreflightResultConf.ListeningPorts,
+		SwapEnabled:          true,
+	}
+	if !reflect.DeepEqual(output, expected) {
+		t.Fatalf("Unexpected output from parser.\nExpected: %#v\nGiven:    %#v",
+			expected, output)
+	}
+}
+
+func TestFlattenRKEHostPreflightResults(t *testing.T) {
+
+	cases := []struct {
+		Input          []*rkeHostPreflightResult
+		ExpectedOutput []interface{}
+	}{
+		{
+			[]*rkeHostPreflightResult{testRKEHostPreflightResultConf},
+			testRKEHostPreflightResultsInterface,
+		},
+	}
+
+	for _, tc := range cases {
+		output := flattenRKEHostPreflightResults(tc.Input)
+		if !reflect.DeepEqual(output, tc.ExpectedOutput) {
+			t.Fatalf("Unexpected output from flattener.\nExpected: %#v\nGiven:    %#v",
+				tc.ExpectedOutput, output)
+		}
+	}
+}
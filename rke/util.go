@@ -1,6 +1,7 @@
 package rke
 
 import (
+	"bufio"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -8,15 +9,19 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 
 	"github.com/blang/semver"
 	ghodssyaml "github.com/ghodss/yaml"
 	gover "github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 	"gopkg.in/yaml.v2"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 var parsedRangeAtLeast124 = semver.MustParseRange(">= 1.24.0-rancher0")
@@ -194,6 +199,49 @@ func ghodssyamlToInterface(in string, out interface{}) error {
 	return err
 }
 
+// yamlDocumentsToInterface unmarshals every non empty document of a yaml or json multi-document string
+func yamlDocumentsToInterface(in string) ([]interface{}, error) {
+	out := []interface{}{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(in)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var obj interface{}
+		if err := ghodssyaml.Unmarshal(doc, &obj); err != nil {
+			return nil, err
+		}
+		if obj != nil {
+			out = append(out, obj)
+		}
+	}
+	return out, nil
+}
+
+// suppressYamlDiff is a DiffSuppressFunc for yaml or json string arguments, that ignores
+// formatting, comments and key order changes
+func suppressYamlDiff(k, old, new string, d *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+	if old == "" || new == "" || strings.HasSuffix(k, ".%") || strings.HasSuffix(k, ".#") {
+		return false
+	}
+	oldObj, err := yamlDocumentsToInterface(old)
+	if err != nil {
+		return false
+	}
+	newObj, err := yamlDocumentsToInterface(new)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(oldObj, newObj)
+}
+
 func interfaceToYaml(in interface{}) (string, error) {
 	if in == nil {
 		return "", nil
@@ -0,0 +1,65 @@
+package rke
+
+import (
+	"testing"
+)
+
+func TestSuppressYamlDiff(t *testing.T) {
+
+	cases := []struct {
+		Key            string
+		Old            string
+		New            string
+		ExpectedOutput bool
+	}{
+		{
+			"addons",
+			"apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n",
+			"kind: Namespace\napiVersion: v1\nmetadata: {name: test}",
+			true,
+		},
+		{
+			"addons",
+			"---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: one\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: two\n",
+			"# namespaces\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: one\n---\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: two",
+			true,
+		},
+		{
+			"addons",
+			"---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: one\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: two\n",
+			"---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: two\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: one\n",
+			false,
+		},
+		{
+			"services.0.kube_api.0.audit_log.0.configuration.0.policy",
+			"apiVersion: audit.k8s.io/v1\nkind: Policy\n",
+			"{\"kind\": \"Policy\", \"apiVersion\": \"audit.k8s.io/v1\"}",
+			true,
+		},
+		{
+			"cluster_yaml",
+			"nodes: []\n",
+			"nodes: [\n",
+			false,
+		},
+		{
+			"addons",
+			"",
+			"apiVersion: v1\n",
+			false,
+		},
+		{
+			"services.0.kube_api.0.admission_configuration.0.plugins.%",
+			"1",
+			"2",
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		output := suppressYamlDiff(tc.Key, tc.Old, tc.New, nil)
+		if output != tc.ExpectedOutput {
+			t.Fatalf("Unexpected output from diff suppress func on input %#v -> %#v\nExpected: %t\nGiven:    %t", tc.Old, tc.New, tc.ExpectedOutput, output)
+		}
+	}
+}
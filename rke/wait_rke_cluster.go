@@ -0,0 +1,267 @@
+package rke
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/rancher/rke/cluster"
+	"github.com/rancher/rke/hosts"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/transport"
+)
+
+const (
+	rkeClusterWaitForInterval       = 5 * time.Second
+	rkeClusterWaitForRequestTimeout = 30 * time.Second
+	rkeClusterWaitForSystemNS       = "kube-system"
+)
+
+// waitForRKECluster waits for the wait_for readiness gates using the cluster kube config
+func waitForRKECluster(ctx context.Context, d *schema.ResourceData, config *Config) error {
+	waitFor := expandRKEClusterWaitFor(d.Get("wait_for").([]interface{}))
+	if waitFor == nil {
+		return nil
+	}
+
+	kubeConfig, ok := d.Get("kube_config_yaml").(string)
+	if !ok || len(kubeConfig) == 0 {
+		return fmt.Errorf("Failed waiting for RKE cluster: kube_config_yaml is empty")
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeConfig))
+	if err != nil {
+		return fmt.Errorf("Failed parsing RKE cluster kube config: %v", err)
+	}
+	restConfig.Timeout = rkeClusterWaitForRequestTimeout
+	wrapTransport, err := expandRKEClusterK8sWrapTransport(d, config)
+	if err != nil {
+		return err
+	}
+	if wrapTransport != nil {
+		restConfig.WrapTransport = wrapTransport
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("Failed creating k8s client: %v", err)
+	}
+
+	expectedNodes := map[string]bool{}
+	for _, key := range []string{"control_plane_hosts", "etcd_hosts", "worker_hosts"} {
+		if v, ok := d.Get(key).([]interface{}); ok {
+			for _, node := range v {
+				if address, ok := node.(map[string]interface{})["address"].(string); ok && len(address) > 0 {
+					expectedNodes[address] = true
+				}
+			}
+		}
+	}
+
+	log.Infof("[rke_provider] Waiting up to %s for RKE cluster readiness...", waitFor.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, waitFor.Timeout)
+	defer cancel()
+	for {
+		err := checkRKEClusterReady(ctx, client, waitFor, len(expectedNodes))
+		if err == nil {
+			log.Info("[rke_provider] RKE cluster is ready")
+			return nil
+		}
+		log.Debugf("[rke_provider] RKE cluster not ready: %v", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Timeout waiting for RKE cluster readiness: %v", err)
+		case <-time.After(rkeClusterWaitForInterval):
+		}
+	}
+}
+
+// expandRKEClusterK8sWrapTransport returns the k8s transport wrapper used by RKE for the cluster
+func expandRKEClusterK8sWrapTransport(d *schema.ResourceData, config *Config) (transport.WrapperFunc, error) {
+	rkeConfig, _, _, tempDir, err := getRKEClusterConfig(d, config)
+	defer removeTempDir(tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// As cluster.SetupDialers does, bastion host takes precedence
+	if len(rkeConfig.BastionHost.Address) > 0 {
+		bastionHost := rkeConfig.BastionHost
+		if len(bastionHost.Port) == 0 {
+			bastionHost.Port = cluster.DefaultSSHPort
+		}
+		if len(bastionHost.SSHKeyPath) == 0 {
+			bastionHost.SSHKeyPath = defaultString(rkeConfig.SSHKeyPath, cluster.DefaultClusterSSHKeyPath)
+		}
+		bastionHost.SSHAgentAuth = rkeConfig.SSHAgentAuth
+		return hosts.BastionHostWrapTransport(bastionHost)
+	}
+
+	dialers, err := expandRKEClusterDialers(d, config)
+	if err != nil {
+		return nil, err
+	}
+	return dialers.K8sWrapTransport, nil
+}
+
+// checkRKEClusterReady returns an error describing the first readiness gate not ready
+func checkRKEClusterReady(ctx context.Context, client kubernetes.Interface, waitFor *rkeClusterWaitFor, expectedNodes int) error {
+	if waitFor.APIReadyz {
+		out, err := client.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+		if err != nil {
+			return fmt.Errorf("k8s api /readyz: %v", err)
+		}
+		if strings.TrimSpace(string(out)) != "ok" {
+			return fmt.Errorf("k8s api /readyz: %s", out)
+		}
+	}
+
+	if waitFor.NodesReady {
+		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("listing nodes: %v", err)
+		}
+		if len(nodes.Items) < expectedNodes {
+			return fmt.Errorf("%d of %d nodes registered", len(nodes.Items), expectedNodes)
+		}
+		for i := range nodes.Items {
+			if !isRKENodeReady(&nodes.Items[i]) {
+				return fmt.Errorf("node %s is not Ready", nodes.Items[i].Name)
+			}
+		}
+	}
+
+	if waitFor.SystemPodsReady {
+		if err := checkRKEClusterSystemWorkloadsReady(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range waitFor.Deployments {
+		namespace, name, _ := strings.Cut(v, "/")
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("getting deployment %s: %v", v, err)
+		}
+		if !isRKEDeploymentReady(deployment) {
+			return fmt.Errorf("deployment %s is not rolled out", v)
+		}
+	}
+
+	for _, v := range waitFor.DaemonSets {
+		namespace, name, _ := strings.Cut(v, "/")
+		daemonSet, err := client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("getting daemonset %s: %v", v, err)
+		}
+		if !isRKEDaemonSetReady(daemonSet) {
+			return fmt.Errorf("daemonset %s is not rolled out", v)
+		}
+	}
+
+	return nil
+}
+
+// checkRKEClusterSystemWorkloadsReady checks system namespace workloads instead of every pod, so failed pods left by retried
+// addon jobs or evicted pods replaced by their controller don't block readiness. Pods without controller must be Ready
+func checkRKEClusterSystemWorkloadsReady(ctx context.Context, client kubernetes.Interface) error {
+	deployments, err := client.AppsV1().Deployments(rkeClusterWaitForSystemNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing %s deployments: %v", rkeClusterWaitForSystemNS, err)
+	}
+	for i := range deployments.Items {
+		if !isRKEDeploymentReady(&deployments.Items[i]) {
+			return fmt.Errorf("deployment %s/%s is not rolled out", rkeClusterWaitForSystemNS, deployments.Items[i].Name)
+		}
+	}
+
+	daemonSets, err := client.AppsV1().DaemonSets(rkeClusterWaitForSystemNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing %s daemonsets: %v", rkeClusterWaitForSystemNS, err)
+	}
+	for i := range daemonSets.Items {
+		if !isRKEDaemonSetReady(&daemonSets.Items[i]) {
+			return fmt.Errorf("daemonset %s/%s is not rolled out", rkeClusterWaitForSystemNS, daemonSets.Items[i].Name)
+		}
+	}
+
+	jobs, err := client.BatchV1().Jobs(rkeClusterWaitForSystemNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing %s jobs: %v", rkeClusterWaitForSystemNS, err)
+	}
+	for i := range jobs.Items {
+		if !isRKEJobComplete(&jobs.Items[i]) {
+			return fmt.Errorf("job %s/%s is not complete", rkeClusterWaitForSystemNS, jobs.Items[i].Name)
+		}
+	}
+
+	pods, err := client.CoreV1().Pods(rkeClusterWaitForSystemNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing %s pods: %v", rkeClusterWaitForSystemNS, err)
+	}
+	for i := range pods.Items {
+		if metav1.GetControllerOf(&pods.Items[i]) == nil && !isRKEPodReady(&pods.Items[i]) {
+			return fmt.Errorf("pod %s/%s is not Ready", rkeClusterWaitForSystemNS, pods.Items[i].Name)
+		}
+	}
+
+	return nil
+}
+
+func isRKENodeReady(in *corev1.Node) bool {
+	for _, condition := range in.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isRKEPodReady returns true for Ready pods and completed pods
+func isRKEPodReady(in *corev1.Pod) bool {
+	if in.Status.Phase == corev1.PodSucceeded {
+		return true
+	}
+	if in.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range in.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isRKEDeploymentReady(in *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if in.Spec.Replicas != nil {
+		replicas = *in.Spec.Replicas
+	}
+	return in.Status.ObservedGeneration >= in.Generation &&
+		in.Status.UpdatedReplicas == replicas &&
+		in.Status.AvailableReplicas == replicas
+}
+
+// isRKEJobComplete returns true for complete jobs, like the RKE addon jobs, even if some of their pods failed before
+func isRKEJobComplete(in *batchv1.Job) bool {
+	for _, condition := range in.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func isRKEDaemonSetReady(in *appsv1.DaemonSet) bool {
+	return in.Status.ObservedGeneration >= in.Generation &&
+		in.Status.UpdatedNumberScheduled == in.Status.DesiredNumberScheduled &&
+		in.Status.NumberAvailable == in.Status.DesiredNumberScheduled
+}
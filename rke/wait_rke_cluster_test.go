@@ -0,0 +1,165 @@
+package rke
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckRKEClusterReady(t *testing.T) {
+	replicas := int32(2)
+	readyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	notReadyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node2"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+		},
+	}
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns-1", Namespace: "kube-system"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	completedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rke-coredns-addon-deploy-job-1", Namespace: "kube-system"},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	pendingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-server-1", Namespace: "kube-system"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	controller := true
+	completeJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "rke-network-plugin-deploy-job", Namespace: "kube-system", UID: "job"},
+		Status: batchv1.JobStatus{
+			Failed:     1,
+			Succeeded:  1,
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	runningJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "rke-ingress-controller-deploy-job", Namespace: "kube-system"},
+		Status:     batchv1.JobStatus{Active: 1},
+	}
+	failedJobPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "rke-network-plugin-deploy-job-1",
+			Namespace:       "kube-system",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "rke-network-plugin-deploy-job", UID: "job", Controller: &controller}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+	evictedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "coredns-2",
+			Namespace:       "kube-system",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "coredns-1", UID: "rs", Controller: &controller}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+	}
+	rolledOutDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	notRolledOutDaemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "canal", Namespace: "kube-system", Generation: 2},
+		Status:     appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2},
+	}
+
+	cases := []struct {
+		Name          string
+		Objects       []runtime.Object
+		WaitFor       *rkeClusterWaitFor
+		ExpectedNodes int
+		Ready         bool
+	}{
+		{
+			"ready",
+			[]runtime.Object{readyNode, readyPod, completedPod, rolledOutDeployment},
+			&rkeClusterWaitFor{NodesReady: true, SystemPodsReady: true, Deployments: []string{"kube-system/coredns"}},
+			1,
+			true,
+		},
+		{
+			"failed job pod and evicted pod",
+			[]runtime.Object{readyPod, completeJob, failedJobPod, evictedPod, rolledOutDeployment},
+			&rkeClusterWaitFor{SystemPodsReady: true},
+			0,
+			true,
+		},
+		{
+			"job not complete",
+			[]runtime.Object{readyPod, runningJob},
+			&rkeClusterWaitFor{SystemPodsReady: true},
+			0,
+			false,
+		},
+		{
+			"system daemonset not rolled out",
+			[]runtime.Object{readyPod, notRolledOutDaemonSet},
+			&rkeClusterWaitFor{SystemPodsReady: true},
+			0,
+			false,
+		},
+		{
+			"node not ready",
+			[]runtime.Object{readyNode, notReadyNode},
+			&rkeClusterWaitFor{NodesReady: true},
+			2,
+			false,
+		},
+		{
+			"node not registered",
+			[]runtime.Object{readyNode},
+			&rkeClusterWaitFor{NodesReady: true},
+			2,
+			false,
+		},
+		{
+			"pod not ready",
+			[]runtime.Object{readyPod, pendingPod},
+			&rkeClusterWaitFor{SystemPodsReady: true},
+			0,
+			false,
+		},
+		{
+			"daemonset not rolled out",
+			[]runtime.Object{notRolledOutDaemonSet},
+			&rkeClusterWaitFor{DaemonSets: []string{"kube-system/canal"}},
+			0,
+			false,
+		},
+		{
+			"deployment not found",
+			[]runtime.Object{},
+			&rkeClusterWaitFor{Deployments: []string{"kube-system/coredns"}},
+			0,
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		client := fake.NewSimpleClientset(tc.Objects...)
+		err := checkRKEClusterReady(context.Background(), client, tc.WaitFor, tc.ExpectedNodes)
+		if tc.Ready && err != nil {
+			t.Fatalf("[%s] Unexpected not ready cluster: %v", tc.Name, err)
+		}
+		if !tc.Ready && err == nil {
+			t.Fatalf("[%s] Unexpected ready cluster", tc.Name)
+		}
+	}
+}